@@ -0,0 +1,139 @@
+package config
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// GetDefaultReservationDuration returns the restaurant default reservation duration in minutes
+func GetDefaultReservationDuration() int {
+	return getEnvInt("RESERVATION_DEFAULT_DURATION", 120)
+}
+
+// GetMaxReservationDuration returns the maximum allowed reservation duration in minutes
+func GetMaxReservationDuration() int {
+	return getEnvInt("RESERVATION_MAX_DURATION", 360)
+}
+
+// GetMinTableFillRatio returns the minimum party size / table capacity ratio (0 disables the check)
+func GetMinTableFillRatio() float64 {
+	value, err := strconv.ParseFloat(getEnv("RESERVATION_MIN_FILL_RATIO", ""), 64)
+	if err != nil || value < 0 {
+		return 0
+	}
+	return value
+}
+
+// GetReservationSlotInterval returns the spacing between bookable time slots in minutes
+func GetReservationSlotInterval() int {
+	interval := getEnvInt("RESERVATION_SLOT_INTERVAL", 30)
+	if interval <= 0 {
+		return 30
+	}
+	return interval
+}
+
+// GetDefaultOpeningTime returns the default daily opening time (Format: "15:04")
+func GetDefaultOpeningTime() string {
+	return getEnv("RESTAURANT_OPENING_TIME", "12:00")
+}
+
+// GetDefaultClosingTime returns the default daily closing time (Format: "15:04")
+func GetDefaultClosingTime() string {
+	return getEnv("RESTAURANT_CLOSING_TIME", "23:00")
+}
+
+// GetTableReservedHorizon returns how many minutes before a reservation its table shows as reserved
+func GetTableReservedHorizon() int {
+	return getEnvInt("TABLE_RESERVED_HORIZON", 60)
+}
+
+// GetWaitlistClaimWindow returns how many minutes a waitlisted customer has to claim an offered table
+func GetWaitlistClaimWindow() int {
+	window := getEnvInt("WAITLIST_CLAIM_WINDOW", 15)
+	if window <= 0 {
+		return 15
+	}
+	return window
+}
+
+// GetNoShowGracePeriod returns how many minutes after its start a confirmed reservation is marked as no-show
+func GetNoShowGracePeriod() int {
+	grace := getEnvInt("NO_SHOW_GRACE_PERIOD", 15)
+	if grace < 0 {
+		return 15
+	}
+	return grace
+}
+
+// GetNoShowCheckInterval returns how often (in minutes) overdue reservations are checked for no-shows
+func GetNoShowCheckInterval() int {
+	interval := getEnvInt("NO_SHOW_CHECK_INTERVAL", 5)
+	if interval <= 0 {
+		return 5
+	}
+	return interval
+}
+
+// GetJobInterval returns how often (in minutes) reservation lifecycle jobs run
+func GetJobInterval() int {
+	interval := getEnvInt("JOB_INTERVAL", 1)
+	if interval <= 0 {
+		return 1
+	}
+	return interval
+}
+
+// GetReminderOffsets returns how many minutes before its start reservation reminders are sent, largest first
+// Configured as a comma separated list (e.g. REMINDER_OFFSETS=1440,120 for 24 hours and 2 hours before)
+func GetReminderOffsets() []int {
+	var offsets []int
+	for _, part := range strings.Split(getEnv("REMINDER_OFFSETS", "1440,120"), ",") {
+		offset, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil || offset <= 0 || slices.Contains(offsets, offset) {
+			continue
+		}
+		offsets = append(offsets, offset)
+	}
+	if len(offsets) == 0 {
+		return []int{1440, 120}
+	}
+
+	slices.Sort(offsets)
+	slices.Reverse(offsets)
+	return offsets
+}
+
+// GetPendingConfirmationTimeout returns how many minutes a reservation may stay pending before it is
+// cancelled (0 keeps it until its start time)
+func GetPendingConfirmationTimeout() int {
+	timeout := getEnvInt("PENDING_CONFIRMATION_TIMEOUT", 0)
+	if timeout < 0 {
+		return 0
+	}
+	return timeout
+}
+
+// GetReservationLinkURL returns the page customers open to confirm or cancel a reservation (the token is appended)
+func GetReservationLinkURL() string {
+	return getEnv("RESERVATION_LINK_URL", "http://localhost:3000/reservations/respond")
+}
+
+// GetFloorEventRetention returns how many hours floor events are kept for host clients to catch up on
+func GetFloorEventRetention() int {
+	retention := getEnvInt("FLOOR_EVENT_RETENTION", 24)
+	if retention < 1 {
+		return 1
+	}
+	return retention
+}
+
+// getEnvInt reads integer environment variable or returns default value
+func getEnvInt(key string, defaultValue int) int {
+	value, err := strconv.Atoi(getEnv(key, ""))
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
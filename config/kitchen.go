@@ -0,0 +1,38 @@
+package config
+
+import "strings"
+
+// KitchenStation kitchen display station and the menu categories routed to it
+type KitchenStation struct {
+	Name       string
+	Categories []string
+}
+
+// GetKitchenStations returns the kitchen display stations in configured order
+// Configured as semicolon separated stations with their comma separated menu categories
+// (e.g. KITCHEN_STATIONS=grill:main;pantry:appetizer,dessert;bar:drink); categories without a station
+// go to the default station
+func GetKitchenStations() []KitchenStation {
+	var stations []KitchenStation
+	for _, part := range strings.Split(getEnv("KITCHEN_STATIONS", ""), ";") {
+		name, categories, _ := strings.Cut(part, ":")
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+
+		station := KitchenStation{Name: name}
+		for _, category := range strings.Split(categories, ",") {
+			if category = strings.TrimSpace(category); category != "" {
+				station.Categories = append(station.Categories, category)
+			}
+		}
+		stations = append(stations, station)
+	}
+	return stations
+}
+
+// GetDefaultKitchenStation returns the station that receives items of categories without a station
+func GetDefaultKitchenStation() string {
+	return getEnv("KITCHEN_DEFAULT_STATION", "kitchen")
+}
@@ -0,0 +1,92 @@
+package config
+
+// SMSConfig SMS gateway settings (SMS is disabled when APIURL is empty)
+type SMSConfig struct {
+	APIURL string
+	APIKey string
+	Sender string
+}
+
+// SMTPConfig SMTP email settings (email is disabled when Host is empty)
+type SMTPConfig struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+}
+
+// WebhookConfig outbound webhook settings (the webhook is disabled when URL is empty)
+type WebhookConfig struct {
+	URL    string
+	Secret string // Signs request bodies with HMAC-SHA256 when set
+}
+
+// GetSMSConfig returns the SMS gateway settings
+func GetSMSConfig() SMSConfig {
+	return SMSConfig{
+		APIURL: getEnv("SMS_API_URL", ""),
+		APIKey: getEnv("SMS_API_KEY", ""),
+		Sender: getEnv("SMS_SENDER", ""),
+	}
+}
+
+// GetSMTPConfig returns the SMTP email settings
+func GetSMTPConfig() SMTPConfig {
+	return SMTPConfig{
+		Host:     getEnv("SMTP_HOST", ""),
+		Port:     getEnv("SMTP_PORT", "587"),
+		Username: getEnv("SMTP_USERNAME", ""),
+		Password: getEnv("SMTP_PASSWORD", ""),
+		From:     getEnv("SMTP_FROM", "no-reply@restaurant.com"),
+	}
+}
+
+// GetWebhookConfig returns the outbound webhook settings
+func GetWebhookConfig() WebhookConfig {
+	return WebhookConfig{
+		URL:    getEnv("NOTIFICATION_WEBHOOK_URL", ""),
+		Secret: getEnv("NOTIFICATION_WEBHOOK_SECRET", ""),
+	}
+}
+
+// GetNotificationFilePath returns the file that channels without settings write to instead (empty disables them)
+// Meant for development and tests, where no real messages should go out
+func GetNotificationFilePath() string {
+	return getEnv("NOTIFICATION_FILE_PATH", "")
+}
+
+// GetOutboxMaxAttempts returns how many times an outbox message is attempted before it is dead-lettered
+func GetOutboxMaxAttempts() int {
+	attempts := getEnvInt("OUTBOX_MAX_ATTEMPTS", 8)
+	if attempts <= 0 {
+		return 8
+	}
+	return attempts
+}
+
+// GetOutboxRetryDelay returns the delay (in seconds) before the first retry of a failed outbox message;
+// it doubles with every further attempt
+func GetOutboxRetryDelay() int {
+	delay := getEnvInt("OUTBOX_RETRY_DELAY", 30)
+	if delay <= 0 {
+		return 30
+	}
+	return delay
+}
+
+// GetDefaultLanguage returns the notification language of users who have not chosen one (DEFAULT_LANGUAGE, defaults to fa)
+// Most guests read Persian; set it to en for English
+func GetDefaultLanguage() string {
+	return getEnv("DEFAULT_LANGUAGE", "fa")
+}
+
+// GetPromotionBatchSize returns how many users a promotion is sent to per batch
+// The outbox worker sends one batch per poll, which keeps the SMS gateway from being flooded
+func GetPromotionBatchSize() int {
+	size := getEnvInt("PROMOTION_BATCH_SIZE", 100)
+	if size <= 0 {
+		return 100
+	}
+	return size
+}
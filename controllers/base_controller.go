@@ -1,6 +1,10 @@
 package controllers
 
 import (
+	"errors"
+
+	"restaurant-booking-backend/services"
+
 	"github.com/gofiber/fiber/v2"
 )
 
@@ -32,3 +36,24 @@ func (bc *BaseController) ValidationErrorResponse(c *fiber.Ctx, errors interface
 		"errors":  errors,
 	})
 }
+
+// CurrentUserID returns the ID of the authenticated user (0 when not authenticated)
+func (bc *BaseController) CurrentUserID(c *fiber.Ctx) uint {
+	if userID, ok := c.Locals("user_id").(uint); ok {
+		return userID
+	}
+	return 0
+}
+
+// DomainErrorResponse returns error response for business rule violations (with reason code)
+func (bc *BaseController) DomainErrorResponse(c *fiber.Ctx, err error) error {
+	var domainErr *services.DomainError
+	if errors.As(err, &domainErr) {
+		return c.Status(domainErr.Status).JSON(fiber.Map{
+			"success": false,
+			"message": domainErr.Message,
+			"code":    domainErr.Code,
+		})
+	}
+	return bc.ErrorResponse(c, fiber.StatusInternalServerError, "Database error")
+}
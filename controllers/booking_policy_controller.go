@@ -0,0 +1,91 @@
+package controllers
+
+import (
+	"restaurant-booking-backend/config"
+	"restaurant-booking-backend/models"
+	"restaurant-booking-backend/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// BookingPolicyController booking policy controller
+type BookingPolicyController struct {
+	BaseController
+	policyService services.BookingPolicyService
+}
+
+// BookingPolicyRequest update booking policy request structure (omitted fields keep their value; 0 disables a rule)
+type BookingPolicyRequest struct {
+	MaxActiveReservations *int                       `json:"max_active_reservations"` // Upcoming reservations a customer may hold at once
+	MaxAdvanceDays        *int                       `json:"max_advance_days"`        // How many days ahead customers may book
+	MinLeadTime           *int                       `json:"min_lead_time"`           // Minutes between booking and reservation start
+	NoShowThreshold       *int                       `json:"no_show_threshold"`       // No-shows at which the no-show action applies
+	NoShowAction          *models.NoShowPolicyAction `json:"no_show_action"`          // "block" or "deposit"
+	DepositAmount         *float64                   `json:"deposit_amount"`          // Deposit required by the "deposit" action
+}
+
+// GetBookingPolicy gets the booking policy (admin only)
+func (bpc *BookingPolicyController) GetBookingPolicy(c *fiber.Ctx) error {
+	policy, err := bpc.policyService.GetPolicy(config.DB)
+	if err != nil {
+		return bpc.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to fetch booking policy")
+	}
+
+	return bpc.SuccessResponse(c, policy, "Booking policy retrieved successfully")
+}
+
+// UpdateBookingPolicy updates the booking policy (admin only)
+func (bpc *BookingPolicyController) UpdateBookingPolicy(c *fiber.Ctx) error {
+	var req BookingPolicyRequest
+	if err := c.BodyParser(&req); err != nil {
+		return bpc.ValidationErrorResponse(c, err.Error())
+	}
+
+	policy, err := bpc.policyService.GetPolicy(config.DB)
+	if err != nil {
+		return bpc.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to fetch booking policy")
+	}
+
+	// Update fields if provided
+	for _, value := range []*int{req.MaxActiveReservations, req.MaxAdvanceDays, req.MinLeadTime, req.NoShowThreshold} {
+		if value != nil && *value < 0 {
+			return bpc.ErrorResponse(c, fiber.StatusBadRequest, "Policy limits cannot be negative")
+		}
+	}
+	if req.MaxActiveReservations != nil {
+		policy.MaxActiveReservations = *req.MaxActiveReservations
+	}
+	if req.MaxAdvanceDays != nil {
+		policy.MaxAdvanceDays = *req.MaxAdvanceDays
+	}
+	if req.MinLeadTime != nil {
+		policy.MinLeadTime = *req.MinLeadTime
+	}
+	if req.NoShowThreshold != nil {
+		policy.NoShowThreshold = *req.NoShowThreshold
+	}
+
+	if req.NoShowAction != nil {
+		if *req.NoShowAction != models.NoShowPolicyBlock && *req.NoShowAction != models.NoShowPolicyDeposit {
+			return bpc.ErrorResponse(c, fiber.StatusBadRequest, "Invalid no_show_action. Use block or deposit")
+		}
+		policy.NoShowAction = *req.NoShowAction
+	}
+
+	if req.DepositAmount != nil {
+		if *req.DepositAmount < 0 {
+			return bpc.ErrorResponse(c, fiber.StatusBadRequest, "Deposit amount cannot be negative")
+		}
+		policy.DepositAmount = *req.DepositAmount
+	}
+
+	if policy.NoShowAction == models.NoShowPolicyDeposit && policy.NoShowThreshold > 0 && policy.DepositAmount <= 0 {
+		return bpc.ErrorResponse(c, fiber.StatusBadRequest, "Deposit amount is required for the deposit action")
+	}
+
+	if err := config.DB.Save(policy).Error; err != nil {
+		return bpc.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to update booking policy")
+	}
+
+	return bpc.SuccessResponse(c, policy, "Booking policy updated successfully")
+}
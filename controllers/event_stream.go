@@ -0,0 +1,151 @@
+package controllers
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// eventStreamHeartbeat interval of keep-alive comments on idle event streams
+const eventStreamHeartbeat = 15 * time.Second
+
+// eventStreamReplayPage events loaded per query when a reconnecting client catches up
+const eventStreamReplayPage = 100
+
+// eventStreamRetry delay browsers wait before reconnecting a dropped event stream
+const eventStreamRetry = 3 * time.Second
+
+// eventStream server-sent event stream of records with increasing IDs
+type eventStream[T any] struct {
+	Name   string                                     // Event name
+	LastID uint                                       // ID of the last event the client has
+	Replay bool                                       // Send the events after LastID that Load returns first
+	Load   func(afterID uint, limit int) ([]T, error) // Loads stored events, oldest first
+	Live   <-chan T                                   // New events; closed when the subscription ends
+	ID     func(event T) uint                         // Returns the ID of an event
+	Encode func(event T) interface{}                  // Returns the data sent for an event (nil sends the event as is)
+	Close  func()                                     // Ends the subscription behind Live
+}
+
+// parseLastEventID reads the ID of the last event a reconnecting client received from the Last-Event-ID header
+// (sent by browsers) or the last_event_id query parameter; ok is false on a fresh connection
+func parseLastEventID(c *fiber.Ctx) (id uint, ok bool, err error) {
+	value := c.Get("Last-Event-ID")
+	if value == "" {
+		value = c.Query("last_event_id")
+	}
+	if value == "" {
+		return 0, false, nil
+	}
+
+	parsed, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		return 0, false, err
+	}
+	return uint(parsed), true, nil
+}
+
+// openEventStream responds with an event stream written by stream once the handler returns
+// stream runs after the handler returned, so it must not use c
+func openEventStream(c *fiber.Ctx, stream func(w *bufio.Writer)) error {
+	c.Set("Content-Type", "text/event-stream")
+	c.Set("Cache-Control", "no-cache")
+	c.Set("Connection", "keep-alive")
+	c.Set("X-Accel-Buffering", "no")
+
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		fmt.Fprintf(w, "retry: %d\n\n", eventStreamRetry.Milliseconds())
+		if err := w.Flush(); err != nil {
+			return
+		}
+		stream(w)
+	})
+
+	return nil
+}
+
+// writeEvent writes a server-sent event (an id of 0 leaves it out); returns false once the client is gone
+func writeEvent(w *bufio.Writer, id uint, name string, data interface{}) bool {
+	body, err := json.Marshal(data)
+	if err != nil {
+		log.Printf("Failed to encode %s event: %v", name, err)
+		return true
+	}
+
+	if id != 0 {
+		fmt.Fprintf(w, "id: %d\n", id)
+	}
+	fmt.Fprintf(w, "event: %s\ndata: %s\n\n", name, body)
+	return w.Flush() == nil
+}
+
+// pumpEvents writes the events arriving on live, keeping the connection alive with heartbeats, until live
+// closes or the client disconnects
+func pumpEvents[T any](w *bufio.Writer, live <-chan T, write func(event T) bool) {
+	heartbeat := time.NewTicker(eventStreamHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case event, ok := <-live:
+			if !ok {
+				return // Dropped or shutting down; the client reconnects and catches up
+			}
+			if !write(event) {
+				return
+			}
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": ping\n\n")
+			if err := w.Flush(); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// serve streams the events to the client until the subscription ends or the client disconnects
+func (es *eventStream[T]) serve(c *fiber.Ctx) error {
+	return openEventStream(c, func(w *bufio.Writer) {
+		defer es.Close()
+
+		// Catch up on events stored while the client was disconnected
+		for replay := es.Replay; replay; {
+			missed, err := es.Load(es.LastID, eventStreamReplayPage)
+			if err != nil {
+				log.Printf("Failed to load missed %s events: %v", es.Name, err)
+				return
+			}
+			for _, event := range missed {
+				if !es.send(w, event) {
+					return
+				}
+			}
+			replay = len(missed) == eventStreamReplayPage
+		}
+
+		pumpEvents(w, es.Live, func(event T) bool { return es.send(w, event) })
+	})
+}
+
+// send writes an event unless the client already has it; returns false once the client is gone
+func (es *eventStream[T]) send(w *bufio.Writer, event T) bool {
+	id := es.ID(event)
+	if id <= es.LastID {
+		return true
+	}
+
+	var data interface{} = event
+	if es.Encode != nil {
+		data = es.Encode(event)
+	}
+	if !writeEvent(w, id, es.Name, data) {
+		return false
+	}
+	es.LastID = id
+	return true
+}
@@ -0,0 +1,61 @@
+package controllers
+
+import (
+	"encoding/json"
+
+	"restaurant-booking-backend/models"
+	"restaurant-booking-backend/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// FloorController live floor plan feed for host clients
+type FloorController struct {
+	BaseController
+	floorEventService services.FloorEventService
+}
+
+// StreamFloorEvents streams table and reservation changes as server-sent events (admin only)
+// Every connected host client receives every event; clients reconnecting with Last-Event-ID (or last_event_id)
+// first receive the events they missed
+func (fc *FloorController) StreamFloorEvents(c *fiber.Ctx) error {
+	lastID, replay, err := parseLastEventID(c)
+	if err != nil {
+		return fc.ErrorResponse(c, fiber.StatusBadRequest, "Invalid last event ID")
+	}
+
+	// Subscribe before looking up the latest event so nothing recorded in between is missed
+	stream := services.SharedFloorEventStream()
+	subscription := stream.Subscribe()
+
+	if !replay {
+		if lastID, err = fc.floorEventService.GetLatestEventID(); err != nil {
+			stream.Unsubscribe(subscription)
+			return fc.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to fetch floor events")
+		}
+	}
+
+	events := &eventStream[models.FloorEvent]{
+		Name:   "floor",
+		LastID: lastID,
+		Replay: replay,
+		Load:   fc.floorEventService.GetEventsAfter,
+		Live:   subscription.Events,
+		ID:     func(event models.FloorEvent) uint { return event.ID },
+		Encode: floorEventResponse,
+		Close:  func() { stream.Unsubscribe(subscription) },
+	}
+	return events.serve(c)
+}
+
+// floorEventResponse builds the data of a floor event
+func floorEventResponse(event models.FloorEvent) interface{} {
+	return fiber.Map{
+		"id":             event.ID,
+		"type":           event.Type,
+		"reservation_id": event.ReservationID,
+		"table_ids":      event.TableIDList(),
+		"data":           json.RawMessage(event.Data),
+		"created_at":     event.CreatedAt,
+	}
+}
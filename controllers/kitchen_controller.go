@@ -0,0 +1,131 @@
+package controllers
+
+import (
+	"bufio"
+	"strconv"
+	"time"
+
+	"restaurant-booking-backend/config"
+	"restaurant-booking-backend/models"
+	"restaurant-booking-backend/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// KitchenController kitchen display controller
+type KitchenController struct {
+	BaseController
+	kitchenService services.KitchenService
+}
+
+// RecallTicketRequest recall ticket request structure
+type RecallTicketRequest struct {
+	Reason string `json:"reason"` // Optional reason recorded in the status history
+}
+
+// GetStations gets the kitchen stations and the menu categories routed to each (admin only)
+func (kc *KitchenController) GetStations(c *fiber.Ctx) error {
+	categories := map[string][]string{}
+	for _, station := range config.GetKitchenStations() {
+		categories[station.Name] = append(categories[station.Name], station.Categories...)
+	}
+
+	stations := []fiber.Map{}
+	for _, name := range kc.kitchenService.Stations() {
+		stations = append(stations, fiber.Map{
+			"name":       name,
+			"categories": append([]string{}, categories[name]...),
+			"default":    name == config.GetDefaultKitchenStation(), // Receives items of categories without a station
+		})
+	}
+
+	return kc.SuccessResponse(c, stations, "Kitchen stations retrieved successfully")
+}
+
+// GetTickets gets the tickets of orders being worked on, oldest first (admin only)
+// Filters items by station when given; ready=true lists recently bumped tickets that can still be recalled
+func (kc *KitchenController) GetTickets(c *fiber.Ctx) error {
+	station := c.Query("station")
+	if err := kc.kitchenService.ValidateStation(station); err != nil {
+		return kc.DomainErrorResponse(c, err)
+	}
+
+	tickets, err := kc.kitchenService.GetTickets(station, c.Query("ready") == "true")
+	if err != nil {
+		return kc.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to fetch kitchen tickets")
+	}
+
+	return kc.SuccessResponse(c, tickets, "Kitchen tickets retrieved successfully")
+}
+
+// StreamTickets streams the tickets of a station as server-sent events (admin only)
+// Starts with a snapshot event of every active ticket, then sends a ticket event whenever an order changes;
+// tickets whose status is no longer confirmed or preparing leave the display
+func (kc *KitchenController) StreamTickets(c *fiber.Ctx) error {
+	station := c.Query("station")
+	if err := kc.kitchenService.ValidateStation(station); err != nil {
+		return kc.DomainErrorResponse(c, err)
+	}
+
+	// Subscribe before loading the snapshot so no change in between is missed
+	stream := services.SharedKitchenStream()
+	subscription := stream.Subscribe()
+
+	tickets, err := kc.kitchenService.GetTickets(station, false)
+	if err != nil {
+		stream.Unsubscribe(subscription)
+		return kc.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to fetch kitchen tickets")
+	}
+
+	return openEventStream(c, func(w *bufio.Writer) {
+		defer stream.Unsubscribe(subscription)
+
+		if !writeEvent(w, 0, "snapshot", tickets) {
+			return
+		}
+		pumpEvents(w, subscription.Orders, func(order models.Order) bool {
+			ticket := kc.kitchenService.BuildTicket(&order, station, time.Now())
+			if ticket == nil {
+				return true // Nothing for this station
+			}
+			return writeEvent(w, 0, "ticket", ticket)
+		})
+	})
+}
+
+// BumpTicket moves an order on from confirmed to preparing, or from preparing to ready (admin only)
+func (kc *KitchenController) BumpTicket(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return kc.ErrorResponse(c, fiber.StatusBadRequest, "Invalid order ID")
+	}
+
+	order, err := kc.kitchenService.Bump(uint(id), kc.CurrentUserID(c))
+	if err != nil {
+		return kc.DomainErrorResponse(c, err)
+	}
+
+	return kc.SuccessResponse(c, kc.kitchenService.BuildTicket(order, "", time.Now()), "Ticket bumped successfully")
+}
+
+// RecallTicket moves a bumped (ready) order back to preparing (admin only)
+func (kc *KitchenController) RecallTicket(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return kc.ErrorResponse(c, fiber.StatusBadRequest, "Invalid order ID")
+	}
+
+	var req RecallTicketRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return kc.ValidationErrorResponse(c, err.Error())
+		}
+	}
+
+	order, err := kc.kitchenService.Recall(uint(id), kc.CurrentUserID(c), req.Reason)
+	if err != nil {
+		return kc.DomainErrorResponse(c, err)
+	}
+
+	return kc.SuccessResponse(c, kc.kitchenService.BuildTicket(order, "", time.Now()), "Ticket recalled successfully")
+}
@@ -49,7 +49,7 @@ func (mc *MenuController) GetAllMenuItems(c *fiber.Ctx) error {
 	// Search by name if provided
 	search := c.Query("search")
 	if search != "" {
-		query = query.Where("name ILIKE ?", "%"+search+"%")
+		query = query.Where("LOWER(name) LIKE LOWER(?)", "%"+search+"%")
 	}
 
 	// Filter by availability if provided (default: only available items for customers)
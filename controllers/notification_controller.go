@@ -1,8 +1,12 @@
 package controllers
 
 import (
+	"fmt"
+	"net/mail"
+
 	"restaurant-booking-backend/config"
 	"restaurant-booking-backend/models"
+	"restaurant-booking-backend/services"
 
 	"github.com/gofiber/fiber/v2"
 )
@@ -10,6 +14,13 @@ import (
 // NotificationController notification controller
 type NotificationController struct {
 	BaseController
+	notificationService services.NotificationService
+}
+
+// UpdateNotificationPreferencesRequest notification preferences update request structure
+type UpdateNotificationPreferencesRequest struct {
+	Channels map[models.NotificationType][]models.NotificationChannel `json:"channels"` // Channels per notification type; empty to opt out
+	Email    *string                                                  `json:"email"`    // Address for email notifications; empty to remove
 }
 
 // GetUserNotifications gets all notifications for the current user
@@ -100,3 +111,130 @@ func (nc *NotificationController) DeleteNotification(c *fiber.Ctx) error {
 
 	return nc.SuccessResponse(c, nil, "Notification deleted successfully")
 }
+
+// StreamNotifications streams new notifications of the current user as server-sent events
+// Each event carries the notification ID; clients reconnecting with Last-Event-ID (or last_event_id)
+// first receive the notifications they missed
+func (nc *NotificationController) StreamNotifications(c *fiber.Ctx) error {
+	userID := nc.CurrentUserID(c)
+	if userID == 0 {
+		return nc.ErrorResponse(c, fiber.StatusUnauthorized, "User not authenticated")
+	}
+
+	lastID, replay, err := parseLastEventID(c)
+	if err != nil {
+		return nc.ErrorResponse(c, fiber.StatusBadRequest, "Invalid last event ID")
+	}
+
+	// Subscribe before looking up the latest notification so nothing created in between is missed
+	stream := services.SharedNotificationStream()
+	subscription := stream.Subscribe(userID)
+
+	if !replay {
+		if lastID, err = nc.notificationService.GetLatestNotificationID(userID); err != nil {
+			stream.Unsubscribe(subscription)
+			return nc.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to fetch notifications")
+		}
+	}
+
+	events := &eventStream[models.Notification]{
+		Name:   "notification",
+		LastID: lastID,
+		Replay: replay,
+		Load: func(afterID uint, limit int) ([]models.Notification, error) {
+			return nc.notificationService.GetNotificationsAfter(userID, afterID, limit)
+		},
+		Live:  subscription.Notifications,
+		ID:    func(notification models.Notification) uint { return notification.ID },
+		Close: func() { stream.Unsubscribe(subscription) },
+	}
+	return events.serve(c)
+}
+
+// GetNotificationPreferences gets the channels the current user receives each type of notifications over
+func (nc *NotificationController) GetNotificationPreferences(c *fiber.Ctx) error {
+	preferences, err := nc.notificationPreferences(nc.CurrentUserID(c))
+	if err != nil {
+		return nc.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to fetch notification preferences")
+	}
+
+	return nc.SuccessResponse(c, preferences, "Notification preferences retrieved successfully")
+}
+
+// notificationPreferences builds the notification preferences response of a user
+func (nc *NotificationController) notificationPreferences(userID uint) (fiber.Map, error) {
+	var user models.User
+	if err := config.DB.First(&user, userID).Error; err != nil {
+		return nil, err
+	}
+
+	channels, err := nc.notificationService.GetPreferences(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Channels that are configured on this server
+	available := []models.NotificationChannel{}
+	senders := services.NotificationSenders()
+	for _, channel := range models.NotificationChannels {
+		if _, ok := senders[channel]; ok {
+			available = append(available, channel)
+		}
+	}
+
+	return fiber.Map{
+		"channels":           channels,
+		"available_channels": available,
+		"email":              user.Email,
+	}, nil
+}
+
+// UpdateNotificationPreferences updates the channels the current user receives notifications over
+// An empty channel list opts out of a notification type: promotions are no longer sent at all, other types
+// only reach the in-app inbox. Types left out of the request keep their channels
+func (nc *NotificationController) UpdateNotificationPreferences(c *fiber.Ctx) error {
+	userID := nc.CurrentUserID(c)
+
+	var req UpdateNotificationPreferencesRequest
+	if err := c.BodyParser(&req); err != nil {
+		return nc.ValidationErrorResponse(c, err.Error())
+	}
+
+	for notificationType, channels := range req.Channels {
+		if !notificationType.IsValid() {
+			return nc.ValidationErrorResponse(c, fmt.Sprintf("Invalid notification type: %s", notificationType))
+		}
+		for _, channel := range channels {
+			if !channel.IsValid() {
+				return nc.ValidationErrorResponse(c, fmt.Sprintf("Invalid notification channel: %s", channel))
+			}
+		}
+	}
+
+	if req.Email != nil {
+		// Store only the address part, e.g. "Sara <sara@example.com>" becomes "sara@example.com"
+		email := *req.Email
+		if email != "" {
+			addr, err := mail.ParseAddress(email)
+			if err != nil {
+				return nc.ValidationErrorResponse(c, "Invalid email address")
+			}
+			email = addr.Address
+		}
+
+		if err := config.DB.Model(&models.User{}).Where("id = ?", userID).Update("email", email).Error; err != nil {
+			return nc.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to update email address")
+		}
+	}
+
+	if err := nc.notificationService.UpdatePreferences(userID, req.Channels); err != nil {
+		return nc.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to update notification preferences")
+	}
+
+	preferences, err := nc.notificationPreferences(userID)
+	if err != nil {
+		return nc.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to fetch notification preferences")
+	}
+
+	return nc.SuccessResponse(c, preferences, "Notification preferences updated successfully")
+}
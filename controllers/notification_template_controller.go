@@ -0,0 +1,63 @@
+package controllers
+
+import (
+	"restaurant-booking-backend/config"
+	"restaurant-booking-backend/models"
+	"restaurant-booking-backend/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// NotificationTemplateController lets admins edit the notification messages of each event and language
+type NotificationTemplateController struct {
+	BaseController
+	templateService services.NotificationTemplateService
+}
+
+// NotificationTemplateRequest update notification template request structure
+type NotificationTemplateRequest struct {
+	Body string `json:"body"` // Go text/template source, e.g. "Table #{{number .TableNumber}} on {{date .Date}}"
+}
+
+// GetNotificationTemplates gets the template of every notification event in every language (admin only)
+func (ntc *NotificationTemplateController) GetNotificationTemplates(c *fiber.Ctx) error {
+	templates, err := ntc.templateService.GetTemplates(config.DB)
+	if err != nil {
+		return ntc.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to fetch notification templates")
+	}
+
+	return ntc.SuccessResponse(c, templates, "Notification templates retrieved successfully")
+}
+
+// UpdateNotificationTemplate replaces the template of a notification event in a language (admin only)
+func (ntc *NotificationTemplateController) UpdateNotificationTemplate(c *fiber.Ctx) error {
+	var req NotificationTemplateRequest
+	if err := c.BodyParser(&req); err != nil {
+		return ntc.ValidationErrorResponse(c, err.Error())
+	}
+
+	template, err := ntc.templateService.SetTemplate(
+		config.DB,
+		models.NotificationEvent(c.Params("event")),
+		models.Language(c.Params("language")),
+		req.Body,
+	)
+	if err != nil {
+		return ntc.DomainErrorResponse(c, err)
+	}
+
+	return ntc.SuccessResponse(c, template, "Notification template updated successfully")
+}
+
+// ResetNotificationTemplate restores the built-in template of a notification event in a language (admin only)
+func (ntc *NotificationTemplateController) ResetNotificationTemplate(c *fiber.Ctx) error {
+	if err := ntc.templateService.ResetTemplate(
+		config.DB,
+		models.NotificationEvent(c.Params("event")),
+		models.Language(c.Params("language")),
+	); err != nil {
+		return ntc.DomainErrorResponse(c, err)
+	}
+
+	return ntc.SuccessResponse(c, nil, "Notification template reset successfully")
+}
@@ -0,0 +1,333 @@
+package controllers
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"restaurant-booking-backend/config"
+	"restaurant-booking-backend/models"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// OpeningHoursController opening hours and calendar exceptions controller
+type OpeningHoursController struct {
+	BaseController
+}
+
+// OpeningHoursRequest create/update weekly opening hours request structure
+type OpeningHoursRequest struct {
+	Weekday   *int   `json:"weekday"`    // 0 = Sunday ... 6 = Saturday - required on create
+	Name      string `json:"name"`       // Optional service name (e.g., "lunch", "dinner")
+	OpenTime  string `json:"open_time"`  // Format: "15:04" - required on create
+	CloseTime string `json:"close_time"` // Format: "15:04" - required on create
+}
+
+// CalendarExceptionRequest create/update calendar exception request structure
+type CalendarExceptionRequest struct {
+	Date      string                       `json:"date"`       // Format: "2006-01-02" - required on create
+	Type      models.CalendarExceptionType `json:"type"`       // "closed" or "special_hours" - required on create
+	Reason    *string                      `json:"reason"`     // Optional
+	OpenTime  string                       `json:"open_time"`  // Format: "15:04" (special_hours only)
+	CloseTime string                       `json:"close_time"` // Format: "15:04" (special_hours only)
+}
+
+// validateServiceTimes validates opening and closing times of a service
+func validateServiceTimes(openTime, closeTime string) string {
+	if _, err := time.Parse("15:04", openTime); err != nil {
+		return "Invalid open_time format. Use HH:MM"
+	}
+	if _, err := time.Parse("15:04", closeTime); err != nil {
+		return "Invalid close_time format. Use HH:MM"
+	}
+	if openTime == closeTime {
+		return "open_time and close_time must be different"
+	}
+	return ""
+}
+
+// GetOpeningHours gets weekly opening hours and upcoming calendar exceptions (public)
+func (ohc *OpeningHoursController) GetOpeningHours(c *fiber.Ctx) error {
+	var hours []models.OpeningHours
+	if err := config.DB.Order("weekday ASC, open_time ASC").Find(&hours).Error; err != nil {
+		return ohc.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to fetch opening hours")
+	}
+
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	var exceptions []models.CalendarException
+	if err := config.DB.Where("date >= ?", today).Order("date ASC").Find(&exceptions).Error; err != nil {
+		return ohc.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to fetch calendar exceptions")
+	}
+
+	return ohc.SuccessResponse(c, fiber.Map{
+		"weekly":     hours,
+		"exceptions": exceptions,
+	}, "Opening hours retrieved successfully")
+}
+
+// GetAllOpeningHours gets all weekly opening hours (admin only)
+func (ohc *OpeningHoursController) GetAllOpeningHours(c *fiber.Ctx) error {
+	var hours []models.OpeningHours
+	query := config.DB
+
+	// Filter by weekday if provided
+	weekday := c.Query("weekday")
+	if weekday != "" {
+		query = query.Where("weekday = ?", weekday)
+	}
+
+	if err := query.Order("weekday ASC, open_time ASC").Find(&hours).Error; err != nil {
+		return ohc.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to fetch opening hours")
+	}
+
+	return ohc.SuccessResponse(c, hours, "Opening hours retrieved successfully")
+}
+
+// CreateOpeningHours creates a weekly service period (admin only)
+func (ohc *OpeningHoursController) CreateOpeningHours(c *fiber.Ctx) error {
+	var req OpeningHoursRequest
+	if err := c.BodyParser(&req); err != nil {
+		return ohc.ValidationErrorResponse(c, err.Error())
+	}
+
+	if req.Weekday == nil || *req.Weekday < 0 || *req.Weekday > 6 {
+		return ohc.ValidationErrorResponse(c, "Weekday must be between 0 (Sunday) and 6 (Saturday)")
+	}
+	if message := validateServiceTimes(req.OpenTime, req.CloseTime); message != "" {
+		return ohc.ValidationErrorResponse(c, message)
+	}
+
+	hours := models.OpeningHours{
+		Weekday:   *req.Weekday,
+		Name:      strings.TrimSpace(req.Name),
+		OpenTime:  req.OpenTime,
+		CloseTime: req.CloseTime,
+	}
+
+	if err := config.DB.Create(&hours).Error; err != nil {
+		return ohc.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to create opening hours")
+	}
+
+	return ohc.SuccessResponse(c, hours, "Opening hours created successfully")
+}
+
+// UpdateOpeningHours updates a weekly service period (admin only)
+func (ohc *OpeningHoursController) UpdateOpeningHours(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return ohc.ErrorResponse(c, fiber.StatusBadRequest, "Invalid opening hours ID")
+	}
+
+	var hours models.OpeningHours
+	if err := config.DB.First(&hours, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return ohc.ErrorResponse(c, fiber.StatusNotFound, "Opening hours not found")
+		}
+		return ohc.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to fetch opening hours")
+	}
+
+	var req OpeningHoursRequest
+	if err := c.BodyParser(&req); err != nil {
+		return ohc.ValidationErrorResponse(c, err.Error())
+	}
+
+	// Update fields if provided
+	if req.Weekday != nil {
+		if *req.Weekday < 0 || *req.Weekday > 6 {
+			return ohc.ValidationErrorResponse(c, "Weekday must be between 0 (Sunday) and 6 (Saturday)")
+		}
+		hours.Weekday = *req.Weekday
+	}
+	if req.Name != "" {
+		hours.Name = strings.TrimSpace(req.Name)
+	}
+	if req.OpenTime != "" {
+		hours.OpenTime = req.OpenTime
+	}
+	if req.CloseTime != "" {
+		hours.CloseTime = req.CloseTime
+	}
+	if message := validateServiceTimes(hours.OpenTime, hours.CloseTime); message != "" {
+		return ohc.ValidationErrorResponse(c, message)
+	}
+
+	if err := config.DB.Save(&hours).Error; err != nil {
+		return ohc.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to update opening hours")
+	}
+
+	return ohc.SuccessResponse(c, hours, "Opening hours updated successfully")
+}
+
+// DeleteOpeningHours deletes a weekly service period (admin only)
+func (ohc *OpeningHoursController) DeleteOpeningHours(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return ohc.ErrorResponse(c, fiber.StatusBadRequest, "Invalid opening hours ID")
+	}
+
+	var hours models.OpeningHours
+	if err := config.DB.First(&hours, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return ohc.ErrorResponse(c, fiber.StatusNotFound, "Opening hours not found")
+		}
+		return ohc.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to fetch opening hours")
+	}
+
+	if err := config.DB.Delete(&hours).Error; err != nil {
+		return ohc.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to delete opening hours")
+	}
+
+	return ohc.SuccessResponse(c, nil, "Opening hours deleted successfully")
+}
+
+// GetAllCalendarExceptions gets holiday closures and special hours (admin only)
+func (ohc *OpeningHoursController) GetAllCalendarExceptions(c *fiber.Ctx) error {
+	var exceptions []models.CalendarException
+	query := config.DB
+
+	// Filter by date range if provided
+	from := c.Query("from")
+	if from != "" {
+		query = query.Where("date >= ?", from)
+	}
+	to := c.Query("to")
+	if to != "" {
+		query = query.Where("date <= ?", to)
+	}
+
+	// Filter by type if provided
+	exceptionType := c.Query("type")
+	if exceptionType != "" {
+		query = query.Where("type = ?", exceptionType)
+	}
+
+	if err := query.Order("date ASC").Find(&exceptions).Error; err != nil {
+		return ohc.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to fetch calendar exceptions")
+	}
+
+	return ohc.SuccessResponse(c, exceptions, "Calendar exceptions retrieved successfully")
+}
+
+// CreateCalendarException creates a holiday closure or special hours for a date (admin only)
+func (ohc *OpeningHoursController) CreateCalendarException(c *fiber.Ctx) error {
+	var req CalendarExceptionRequest
+	if err := c.BodyParser(&req); err != nil {
+		return ohc.ValidationErrorResponse(c, err.Error())
+	}
+
+	date, err := time.Parse("2006-01-02", req.Date)
+	if err != nil {
+		return ohc.ErrorResponse(c, fiber.StatusBadRequest, "Invalid date format. Use YYYY-MM-DD")
+	}
+
+	exception := models.CalendarException{
+		Date: date,
+		Type: req.Type,
+	}
+	if req.Reason != nil {
+		exception.Reason = strings.TrimSpace(*req.Reason)
+	}
+
+	switch req.Type {
+	case models.CalendarExceptionClosed:
+	case models.CalendarExceptionSpecialHours:
+		if message := validateServiceTimes(req.OpenTime, req.CloseTime); message != "" {
+			return ohc.ValidationErrorResponse(c, message)
+		}
+		exception.OpenTime = req.OpenTime
+		exception.CloseTime = req.CloseTime
+	default:
+		return ohc.ValidationErrorResponse(c, "Type must be 'closed' or 'special_hours'")
+	}
+
+	if err := config.DB.Create(&exception).Error; err != nil {
+		return ohc.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to create calendar exception")
+	}
+
+	return ohc.SuccessResponse(c, exception, "Calendar exception created successfully")
+}
+
+// UpdateCalendarException updates a calendar exception (admin only)
+func (ohc *OpeningHoursController) UpdateCalendarException(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return ohc.ErrorResponse(c, fiber.StatusBadRequest, "Invalid calendar exception ID")
+	}
+
+	var exception models.CalendarException
+	if err := config.DB.First(&exception, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return ohc.ErrorResponse(c, fiber.StatusNotFound, "Calendar exception not found")
+		}
+		return ohc.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to fetch calendar exception")
+	}
+
+	var req CalendarExceptionRequest
+	if err := c.BodyParser(&req); err != nil {
+		return ohc.ValidationErrorResponse(c, err.Error())
+	}
+
+	// Update fields if provided
+	if req.Date != "" {
+		date, err := time.Parse("2006-01-02", req.Date)
+		if err != nil {
+			return ohc.ErrorResponse(c, fiber.StatusBadRequest, "Invalid date format. Use YYYY-MM-DD")
+		}
+		exception.Date = date
+	}
+	if req.Type != "" {
+		exception.Type = req.Type
+	}
+	if req.Reason != nil {
+		exception.Reason = strings.TrimSpace(*req.Reason)
+	}
+	if req.OpenTime != "" {
+		exception.OpenTime = req.OpenTime
+	}
+	if req.CloseTime != "" {
+		exception.CloseTime = req.CloseTime
+	}
+
+	switch exception.Type {
+	case models.CalendarExceptionClosed:
+		exception.OpenTime = ""
+		exception.CloseTime = ""
+	case models.CalendarExceptionSpecialHours:
+		if message := validateServiceTimes(exception.OpenTime, exception.CloseTime); message != "" {
+			return ohc.ValidationErrorResponse(c, message)
+		}
+	default:
+		return ohc.ValidationErrorResponse(c, "Type must be 'closed' or 'special_hours'")
+	}
+
+	if err := config.DB.Save(&exception).Error; err != nil {
+		return ohc.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to update calendar exception")
+	}
+
+	return ohc.SuccessResponse(c, exception, "Calendar exception updated successfully")
+}
+
+// DeleteCalendarException deletes a calendar exception (admin only)
+func (ohc *OpeningHoursController) DeleteCalendarException(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return ohc.ErrorResponse(c, fiber.StatusBadRequest, "Invalid calendar exception ID")
+	}
+
+	var exception models.CalendarException
+	if err := config.DB.First(&exception, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return ohc.ErrorResponse(c, fiber.StatusNotFound, "Calendar exception not found")
+		}
+		return ohc.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to fetch calendar exception")
+	}
+
+	if err := config.DB.Delete(&exception).Error; err != nil {
+		return ohc.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to delete calendar exception")
+	}
+
+	return ohc.SuccessResponse(c, nil, "Calendar exception deleted successfully")
+}
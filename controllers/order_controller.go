@@ -6,6 +6,7 @@ import (
 
 	"restaurant-booking-backend/config"
 	"restaurant-booking-backend/models"
+	"restaurant-booking-backend/services"
 	"restaurant-booking-backend/utils"
 
 	"github.com/gofiber/fiber/v2"
@@ -15,6 +16,7 @@ import (
 // OrderController order controller
 type OrderController struct {
 	BaseController
+	orderService services.OrderService
 }
 
 // OrderItemRequest order item request structure
@@ -39,6 +41,7 @@ type CreateOrderByAdminRequest struct {
 // UpdateOrderStatusRequest update order status request structure
 type UpdateOrderStatusRequest struct {
 	Status string `json:"status" validate:"required"`
+	Reason string `json:"reason"` // Optional reason recorded in the status history
 }
 
 // CreateOrder creates a new order (customer only)
@@ -117,6 +120,10 @@ func (oc *OrderController) CreateOrder(c *fiber.Ctx) error {
 		tx.Rollback()
 		return oc.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to create order")
 	}
+	if err := oc.orderService.RecordCreated(tx, &order, oc.CurrentUserID(c), ""); err != nil {
+		tx.Rollback()
+		return oc.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to create order")
+	}
 
 	// Create order items
 	for i := range orderItems {
@@ -225,34 +232,49 @@ func (oc *OrderController) UpdateOrderStatus(c *fiber.Ctx) error {
 	}
 
 	// Validate status
-	validStatuses := map[string]bool{
-		string(models.OrderStatusPending):   true,
-		string(models.OrderStatusConfirmed): true,
-		string(models.OrderStatusPreparing): true,
-		string(models.OrderStatusReady):     true,
-		string(models.OrderStatusDelivered): true,
-		string(models.OrderStatusCancelled): true,
+	if !models.OrderStatus(req.Status).IsValid() {
+		return oc.ErrorResponse(c, fiber.StatusBadRequest, "Invalid order status")
 	}
 
-	if !validStatuses[req.Status] {
-		return oc.ErrorResponse(c, fiber.StatusBadRequest, "Invalid order status")
+	userID := c.Locals("user_id")
+	if userID == nil {
+		return oc.ErrorResponse(c, fiber.StatusUnauthorized, "User not authenticated")
 	}
 
-	// Get order
+	// Use database transaction so concurrent updates cannot skip a transition
+	tx := config.DB.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	// Get order with lock
 	var order models.Order
-	if err := config.DB.First(&order, id).Error; err != nil {
+	if err := tx.Set("gorm:query_option", "FOR UPDATE").First(&order, id).Error; err != nil {
+		tx.Rollback()
 		if err == gorm.ErrRecordNotFound {
 			return oc.ErrorResponse(c, fiber.StatusNotFound, "Order not found")
 		}
 		return oc.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to fetch order")
 	}
 
-	// Update status
-	order.Status = models.OrderStatus(req.Status)
-	if err := config.DB.Save(&order).Error; err != nil {
+	// Update status if the state machine allows the transition and record who made it
+	if err := oc.orderService.TransitionStatus(tx, &order, models.OrderStatus(req.Status), userID.(uint), req.Reason); err != nil {
+		tx.Rollback()
+		return oc.DomainErrorResponse(c, err)
+	}
+
+	if err := tx.Save(&order).Error; err != nil {
+		tx.Rollback()
 		return oc.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to update order status")
 	}
 
+	// Commit transaction
+	if err := tx.Commit().Error; err != nil {
+		return oc.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to commit status update")
+	}
+
 	// Load relationships for response
 	config.DB.Preload("User").Preload("OrderItems.MenuItem").First(&order, order.ID)
 
@@ -379,6 +401,10 @@ func (oc *OrderController) CreateOrderByAdmin(c *fiber.Ctx) error {
 		tx.Rollback()
 		return oc.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to create order")
 	}
+	if err := oc.orderService.RecordCreated(tx, &order, oc.CurrentUserID(c), ""); err != nil {
+		tx.Rollback()
+		return oc.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to create order")
+	}
 
 	// Create order items
 	for i := range orderItems {
@@ -400,15 +426,34 @@ func (oc *OrderController) CreateOrderByAdmin(c *fiber.Ctx) error {
 	return oc.SuccessResponse(c, order, "Order created successfully by admin")
 }
 
+// GetOrderHistory gets the status change history of an order (admin only)
+func (oc *OrderController) GetOrderHistory(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return oc.ErrorResponse(c, fiber.StatusBadRequest, "Invalid order ID")
+	}
+
+	var order models.Order
+	if err := config.DB.Unscoped().First(&order, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return oc.ErrorResponse(c, fiber.StatusNotFound, "Order not found")
+		}
+		return oc.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to fetch order")
+	}
+
+	history, err := oc.orderService.GetStatusHistory(config.DB, order.ID)
+	if err != nil {
+		return oc.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to fetch order history")
+	}
+
+	return oc.SuccessResponse(c, history, "Order history retrieved successfully")
+}
+
 // GetOrderStatuses returns all available order statuses
 func (oc *OrderController) GetOrderStatuses(c *fiber.Ctx) error {
-	statuses := []string{
-		string(models.OrderStatusPending),
-		string(models.OrderStatusConfirmed),
-		string(models.OrderStatusPreparing),
-		string(models.OrderStatusReady),
-		string(models.OrderStatusDelivered),
-		string(models.OrderStatusCancelled),
+	statuses := make([]string, 0, len(models.OrderStatuses))
+	for _, status := range models.OrderStatuses {
+		statuses = append(statuses, string(status))
 	}
 
 	return oc.SuccessResponse(c, statuses, "Order statuses retrieved successfully")
@@ -0,0 +1,93 @@
+package controllers
+
+import (
+	"strconv"
+
+	"restaurant-booking-backend/config"
+	"restaurant-booking-backend/models"
+	"restaurant-booking-backend/services"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// OutboxController lets admins inspect and retry notification outbox messages
+type OutboxController struct {
+	BaseController
+	outboxService services.OutboxService
+}
+
+// GetOutboxMessages gets outbox messages, newest first (admin only)
+func (obc *OutboxController) GetOutboxMessages(c *fiber.Ctx) error {
+	query := config.DB
+
+	// Filter by status if provided (e.g. dead for failed deliveries)
+	status := models.OutboxStatus(c.Query("status"))
+	if status != "" {
+		if !status.IsValid() {
+			return obc.ErrorResponse(c, fiber.StatusBadRequest, "Invalid outbox status")
+		}
+		query = query.Where("status = ?", status)
+	}
+
+	// Filter by topic if provided
+	topic := c.Query("topic")
+	if topic != "" {
+		query = query.Where("topic = ?", topic)
+	}
+
+	limit := c.QueryInt("limit", 100)
+	if limit <= 0 || limit > 1000 {
+		return obc.ErrorResponse(c, fiber.StatusBadRequest, "Limit must be between 1 and 1000")
+	}
+
+	var messages []models.OutboxMessage
+	if err := query.Order("id DESC").Limit(limit).Find(&messages).Error; err != nil {
+		return obc.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to fetch outbox messages")
+	}
+
+	return obc.SuccessResponse(c, messages, "Outbox messages retrieved successfully")
+}
+
+// GetOutboxMessageByID gets a single outbox message (admin only)
+func (obc *OutboxController) GetOutboxMessageByID(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return obc.ErrorResponse(c, fiber.StatusBadRequest, "Invalid outbox message ID")
+	}
+
+	var message models.OutboxMessage
+	if err := config.DB.First(&message, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return obc.ErrorResponse(c, fiber.StatusNotFound, "Outbox message not found")
+		}
+		return obc.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to fetch outbox message")
+	}
+
+	return obc.SuccessResponse(c, message, "Outbox message retrieved successfully")
+}
+
+// RetryOutboxMessage schedules a failed or pending outbox message for immediate delivery (admin only)
+func (obc *OutboxController) RetryOutboxMessage(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return obc.ErrorResponse(c, fiber.StatusBadRequest, "Invalid outbox message ID")
+	}
+
+	message, err := obc.outboxService.Retry(uint(id))
+	if err != nil {
+		return obc.DomainErrorResponse(c, err)
+	}
+
+	return obc.SuccessResponse(c, message, "Outbox message scheduled for retry")
+}
+
+// RetryDeadOutboxMessages schedules every dead-lettered outbox message for immediate delivery (admin only)
+func (obc *OutboxController) RetryDeadOutboxMessages(c *fiber.Ctx) error {
+	count, err := obc.outboxService.RetryDead()
+	if err != nil {
+		return obc.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to retry outbox messages")
+	}
+
+	return obc.SuccessResponse(c, fiber.Map{"count": count}, "Dead outbox messages scheduled for retry")
+}
@@ -0,0 +1,103 @@
+package controllers
+
+import (
+	"strconv"
+	"strings"
+
+	"restaurant-booking-backend/config"
+	"restaurant-booking-backend/models"
+	"restaurant-booking-backend/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// PromotionController lets admins broadcast promotions to customers
+type PromotionController struct {
+	BaseController
+	promotionService services.PromotionService
+}
+
+// CreatePromotionRequest create promotion request structure
+type CreatePromotionRequest struct {
+	Message      string                   `json:"message"`
+	Audience     models.PromotionAudience `json:"audience"`      // all, recent_reservations, menu_item or users
+	AudienceDays int                      `json:"audience_days"` // Look-back window of recent_reservations in days; defaults to 90
+	MenuItemID   *uint                    `json:"menu_item_id"`  // Required for menu_item
+	UserIDs      []uint                   `json:"user_ids"`      // Required for users
+}
+
+// GetPromotions gets promotions with their progress, newest first (admin only)
+func (pc *PromotionController) GetPromotions(c *fiber.Ctx) error {
+	query := config.DB
+
+	// Filter by status if provided (e.g. sending for promotions in progress)
+	status := c.Query("status")
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var promotions []models.Promotion
+	if err := query.Order("id DESC").Find(&promotions).Error; err != nil {
+		return pc.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to fetch promotions")
+	}
+
+	return pc.SuccessResponse(c, promotions, "Promotions retrieved successfully")
+}
+
+// GetPromotionByID gets a single promotion with its progress (admin only)
+func (pc *PromotionController) GetPromotionByID(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return pc.ErrorResponse(c, fiber.StatusBadRequest, "Invalid promotion ID")
+	}
+
+	promotion, err := pc.promotionService.GetPromotion(uint(id))
+	if err != nil {
+		return pc.DomainErrorResponse(c, err)
+	}
+
+	return pc.SuccessResponse(c, promotion, "Promotion retrieved successfully")
+}
+
+// CreatePromotion composes a promotion and queues it for delivery to its audience (admin only)
+func (pc *PromotionController) CreatePromotion(c *fiber.Ctx) error {
+	var req CreatePromotionRequest
+	if err := c.BodyParser(&req); err != nil {
+		return pc.ValidationErrorResponse(c, err.Error())
+	}
+
+	req.Message = strings.TrimSpace(req.Message)
+	if req.Message == "" || req.Audience == "" {
+		return pc.ValidationErrorResponse(c, "Message and audience are required")
+	}
+
+	promotion := models.Promotion{
+		Message:      req.Message,
+		Audience:     req.Audience,
+		AudienceDays: req.AudienceDays,
+		MenuItemID:   req.MenuItemID,
+		CreatedByID:  pc.CurrentUserID(c),
+	}
+	promotion.SetUserIDs(req.UserIDs)
+
+	if err := pc.promotionService.CreatePromotion(&promotion); err != nil {
+		return pc.DomainErrorResponse(c, err)
+	}
+
+	return pc.SuccessResponse(c, promotion, "Promotion queued successfully")
+}
+
+// CancelPromotion stops sending a queued or sending promotion (admin only)
+func (pc *PromotionController) CancelPromotion(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return pc.ErrorResponse(c, fiber.StatusBadRequest, "Invalid promotion ID")
+	}
+
+	promotion, err := pc.promotionService.CancelPromotion(uint(id))
+	if err != nil {
+		return pc.DomainErrorResponse(c, err)
+	}
+
+	return pc.SuccessResponse(c, promotion, "Promotion cancelled successfully")
+}
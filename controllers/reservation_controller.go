@@ -3,7 +3,6 @@ package controllers
 import (
 	"strconv"
 	"strings"
-	"sync"
 	"time"
 
 	"restaurant-booking-backend/config"
@@ -19,52 +18,107 @@ import (
 type ReservationController struct {
 	BaseController
 	notificationService *services.NotificationService
-	// tableLocks stores mutexes for each table to prevent concurrent reservations
-	tableLocks sync.Map // map[uint]*sync.Mutex
-	// globalLock for operations that need global synchronization
-	globalLock sync.RWMutex
+	reservationService  *services.ReservationService
+	waitlistService     *services.WaitlistService
+	policyService       *services.BookingPolicyService
+	floorEventService   *services.FloorEventService
+	// tableLocks serializes bookings of the same tables to prevent concurrent reservations
+	tableLocks *services.TableLocks
 }
 
 // NewReservationController creates a new reservation controller
 func NewReservationController() *ReservationController {
 	return &ReservationController{
 		notificationService: &services.NotificationService{},
+		reservationService:  &services.ReservationService{},
+		waitlistService:     &services.WaitlistService{},
+		policyService:       &services.BookingPolicyService{},
+		floorEventService:   &services.FloorEventService{},
+		tableLocks:          services.SharedTableLocks(),
 	}
 }
 
 // CreateReservationRequest create reservation request structure
 type CreateReservationRequest struct {
-	TableID uint   `json:"table_id" binding:"required"`
-	Date    string `json:"date" binding:"required"` // Format: "2006-01-02"
-	Time    string `json:"time" binding:"required"` // Format: "15:04"
+	TableID            uint   `json:"table_id"`                      // Table to book (or table_combination_id; omit both for automatic assignment)
+	TableCombinationID uint   `json:"table_combination_id"`          // Table combination to book for larger parties
+	Date               string `json:"date" binding:"required"`       // Format: "2006-01-02"
+	Time               string `json:"time" binding:"required"`       // Format: "15:04"
+	PartySize          int    `json:"party_size" binding:"required"` // Number of guests
+	Duration           int    `json:"duration"`                      // Optional duration in minutes (defaults to table/restaurant default)
+	Location           string `json:"location"`                      // Optional location preference for automatic table assignment
 }
 
 // CreateReservationByAdminRequest create reservation by admin request structure
 type CreateReservationByAdminRequest struct {
-	Phone    string `json:"phone" binding:"required"` // User phone number
-	Name     string `json:"name" binding:"required"`  // First name (required if user doesn't exist)
-	LastName string `json:"last_name"`                // Last name (optional)
-	TableID  uint   `json:"table_id" binding:"required"`
-	Date     string `json:"date" binding:"required"` // Format: "2006-01-02"
-	Time     string `json:"time" binding:"required"` // Format: "15:04"
+	Phone              string `json:"phone" binding:"required"`      // User phone number
+	Name               string `json:"name" binding:"required"`       // First name (required if user doesn't exist)
+	LastName           string `json:"last_name"`                     // Last name (optional)
+	TableID            uint   `json:"table_id"`                      // Table to book (or table_combination_id; omit both for automatic assignment)
+	TableCombinationID uint   `json:"table_combination_id"`          // Table combination to book for larger parties
+	Date               string `json:"date" binding:"required"`       // Format: "2006-01-02"
+	Time               string `json:"time" binding:"required"`       // Format: "15:04"
+	PartySize          int    `json:"party_size" binding:"required"` // Number of guests
+	Duration           int    `json:"duration"`                      // Optional duration in minutes (defaults to table/restaurant default)
+	Location           string `json:"location"`                      // Optional location preference for automatic table assignment
 }
 
 // UpdateReservationStatusRequest update reservation status request structure
 type UpdateReservationStatusRequest struct {
 	Status models.ReservationStatus `json:"status" binding:"required"`
+	Reason string                   `json:"reason"` // Optional reason recorded in the status history
 }
 
-// getTableLock gets or creates a mutex for a specific table
-func (rc *ReservationController) getTableLock(tableID uint) *sync.Mutex {
-	// Try to get existing mutex
-	if lock, ok := rc.tableLocks.Load(tableID); ok {
-		return lock.(*sync.Mutex)
+// ModifyReservationRequest modify reservation request structure
+// Omitted fields keep their current value; omit both table_id and table_combination_id to keep the current tables
+type ModifyReservationRequest struct {
+	TableID            uint   `json:"table_id"`             // New table (or table_combination_id)
+	TableCombinationID uint   `json:"table_combination_id"` // New table combination for larger parties
+	Date               string `json:"date"`                 // Format: "2006-01-02"
+	Time               string `json:"time"`                 // Format: "15:04"
+	PartySize          int    `json:"party_size"`           // Number of guests
+	Duration           int    `json:"duration"`             // Duration in minutes
+}
+
+// lockBooking locks the tables of a requested table or table combination, or every table
+// when the table is to be assigned automatically, and returns a function that releases them
+func (rc *ReservationController) lockBooking(tableID, combinationID uint) (func(), error) {
+	if tableID == 0 && combinationID == 0 {
+		return rc.tableLocks.LockAll(), nil
+	}
+
+	tableIDs, err := rc.reservationService.TargetTableIDs(config.DB, tableID, combinationID)
+	if err != nil {
+		return nil, err
+	}
+	return rc.tableLocks.Lock(tableIDs), nil
+}
+
+// prepareBooking validates a booking against the requested table or table combination, or assigns the
+// best-fitting free table when neither is given, and returns the target with the resolved duration
+func (rc *ReservationController) prepareBooking(tx *gorm.DB, tableID, combinationID uint, start time.Time, partySize, duration int, location string) (*services.BookingTarget, int, error) {
+	if tableID == 0 && combinationID == 0 {
+		return rc.reservationService.AssignTable(tx, services.AssignmentQuery{
+			Start:     start,
+			PartySize: partySize,
+			Duration:  duration,
+			Location:  location,
+		})
 	}
 
-	// Create new mutex if it doesn't exist
-	newLock := &sync.Mutex{}
-	lock, _ := rc.tableLocks.LoadOrStore(tableID, newLock)
-	return lock.(*sync.Mutex)
+	// Load the table or table combination and lock the rows (SELECT FOR UPDATE)
+	target, err := rc.reservationService.LoadBookingTarget(tx, tableID, combinationID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// Check party size, opening hours and overlapping reservations on every held table
+	duration, err = rc.reservationService.ValidateBooking(tx, target, start, partySize, duration, 0)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return target, duration, nil
 }
 
 // CreateReservation creates a new reservation (customer only)
@@ -93,24 +147,33 @@ func (rc *ReservationController) CreateReservation(c *fiber.Ctx) error {
 	}
 
 	// Combine date and time
-	reservationDateTime := time.Date(
-		reservationDate.Year(),
-		reservationDate.Month(),
-		reservationDate.Day(),
-		reservationTime.Hour(),
-		reservationTime.Minute(),
-		0, 0, time.UTC,
-	)
+	reservationDateTime := utils.CombineDateTime(reservationDate, reservationTime)
 
 	// Check if reservation is in the past
 	if reservationDateTime.Before(time.Now()) {
 		return rc.ErrorResponse(c, fiber.StatusBadRequest, "Cannot make reservation in the past")
 	}
 
-	// Get table-specific lock to prevent concurrent reservations for the same table
-	tableLock := rc.getTableLock(req.TableID)
-	tableLock.Lock()
-	defer tableLock.Unlock()
+	// Validate requested duration
+	if req.Duration < 0 || req.Duration > config.GetMaxReservationDuration() {
+		return rc.ErrorResponse(c, fiber.StatusBadRequest, "Invalid reservation duration")
+	}
+
+	// Check lead time and advance booking window of the booking policy
+	policy, err := rc.policyService.GetPolicy(config.DB)
+	if err != nil {
+		return rc.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to fetch booking policy")
+	}
+	if err := rc.policyService.CheckTiming(policy, reservationDateTime); err != nil {
+		return rc.DomainErrorResponse(c, err)
+	}
+
+	// Get locks of the tables the booking holds to prevent concurrent reservations for the same tables
+	unlock, err := rc.lockBooking(req.TableID, req.TableCombinationID)
+	if err != nil {
+		return rc.DomainErrorResponse(c, err)
+	}
+	defer unlock()
 
 	// Use database transaction to ensure atomicity
 	tx := config.DB.Begin()
@@ -120,58 +183,47 @@ func (rc *ReservationController) CreateReservation(c *fiber.Ctx) error {
 		}
 	}()
 
-	// Check if table exists and lock the row (SELECT FOR UPDATE)
-	var table models.Table
-	if err := tx.Set("gorm:query_option", "FOR UPDATE").First(&table, req.TableID).Error; err != nil {
-		tx.Rollback()
-		if err == gorm.ErrRecordNotFound {
-			return rc.ErrorResponse(c, fiber.StatusNotFound, "Table not found")
-		}
-		return rc.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to fetch table")
-	}
-
-	// Check if table is available
-	if table.Status != models.TableStatusAvailable {
+	// Validate the requested table or combination, or assign the best-fitting free table
+	target, duration, err := rc.prepareBooking(tx, req.TableID, req.TableCombinationID, reservationDateTime, req.PartySize, req.Duration, req.Location)
+	if err != nil {
 		tx.Rollback()
-		return rc.ErrorResponse(c, fiber.StatusBadRequest, "Table is not available")
+		return rc.DomainErrorResponse(c, err)
 	}
 
-	// Check if table is already reserved at this date and time
-	var existingReservation models.Reservation
-	if err := tx.Where("table_id = ? AND date = ? AND time = ? AND status IN ?",
-		req.TableID,
-		reservationDate,
-		reservationTime,
-		[]models.ReservationStatus{
-			models.ReservationStatusPending,
-			models.ReservationStatusConfirmed,
-		}).First(&existingReservation).Error; err == nil {
-		tx.Rollback()
-		return rc.ErrorResponse(c, fiber.StatusConflict, "Table is already reserved at this date and time")
-	} else if err != gorm.ErrRecordNotFound {
+	// Check the customer's upcoming reservations and no-show record against the booking policy
+	deposit, err := rc.policyService.CheckCustomer(tx, policy, userID.(uint))
+	if err != nil {
 		tx.Rollback()
-		return rc.ErrorResponse(c, fiber.StatusInternalServerError, "Database error")
+		return rc.DomainErrorResponse(c, err)
 	}
 
 	// Create reservation
 	reservation := models.Reservation{
-		UserID:  userID.(uint),
-		TableID: req.TableID,
-		Date:    reservationDate,
-		Time:    reservationTime,
-		Status:  models.ReservationStatusPending,
+		UserID:        userID.(uint),
+		Date:          reservationDate,
+		Time:          reservationTime,
+		Duration:      duration,
+		PartySize:     req.PartySize,
+		Status:        models.ReservationStatusPending,
+		DepositAmount: deposit,
 	}
+	target.Apply(&reservation)
 
 	if err := tx.Create(&reservation).Error; err != nil {
 		tx.Rollback()
 		return rc.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to create reservation")
 	}
-
-	// Update table status to reserved
-	table.Status = models.TableStatusReserved
-	if err := tx.Save(&table).Error; err != nil {
+	if err := rc.reservationService.RecordCreated(tx, &reservation, rc.CurrentUserID(c), ""); err != nil {
+		tx.Rollback()
+		return rc.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to create reservation")
+	}
+	if err := rc.notificationService.QueueReservationCreated(tx, &reservation); err != nil {
 		tx.Rollback()
-		return rc.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to update table status")
+		return rc.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to create reservation")
+	}
+	if err := rc.floorEventService.RecordReservation(tx, models.FloorEventReservationCreated, &reservation); err != nil {
+		tx.Rollback()
+		return rc.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to create reservation")
 	}
 
 	// Commit transaction
@@ -180,12 +232,7 @@ func (rc *ReservationController) CreateReservation(c *fiber.Ctx) error {
 	}
 
 	// Load relationships for response (outside transaction)
-	config.DB.Preload("User").Preload("Table").First(&reservation, reservation.ID)
-
-	// Send notification asynchronously
-	if rc.notificationService != nil {
-		go rc.notificationService.SendReservationCreatedNotification(&reservation)
-	}
+	config.DB.Preload("User").Preload("Table").Preload("Tables").First(&reservation, reservation.ID)
 
 	return rc.SuccessResponse(c, reservation, "Reservation created successfully")
 }
@@ -198,7 +245,7 @@ func (rc *ReservationController) GetUserReservations(c *fiber.Ctx) error {
 	}
 
 	var reservations []models.Reservation
-	query := config.DB.Where("user_id = ?", userID.(uint)).Preload("Table")
+	query := config.DB.Where("user_id = ?", userID.(uint)).Preload("Table").Preload("Tables")
 
 	// Filter by status if provided
 	status := c.Query("status")
@@ -230,7 +277,7 @@ func (rc *ReservationController) GetReservationByID(c *fiber.Ctx) error {
 	userRole := c.Locals("user_role")
 
 	var reservation models.Reservation
-	query := config.DB.Preload("User").Preload("Table")
+	query := config.DB.Preload("User").Preload("Table").Preload("Tables")
 
 	// If user is customer, only show their own reservations
 	if userID != nil && userRole == "customer" {
@@ -261,7 +308,7 @@ func (rc *ReservationController) CancelReservation(c *fiber.Ctx) error {
 	userRole := c.Locals("user_role")
 
 	var reservation models.Reservation
-	query := config.DB
+	query := config.DB.Preload("Tables")
 
 	// If user is customer, only allow canceling their own reservations
 	if userID != nil && userRole == "customer" {
@@ -278,18 +325,13 @@ func (rc *ReservationController) CancelReservation(c *fiber.Ctx) error {
 	}
 
 	// Check if reservation can be cancelled
-	if reservation.Status == models.ReservationStatusCancelled {
-		return rc.ErrorResponse(c, fiber.StatusBadRequest, "Reservation is already cancelled")
-	}
-
-	if reservation.Status == models.ReservationStatusCompleted {
-		return rc.ErrorResponse(c, fiber.StatusBadRequest, "Cannot cancel completed reservation")
+	if err := rc.reservationService.ValidateTransition(&reservation, models.ReservationStatusCancelled); err != nil {
+		return rc.DomainErrorResponse(c, err)
 	}
 
-	// Get table-specific lock to prevent concurrent modifications
-	tableLock := rc.getTableLock(reservation.TableID)
-	tableLock.Lock()
-	defer tableLock.Unlock()
+	// Get locks of every held table to prevent concurrent modifications
+	unlockTables := rc.tableLocks.Lock(reservation.HeldTableIDs())
+	defer unlockTables()
 
 	// Use database transaction to ensure atomicity
 	tx := config.DB.Begin()
@@ -308,36 +350,23 @@ func (rc *ReservationController) CancelReservation(c *fiber.Ctx) error {
 		return rc.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to fetch reservation")
 	}
 
-	// Double-check status within transaction
-	if reservation.Status == models.ReservationStatusCancelled {
+	// Double-check status within transaction and update it
+	if err := rc.reservationService.TransitionStatus(tx, &reservation, models.ReservationStatusCancelled, rc.CurrentUserID(c), c.Query("reason")); err != nil {
 		tx.Rollback()
-		return rc.ErrorResponse(c, fiber.StatusBadRequest, "Reservation is already cancelled")
+		return rc.DomainErrorResponse(c, err)
 	}
 
-	// Update reservation status
-	reservation.Status = models.ReservationStatusCancelled
 	if err := tx.Save(&reservation).Error; err != nil {
 		tx.Rollback()
 		return rc.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to cancel reservation")
 	}
-
-	// Update table status if no other active reservations
-	var activeReservations int64
-	tx.Model(&models.Reservation{}).
-		Where("table_id = ? AND status IN ?", reservation.TableID, []models.ReservationStatus{
-			models.ReservationStatusPending,
-			models.ReservationStatusConfirmed,
-		}).Count(&activeReservations)
-
-	if activeReservations == 0 {
-		var table models.Table
-		if err := tx.Set("gorm:query_option", "FOR UPDATE").First(&table, reservation.TableID).Error; err == nil {
-			table.Status = models.TableStatusAvailable
-			if err := tx.Save(&table).Error; err != nil {
-				tx.Rollback()
-				return rc.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to update table status")
-			}
-		}
+	if err := rc.notificationService.QueueReservationCancelled(tx, &reservation); err != nil {
+		tx.Rollback()
+		return rc.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to cancel reservation")
+	}
+	if err := rc.floorEventService.RecordReservation(tx, models.FloorEventReservationCancelled, &reservation); err != nil {
+		tx.Rollback()
+		return rc.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to cancel reservation")
 	}
 
 	// Commit transaction
@@ -345,21 +374,238 @@ func (rc *ReservationController) CancelReservation(c *fiber.Ctx) error {
 		return rc.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to commit cancellation")
 	}
 
+	// Offer the released tables to the first eligible waitlisted customer (table locks are still held)
+	rc.waitlistService.OfferFreedSlot(&reservation)
+
 	// Load relationships for response (outside transaction)
-	config.DB.Preload("User").Preload("Table").First(&reservation, reservation.ID)
+	config.DB.Preload("User").Preload("Table").Preload("Tables").First(&reservation, reservation.ID)
+
+	return rc.SuccessResponse(c, reservation, "Reservation cancelled successfully")
+}
 
-	// Send notification asynchronously
-	if rc.notificationService != nil {
-		go rc.notificationService.SendReservationCancelledNotification(&reservation)
+// ModifyReservation changes the date, time, tables or party size of a reservation (customer can modify their own, admin can modify any)
+// The old and new tables are locked together and the reservation is updated in a single transaction,
+// so the original slot is only released once the new one is secured
+func (rc *ReservationController) ModifyReservation(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return rc.ErrorResponse(c, fiber.StatusBadRequest, "Invalid reservation ID")
 	}
 
-	return rc.SuccessResponse(c, reservation, "Reservation cancelled successfully")
+	userID := c.Locals("user_id")
+	userRole := c.Locals("user_role")
+
+	var req ModifyReservationRequest
+	if err := c.BodyParser(&req); err != nil {
+		return rc.ValidationErrorResponse(c, err.Error())
+	}
+
+	var reservation models.Reservation
+	query := config.DB.Preload("Tables")
+
+	// If user is customer, only allow modifying their own reservations
+	if userID != nil && userRole == "customer" {
+		query = query.Where("id = ? AND user_id = ?", id, userID.(uint))
+	} else {
+		query = query.Where("id = ?", id)
+	}
+
+	if err := query.First(&reservation).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return rc.ErrorResponse(c, fiber.StatusNotFound, "Reservation not found")
+		}
+		return rc.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to fetch reservation")
+	}
+
+	// Check if reservation can be modified
+	if err := rc.reservationService.EnsureModifiable(&reservation); err != nil {
+		return rc.DomainErrorResponse(c, err)
+	}
+
+	// Parse date and time, keeping the current values when omitted
+	reservationDate := reservation.Date
+	if req.Date != "" {
+		reservationDate, err = time.Parse("2006-01-02", req.Date)
+		if err != nil {
+			return rc.ErrorResponse(c, fiber.StatusBadRequest, "Invalid date format. Use YYYY-MM-DD")
+		}
+	}
+
+	reservationTime := reservation.Time
+	if req.Time != "" {
+		reservationTime, err = time.Parse("15:04", req.Time)
+		if err != nil {
+			return rc.ErrorResponse(c, fiber.StatusBadRequest, "Invalid time format. Use HH:MM")
+		}
+	}
+
+	// Combine date and time
+	reservationDateTime := utils.CombineDateTime(reservationDate, reservationTime)
+
+	// Check if reservation is moved to the past
+	if reservationDateTime.Before(time.Now()) {
+		return rc.ErrorResponse(c, fiber.StatusBadRequest, "Cannot move reservation to the past")
+	}
+
+	// Customers moving a reservation are bound by the lead time and advance window of the booking policy
+	if userRole == "customer" && (req.Date != "" || req.Time != "") {
+		policy, err := rc.policyService.GetPolicy(config.DB)
+		if err != nil {
+			return rc.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to fetch booking policy")
+		}
+		if err := rc.policyService.CheckTiming(policy, reservationDateTime); err != nil {
+			return rc.DomainErrorResponse(c, err)
+		}
+	}
+
+	partySize := reservation.PartySize
+	if req.PartySize != 0 {
+		partySize = req.PartySize
+	}
+
+	// Validate requested duration (keeps the current duration when omitted)
+	if req.Duration < 0 || req.Duration > config.GetMaxReservationDuration() {
+		return rc.ErrorResponse(c, fiber.StatusBadRequest, "Invalid reservation duration")
+	}
+	duration := reservation.Duration
+	if req.Duration != 0 {
+		duration = req.Duration
+	}
+
+	// Keep the current table or combination unless a new one is requested
+	tableID, combinationID := req.TableID, req.TableCombinationID
+	if tableID == 0 && combinationID == 0 {
+		if reservation.TableCombinationID != nil {
+			combinationID = *reservation.TableCombinationID
+		} else {
+			tableID = reservation.TableID
+		}
+	}
+
+	newTableIDs, err := rc.reservationService.TargetTableIDs(config.DB, tableID, combinationID)
+	if err != nil {
+		return rc.DomainErrorResponse(c, err)
+	}
+
+	// Get locks of the currently held and the requested tables together to prevent concurrent modifications
+	lockedTableIDs := append(reservation.HeldTableIDs(), newTableIDs...)
+	unlockTables := rc.tableLocks.Lock(lockedTableIDs)
+	defer unlockTables()
+
+	// Use database transaction to ensure atomicity
+	tx := config.DB.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	// Reload reservation within transaction with lock
+	if err := tx.Set("gorm:query_option", "FOR UPDATE").Preload("Tables").First(&reservation, id).Error; err != nil {
+		tx.Rollback()
+		if err == gorm.ErrRecordNotFound {
+			return rc.ErrorResponse(c, fiber.StatusNotFound, "Reservation not found")
+		}
+		return rc.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to fetch reservation")
+	}
+
+	// Double-check status and held tables within transaction
+	if err := rc.reservationService.EnsureModifiable(&reservation); err != nil {
+		tx.Rollback()
+		return rc.DomainErrorResponse(c, err)
+	}
+	if !containsTableIDs(lockedTableIDs, reservation.HeldTableIDs()) {
+		tx.Rollback()
+		return rc.ErrorResponse(c, fiber.StatusConflict, "Reservation was changed in the meantime, please try again")
+	}
+
+	// A table held for a waitlist offer must be claimed before it can be changed
+	var openOffers int64
+	if err := tx.Model(&models.WaitlistEntry{}).
+		Where("reservation_id = ? AND status = ?", reservation.ID, models.WaitlistStatusOffered).
+		Count(&openOffers).Error; err != nil {
+		tx.Rollback()
+		return rc.ErrorResponse(c, fiber.StatusInternalServerError, "Database error")
+	}
+	if openOffers > 0 {
+		tx.Rollback()
+		return rc.ErrorResponse(c, fiber.StatusConflict, "Claim the waitlist offer before modifying this reservation")
+	}
+
+	// Load the new table or combination and check it against every other reservation
+	target, err := rc.reservationService.LoadBookingTarget(tx, tableID, combinationID)
+	if err != nil {
+		tx.Rollback()
+		return rc.DomainErrorResponse(c, err)
+	}
+
+	duration, err = rc.reservationService.ValidateBooking(tx, target, reservationDateTime, partySize, duration, reservation.ID)
+	if err != nil {
+		tx.Rollback()
+		return rc.DomainErrorResponse(c, err)
+	}
+
+	// Update reservation
+	previous := reservation
+	reservation.Date = reservationDate
+	reservation.Time = reservationTime
+	reservation.Duration = duration
+	reservation.PartySize = partySize
+	reservation.Version++
+	target.Apply(&reservation)
+
+	if err := tx.Omit("Tables").Save(&reservation).Error; err != nil {
+		tx.Rollback()
+		return rc.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to modify reservation")
+	}
+	if err := tx.Model(&reservation).Association("Tables").Replace(reservation.Tables); err != nil {
+		tx.Rollback()
+		return rc.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to modify reservation")
+	}
+	if err := rc.notificationService.QueueReservationModified(tx, &reservation, &previous); err != nil {
+		tx.Rollback()
+		return rc.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to modify reservation")
+	}
+	if err := rc.floorEventService.RecordReservation(tx, models.FloorEventReservationModified, &reservation); err != nil {
+		tx.Rollback()
+		return rc.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to modify reservation")
+	}
+
+	// Commit transaction
+	if err := tx.Commit().Error; err != nil {
+		return rc.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to commit reservation changes")
+	}
+
+	// Offer the released slot to the first eligible waitlisted customer (table locks are still held)
+	rc.waitlistService.OfferFreedSlot(&previous)
+
+	// Load relationships for response (outside transaction)
+	config.DB.Preload("User").Preload("Table").Preload("Tables").First(&reservation, reservation.ID)
+
+	return rc.SuccessResponse(c, reservation, "Reservation modified successfully")
+}
+
+// containsTableIDs checks if every table ID in ids is in locked
+func containsTableIDs(locked, ids []uint) bool {
+	for _, id := range ids {
+		found := false
+		for _, lockedID := range locked {
+			if lockedID == id {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
 }
 
 // GetAllReservations gets all reservations (admin only)
 func (rc *ReservationController) GetAllReservations(c *fiber.Ctx) error {
 	var reservations []models.Reservation
-	query := config.DB.Preload("User").Preload("Table")
+	query := config.DB.Preload("User").Preload("Table").Preload("Tables")
 
 	// Filter by status if provided
 	status := c.Query("status")
@@ -379,10 +625,10 @@ func (rc *ReservationController) GetAllReservations(c *fiber.Ctx) error {
 		query = query.Where("user_id = ?", userID)
 	}
 
-	// Filter by table_id if provided
+	// Filter by table_id if provided (including table combinations holding the table)
 	tableID := c.Query("table_id")
 	if tableID != "" {
-		query = query.Where("table_id = ?", tableID)
+		query = query.Where("table_id = ? OR id IN (SELECT reservation_id FROM reservation_tables WHERE table_id = ?)", tableID, tableID)
 	}
 
 	if err := query.Order("date DESC, time DESC").Find(&reservations).Error; err != nil {
@@ -405,36 +651,28 @@ func (rc *ReservationController) UpdateReservationStatus(c *fiber.Ctx) error {
 	}
 
 	// Validate status
-	validStatus := false
-	for _, status := range []models.ReservationStatus{
-		models.ReservationStatusPending,
-		models.ReservationStatusConfirmed,
-		models.ReservationStatusCancelled,
-		models.ReservationStatusCompleted,
-	} {
-		if req.Status == status {
-			validStatus = true
-			break
-		}
-	}
-
-	if !validStatus {
+	if !req.Status.IsValid() {
 		return rc.ErrorResponse(c, fiber.StatusBadRequest, "Invalid reservation status")
 	}
 
-	// First, get reservation to know which table to lock
+	return rc.changeStatus(c, uint(id), req.Status, rc.CurrentUserID(c), req.Reason, "Reservation status updated successfully")
+}
+
+// changeStatus moves a reservation to a new status through the state machine on behalf of actorID
+// Shared by admin status updates and customer confirmation links
+func (rc *ReservationController) changeStatus(c *fiber.Ctx, id uint, status models.ReservationStatus, actorID uint, reason, message string) error {
+	// First, get reservation to know which tables to lock
 	var reservation models.Reservation
-	if err := config.DB.Preload("Table").First(&reservation, id).Error; err != nil {
+	if err := config.DB.Preload("Table").Preload("Tables").First(&reservation, id).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return rc.ErrorResponse(c, fiber.StatusNotFound, "Reservation not found")
 		}
 		return rc.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to fetch reservation")
 	}
 
-	// Get table-specific lock to prevent concurrent modifications
-	tableLock := rc.getTableLock(reservation.TableID)
-	tableLock.Lock()
-	defer tableLock.Unlock()
+	// Get locks of every held table to prevent concurrent modifications
+	unlockTables := rc.tableLocks.Lock(reservation.HeldTableIDs())
+	defer unlockTables()
 
 	// Use database transaction to ensure atomicity
 	tx := config.DB.Begin()
@@ -445,7 +683,7 @@ func (rc *ReservationController) UpdateReservationStatus(c *fiber.Ctx) error {
 	}()
 
 	// Reload reservation within transaction with lock
-	if err := tx.Set("gorm:query_option", "FOR UPDATE").Preload("Table").First(&reservation, id).Error; err != nil {
+	if err := tx.Set("gorm:query_option", "FOR UPDATE").Preload("Table").Preload("Tables").First(&reservation, id).Error; err != nil {
 		tx.Rollback()
 		if err == gorm.ErrRecordNotFound {
 			return rc.ErrorResponse(c, fiber.StatusNotFound, "Reservation not found")
@@ -453,39 +691,25 @@ func (rc *ReservationController) UpdateReservationStatus(c *fiber.Ctx) error {
 		return rc.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to fetch reservation")
 	}
 
-	// Update reservation status
-	reservation.Status = req.Status
-	if err := tx.Save(&reservation).Error; err != nil {
+	// Update reservation status if the state machine allows the transition
+	// Final statuses never move back, so an active reservation never needs a new conflict check
+	wasActive := reservation.IsActive()
+	if err := rc.reservationService.TransitionStatus(tx, &reservation, status, actorID, reason); err != nil {
 		tx.Rollback()
-		return rc.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to update reservation status")
+		return rc.DomainErrorResponse(c, err)
 	}
 
-	// Update table status based on reservation status
-	var table models.Table
-	if err := tx.Set("gorm:query_option", "FOR UPDATE").First(&table, reservation.TableID).Error; err != nil {
+	if err := tx.Save(&reservation).Error; err != nil {
 		tx.Rollback()
-		return rc.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to fetch table")
+		return rc.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to update reservation status")
 	}
-
-	if req.Status == models.ReservationStatusConfirmed {
-		table.Status = models.TableStatusReserved
-	} else if req.Status == models.ReservationStatusCancelled || req.Status == models.ReservationStatusCompleted {
-		// Check if there are other active reservations for this table
-		var activeReservations int64
-		tx.Model(&models.Reservation{}).
-			Where("table_id = ? AND id != ? AND status IN ?", reservation.TableID, id, []models.ReservationStatus{
-				models.ReservationStatusPending,
-				models.ReservationStatusConfirmed,
-			}).Count(&activeReservations)
-
-		if activeReservations == 0 {
-			table.Status = models.TableStatusAvailable
-		}
+	if err := rc.notificationService.QueueReservationStatusUpdated(tx, &reservation); err != nil {
+		tx.Rollback()
+		return rc.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to update reservation status")
 	}
-
-	if err := tx.Save(&table).Error; err != nil {
+	if err := rc.floorEventService.RecordReservationStatus(tx, &reservation); err != nil {
 		tx.Rollback()
-		return rc.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to update table status")
+		return rc.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to update reservation status")
 	}
 
 	// Commit transaction
@@ -493,24 +717,108 @@ func (rc *ReservationController) UpdateReservationStatus(c *fiber.Ctx) error {
 		return rc.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to commit status update")
 	}
 
+	// Offer the released tables to the first eligible waitlisted customer (table locks are still held)
+	if wasActive && !reservation.IsActive() {
+		rc.waitlistService.OfferFreedSlot(&reservation)
+	}
+
 	// Load relationships for response (outside transaction)
-	config.DB.Preload("User").Preload("Table").First(&reservation, reservation.ID)
+	config.DB.Preload("User").Preload("Table").Preload("Tables").First(&reservation, reservation.ID)
+
+	return rc.SuccessResponse(c, reservation, message)
+}
+
+// GetReservationByToken gets the reservation behind a confirmation link (public)
+func (rc *ReservationController) GetReservationByToken(c *fiber.Ctx) error {
+	reservation, err := services.FindReservationByToken(c.Params("token"))
+	if err != nil {
+		return rc.DomainErrorResponse(c, err)
+	}
+
+	return rc.SuccessResponse(c, fiber.Map{
+		"reservation": reservation,
+		"confirm_by":  services.ConfirmationDeadline(reservation),
+		"can_confirm": reservation.Status.CanTransitionTo(models.ReservationStatusConfirmed) && reservation.DepositAmount == 0,
+		"can_cancel":  reservation.Status.CanTransitionTo(models.ReservationStatusCancelled),
+	}, "Reservation retrieved successfully")
+}
+
+// ConfirmReservationByToken confirms a pending reservation through its confirmation link (public)
+func (rc *ReservationController) ConfirmReservationByToken(c *fiber.Ctx) error {
+	reservation, err := services.FindReservationByToken(c.Params("token"))
+	if err != nil {
+		return rc.DomainErrorResponse(c, err)
+	}
 
-	// Send notification asynchronously
-	if rc.notificationService != nil {
-		go rc.notificationService.SendReservationStatusUpdatedNotification(&reservation)
+	if err := services.EnsureConfirmableByCustomer(reservation); err != nil {
+		return rc.DomainErrorResponse(c, err)
 	}
 
-	return rc.SuccessResponse(c, reservation, "Reservation status updated successfully")
+	return rc.changeStatus(c, reservation.ID, models.ReservationStatusConfirmed, reservation.UserID,
+		"Confirmed by customer via confirmation link", "Reservation confirmed successfully")
+}
+
+// CancelReservationByToken cancels a reservation through its confirmation link (public)
+func (rc *ReservationController) CancelReservationByToken(c *fiber.Ctx) error {
+	reservation, err := services.FindReservationByToken(c.Params("token"))
+	if err != nil {
+		return rc.DomainErrorResponse(c, err)
+	}
+
+	reason := c.Query("reason")
+	if reason == "" {
+		reason = "Cancelled by customer via confirmation link"
+	}
+
+	return rc.changeStatus(c, reservation.ID, models.ReservationStatusCancelled, reservation.UserID,
+		reason, "Reservation cancelled successfully")
+}
+
+// GetReservationHistory gets the status change history of a reservation (admin only)
+func (rc *ReservationController) GetReservationHistory(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return rc.ErrorResponse(c, fiber.StatusBadRequest, "Invalid reservation ID")
+	}
+
+	var reservation models.Reservation
+	if err := config.DB.Unscoped().First(&reservation, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return rc.ErrorResponse(c, fiber.StatusNotFound, "Reservation not found")
+		}
+		return rc.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to fetch reservation")
+	}
+
+	history, err := rc.reservationService.GetStatusHistory(config.DB, reservation.ID)
+	if err != nil {
+		return rc.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to fetch reservation history")
+	}
+
+	return rc.SuccessResponse(c, history, "Reservation history retrieved successfully")
 }
 
 // GetReservationStatuses gets all available reservation statuses
 func (rc *ReservationController) GetReservationStatuses(c *fiber.Ctx) error {
-	statuses := []map[string]string{
-		{"value": string(models.ReservationStatusPending), "label": "Pending"},
-		{"value": string(models.ReservationStatusConfirmed), "label": "Confirmed"},
-		{"value": string(models.ReservationStatusCancelled), "label": "Cancelled"},
-		{"value": string(models.ReservationStatusCompleted), "label": "Completed"},
+	labels := map[models.ReservationStatus]string{
+		models.ReservationStatusPending:   "Pending",
+		models.ReservationStatusConfirmed: "Confirmed",
+		models.ReservationStatusSeated:    "Seated",
+		models.ReservationStatusCompleted: "Completed",
+		models.ReservationStatusCancelled: "Cancelled",
+		models.ReservationStatusNoShow:    "No-show",
+	}
+
+	statuses := make([]fiber.Map, 0, len(models.ReservationStatuses))
+	for _, status := range models.ReservationStatuses {
+		transitions := status.Transitions()
+		if transitions == nil {
+			transitions = []models.ReservationStatus{}
+		}
+		statuses = append(statuses, fiber.Map{
+			"value":       status,
+			"label":       labels[status],
+			"transitions": transitions, // Statuses the reservation may move to next
+		})
 	}
 
 	return rc.SuccessResponse(c, statuses, "Reservation statuses retrieved successfully")
@@ -550,20 +858,18 @@ func (rc *ReservationController) CreateReservationByAdmin(c *fiber.Ctx) error {
 	}
 
 	// Combine date and time
-	reservationDateTime := time.Date(
-		reservationDate.Year(),
-		reservationDate.Month(),
-		reservationDate.Day(),
-		reservationTime.Hour(),
-		reservationTime.Minute(),
-		0, 0, time.UTC,
-	)
+	reservationDateTime := utils.CombineDateTime(reservationDate, reservationTime)
 
 	// Check if reservation is in the past
 	if reservationDateTime.Before(time.Now()) {
 		return rc.ErrorResponse(c, fiber.StatusBadRequest, "Cannot make reservation in the past")
 	}
 
+	// Validate requested duration
+	if req.Duration < 0 || req.Duration > config.GetMaxReservationDuration() {
+		return rc.ErrorResponse(c, fiber.StatusBadRequest, "Invalid reservation duration")
+	}
+
 	// Get or create user by phone
 	var user models.User
 	// Check if active user exists
@@ -601,10 +907,12 @@ func (rc *ReservationController) CreateReservationByAdmin(c *fiber.Ctx) error {
 		}
 	}
 
-	// Get table-specific lock to prevent concurrent reservations for the same table
-	tableLock := rc.getTableLock(req.TableID)
-	tableLock.Lock()
-	defer tableLock.Unlock()
+	// Get locks of the tables the booking holds to prevent concurrent reservations for the same tables
+	unlock, err := rc.lockBooking(req.TableID, req.TableCombinationID)
+	if err != nil {
+		return rc.DomainErrorResponse(c, err)
+	}
+	defer unlock()
 
 	// Use database transaction to ensure atomicity
 	tx := config.DB.Begin()
@@ -614,58 +922,39 @@ func (rc *ReservationController) CreateReservationByAdmin(c *fiber.Ctx) error {
 		}
 	}()
 
-	// Check if table exists and lock the row (SELECT FOR UPDATE)
-	var table models.Table
-	if err := tx.Set("gorm:query_option", "FOR UPDATE").First(&table, req.TableID).Error; err != nil {
+	// Validate the requested table or combination, or assign the best-fitting free table
+	target, duration, err := rc.prepareBooking(tx, req.TableID, req.TableCombinationID, reservationDateTime, req.PartySize, req.Duration, req.Location)
+	if err != nil {
 		tx.Rollback()
-		if err == gorm.ErrRecordNotFound {
-			return rc.ErrorResponse(c, fiber.StatusNotFound, "Table not found")
-		}
-		return rc.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to fetch table")
+		return rc.DomainErrorResponse(c, err)
 	}
 
-	// Check if table is available
-	if table.Status != models.TableStatusAvailable {
-		tx.Rollback()
-		return rc.ErrorResponse(c, fiber.StatusBadRequest, "Table is not available")
+	// Create reservation; staff booked it with the customer, so it does not wait for their confirmation
+	reservation := models.Reservation{
+		UserID:    user.ID,
+		Date:      reservationDate,
+		Time:      reservationTime,
+		Duration:  duration,
+		PartySize: req.PartySize,
+		Status:    models.ReservationStatusConfirmed,
 	}
+	target.Apply(&reservation)
 
-	// Check if table is already reserved at this date and time
-	var existingReservation models.Reservation
-	if err := tx.Where("table_id = ? AND date = ? AND time = ? AND status IN ?",
-		req.TableID,
-		reservationDate,
-		reservationTime,
-		[]models.ReservationStatus{
-			models.ReservationStatusPending,
-			models.ReservationStatusConfirmed,
-		}).First(&existingReservation).Error; err == nil {
-		tx.Rollback()
-		return rc.ErrorResponse(c, fiber.StatusConflict, "Table is already reserved at this date and time")
-	} else if err != gorm.ErrRecordNotFound {
+	if err := tx.Create(&reservation).Error; err != nil {
 		tx.Rollback()
-		return rc.ErrorResponse(c, fiber.StatusInternalServerError, "Database error")
+		return rc.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to create reservation")
 	}
-
-	// Create reservation
-	reservation := models.Reservation{
-		UserID:  user.ID,
-		TableID: req.TableID,
-		Date:    reservationDate,
-		Time:    reservationTime,
-		Status:  models.ReservationStatusPending,
+	if err := rc.reservationService.RecordCreated(tx, &reservation, rc.CurrentUserID(c), ""); err != nil {
+		tx.Rollback()
+		return rc.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to create reservation")
 	}
-
-	if err := tx.Create(&reservation).Error; err != nil {
+	if err := rc.notificationService.QueueReservationCreated(tx, &reservation); err != nil {
 		tx.Rollback()
 		return rc.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to create reservation")
 	}
-
-	// Update table status to reserved
-	table.Status = models.TableStatusReserved
-	if err := tx.Save(&table).Error; err != nil {
+	if err := rc.floorEventService.RecordReservation(tx, models.FloorEventReservationCreated, &reservation); err != nil {
 		tx.Rollback()
-		return rc.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to update table status")
+		return rc.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to create reservation")
 	}
 
 	// Commit transaction
@@ -674,12 +963,7 @@ func (rc *ReservationController) CreateReservationByAdmin(c *fiber.Ctx) error {
 	}
 
 	// Load relationships for response (outside transaction)
-	config.DB.Preload("User").Preload("Table").First(&reservation, reservation.ID)
-
-	// Send notification asynchronously
-	if rc.notificationService != nil {
-		go rc.notificationService.SendReservationCreatedNotification(&reservation)
-	}
+	config.DB.Preload("User").Preload("Table").Preload("Tables").First(&reservation, reservation.ID)
 
 	return rc.SuccessResponse(c, reservation, "Reservation created successfully by admin")
 }
@@ -0,0 +1,238 @@
+package controllers
+
+import (
+	"strconv"
+	"strings"
+
+	"restaurant-booking-backend/config"
+	"restaurant-booking-backend/models"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// TableCombinationController table combination controller
+type TableCombinationController struct {
+	BaseController
+}
+
+// TableCombinationRequest create/update table combination request structure
+type TableCombinationRequest struct {
+	Name     string `json:"name"`      // Required on create
+	TableIDs []uint `json:"table_ids"` // At least two member tables - required on create
+	Capacity *int   `json:"capacity"`  // Optional - defaults to the sum of member table capacities
+	IsActive *bool  `json:"is_active"` // Optional - defaults to true
+}
+
+// loadCombinationTables loads member tables and validates the selection
+func loadCombinationTables(tableIDs []uint) ([]models.Table, string) {
+	unique := make(map[uint]bool)
+	for _, tableID := range tableIDs {
+		unique[tableID] = true
+	}
+	if len(unique) < 2 {
+		return nil, "A table combination needs at least two different tables"
+	}
+
+	var tables []models.Table
+	if err := config.DB.Where("id IN ?", tableIDs).Order("number ASC").Find(&tables).Error; err != nil {
+		return nil, "Failed to fetch tables"
+	}
+	if len(tables) != len(unique) {
+		return nil, "One or more tables not found"
+	}
+
+	return tables, ""
+}
+
+// combinedCapacity returns the sum of member table capacities
+func combinedCapacity(tables []models.Table) int {
+	capacity := 0
+	for _, table := range tables {
+		capacity += table.Capacity
+	}
+	return capacity
+}
+
+// GetTableCombinations gets bookable table combinations (public)
+func (tcc *TableCombinationController) GetTableCombinations(c *fiber.Ctx) error {
+	var combinations []models.TableCombination
+	query := config.DB.Where("is_active = ?", true).Preload("Tables")
+
+	// Filter by party size if provided
+	partySize := c.Query("party_size")
+	if partySize != "" {
+		size, err := strconv.Atoi(partySize)
+		if err != nil || size <= 0 {
+			return tcc.ErrorResponse(c, fiber.StatusBadRequest, "Invalid party_size")
+		}
+		query = query.Where("capacity >= ?", size)
+	}
+
+	if err := query.Order("capacity ASC").Find(&combinations).Error; err != nil {
+		return tcc.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to fetch table combinations")
+	}
+
+	return tcc.SuccessResponse(c, combinations, "Table combinations retrieved successfully")
+}
+
+// GetAllTableCombinations gets all table combinations (admin only)
+func (tcc *TableCombinationController) GetAllTableCombinations(c *fiber.Ctx) error {
+	var combinations []models.TableCombination
+	if err := config.DB.Preload("Tables").Order("name ASC").Find(&combinations).Error; err != nil {
+		return tcc.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to fetch table combinations")
+	}
+
+	return tcc.SuccessResponse(c, combinations, "Table combinations retrieved successfully")
+}
+
+// GetTableCombinationByID gets a single table combination by ID (admin only)
+func (tcc *TableCombinationController) GetTableCombinationByID(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return tcc.ErrorResponse(c, fiber.StatusBadRequest, "Invalid table combination ID")
+	}
+
+	var combination models.TableCombination
+	if err := config.DB.Preload("Tables").First(&combination, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return tcc.ErrorResponse(c, fiber.StatusNotFound, "Table combination not found")
+		}
+		return tcc.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to fetch table combination")
+	}
+
+	return tcc.SuccessResponse(c, combination, "Table combination retrieved successfully")
+}
+
+// CreateTableCombination creates a combinable group of tables (admin only)
+func (tcc *TableCombinationController) CreateTableCombination(c *fiber.Ctx) error {
+	var req TableCombinationRequest
+	if err := c.BodyParser(&req); err != nil {
+		return tcc.ValidationErrorResponse(c, err.Error())
+	}
+
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		return tcc.ValidationErrorResponse(c, "Name is required")
+	}
+
+	tables, message := loadCombinationTables(req.TableIDs)
+	if message != "" {
+		return tcc.ValidationErrorResponse(c, message)
+	}
+
+	combination := models.TableCombination{
+		Name:     req.Name,
+		Capacity: combinedCapacity(tables),
+		IsActive: true,
+		Tables:   tables,
+	}
+	if req.Capacity != nil {
+		if *req.Capacity <= 0 {
+			return tcc.ValidationErrorResponse(c, "Capacity must be greater than 0")
+		}
+		combination.Capacity = *req.Capacity
+	}
+	if req.IsActive != nil {
+		combination.IsActive = *req.IsActive
+	}
+
+	if err := config.DB.Create(&combination).Error; err != nil {
+		return tcc.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to create table combination")
+	}
+
+	return tcc.SuccessResponse(c, combination, "Table combination created successfully")
+}
+
+// UpdateTableCombination updates a table combination (admin only)
+func (tcc *TableCombinationController) UpdateTableCombination(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return tcc.ErrorResponse(c, fiber.StatusBadRequest, "Invalid table combination ID")
+	}
+
+	var combination models.TableCombination
+	if err := config.DB.Preload("Tables").First(&combination, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return tcc.ErrorResponse(c, fiber.StatusNotFound, "Table combination not found")
+		}
+		return tcc.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to fetch table combination")
+	}
+
+	var req TableCombinationRequest
+	if err := c.BodyParser(&req); err != nil {
+		return tcc.ValidationErrorResponse(c, err.Error())
+	}
+
+	// Update fields if provided
+	if req.Name != "" {
+		combination.Name = strings.TrimSpace(req.Name)
+	}
+	var tables []models.Table
+	if req.TableIDs != nil {
+		var message string
+		tables, message = loadCombinationTables(req.TableIDs)
+		if message != "" {
+			return tcc.ValidationErrorResponse(c, message)
+		}
+		combination.Capacity = combinedCapacity(tables)
+	}
+	if req.Capacity != nil {
+		if *req.Capacity <= 0 {
+			return tcc.ValidationErrorResponse(c, "Capacity must be greater than 0")
+		}
+		combination.Capacity = *req.Capacity
+	}
+	if req.IsActive != nil {
+		combination.IsActive = *req.IsActive
+	}
+
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Tables").Save(&combination).Error; err != nil {
+			return err
+		}
+		if tables != nil {
+			return tx.Model(&combination).Association("Tables").Replace(tables)
+		}
+		return nil
+	})
+	if err != nil {
+		return tcc.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to update table combination")
+	}
+
+	config.DB.Preload("Tables").First(&combination, combination.ID)
+
+	return tcc.SuccessResponse(c, combination, "Table combination updated successfully")
+}
+
+// DeleteTableCombination deletes a table combination (admin only)
+func (tcc *TableCombinationController) DeleteTableCombination(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return tcc.ErrorResponse(c, fiber.StatusBadRequest, "Invalid table combination ID")
+	}
+
+	var combination models.TableCombination
+	if err := config.DB.First(&combination, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return tcc.ErrorResponse(c, fiber.StatusNotFound, "Table combination not found")
+		}
+		return tcc.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to fetch table combination")
+	}
+
+	// Check if combination has active reservations
+	var activeReservations int64
+	config.DB.Model(&models.Reservation{}).
+		Where("table_combination_id = ? AND status IN ?", id, models.ActiveReservationStatuses).
+		Count(&activeReservations)
+
+	if activeReservations > 0 {
+		return tcc.ErrorResponse(c, fiber.StatusBadRequest, "Cannot delete table combination with active reservations")
+	}
+
+	if err := config.DB.Delete(&combination).Error; err != nil {
+		return tcc.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to delete table combination")
+	}
+
+	return tcc.SuccessResponse(c, nil, "Table combination deleted successfully")
+}
@@ -3,9 +3,12 @@ package controllers
 import (
 	"errors"
 	"strconv"
+	"time"
 
 	"restaurant-booking-backend/config"
 	"restaurant-booking-backend/models"
+	"restaurant-booking-backend/services"
+	"restaurant-booking-backend/utils"
 
 	"github.com/gofiber/fiber/v2"
 	"gorm.io/gorm"
@@ -14,30 +17,62 @@ import (
 // TableController table controller
 type TableController struct {
 	BaseController
+	reservationService services.ReservationService
+	floorEventService  services.FloorEventService
 }
 
 // CreateTableRequest create table request structure
 type CreateTableRequest struct {
-	Number   int                `json:"number" binding:"required,gt=0"`
-	Capacity int                `json:"capacity" binding:"required,gt=0"`
-	Location string             `json:"location" binding:"required"`
-	Status   models.TableStatus `json:"status"`
+	Number              int                `json:"number" binding:"required,gt=0"`
+	Capacity            int                `json:"capacity" binding:"required,gt=0"`
+	Location            string             `json:"location" binding:"required"`
+	Status              models.TableStatus `json:"status"`
+	ReservationDuration int                `json:"reservation_duration"` // Optional, minutes (0 = restaurant default)
 }
 
 // UpdateTableRequest update table request structure
 type UpdateTableRequest struct {
-	Number   int                `json:"number" binding:"omitempty,gt=0"`
-	Capacity int                `json:"capacity" binding:"omitempty,gt=0"`
-	Location string             `json:"location"`
-	Status   models.TableStatus `json:"status"`
+	Number              int                `json:"number" binding:"omitempty,gt=0"`
+	Capacity            int                `json:"capacity" binding:"omitempty,gt=0"`
+	Location            string             `json:"location"`
+	Status              models.TableStatus `json:"status"`
+	ReservationDuration *int               `json:"reservation_duration"` // Pointer to allow resetting to restaurant default (0)
+}
+
+// parseOccupancyInstant reads optional date and time query parameters (defaults to now)
+func parseOccupancyInstant(c *fiber.Ctx) (time.Time, string) {
+	if c.Query("date") == "" && c.Query("time") == "" {
+		return time.Now(), ""
+	}
+
+	date, err := time.Parse("2006-01-02", c.Query("date"))
+	if err != nil {
+		return time.Time{}, "Invalid date format. Use YYYY-MM-DD"
+	}
+	clock, err := time.Parse("15:04", c.Query("time"))
+	if err != nil {
+		return time.Time{}, "Invalid time format. Use HH:MM"
+	}
+	return utils.CombineDateTime(date, clock), ""
+}
+
+// validateManualTableStatus checks that only the manual service flag is set on a table
+func validateManualTableStatus(status models.TableStatus) bool {
+	return status == models.TableStatusAvailable || status == models.TableStatusMaintenance
 }
 
 // GetAllTables gets all tables with filtering (admin only)
+// Occupancy is derived from reservations at date/time query parameters (defaults to now)
 func (tc *TableController) GetAllTables(c *fiber.Ctx) error {
 	var tables []models.Table
 	query := config.DB
 
-	// Filter by status if provided
+	at, message := parseOccupancyInstant(c)
+	if message != "" {
+		return tc.ErrorResponse(c, fiber.StatusBadRequest, message)
+	}
+
+	// Filter by manual service status if provided
 	status := c.Query("status")
 	if status != "" {
 		query = query.Where("status = ?", status)
@@ -55,20 +90,43 @@ func (tc *TableController) GetAllTables(c *fiber.Ctx) error {
 	// Filter by location if provided
 	location := c.Query("location")
 	if location != "" {
-		query = query.Where("location ILIKE ?", "%"+location+"%")
+		query = query.Where("LOWER(location) LIKE LOWER(?)", "%"+location+"%")
 	}
 
 	if err := query.Order("number ASC").Find(&tables).Error; err != nil {
 		return tc.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to fetch tables")
 	}
 
+	if err := tc.reservationService.ResolveOccupancy(config.DB, tables, at); err != nil {
+		return tc.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to fetch table occupancy")
+	}
+
+	// Filter by derived occupancy if provided
+	occupancy := c.Query("occupancy")
+	if occupancy != "" {
+		filtered := []models.Table{}
+		for _, table := range tables {
+			if string(table.Occupancy) == occupancy {
+				filtered = append(filtered, table)
+			}
+		}
+		tables = filtered
+	}
+
 	return tc.SuccessResponse(c, tables, "Tables retrieved successfully")
 }
 
 // GetAvailableTables gets available tables (public - for customers)
+// Without date/time returns tables free right now; with date/time returns tables free
+// for a reservation starting then (using each table's reservation duration)
 func (tc *TableController) GetAvailableTables(c *fiber.Ctx) error {
 	var tables []models.Table
-	query := config.DB.Where("status = ?", models.TableStatusAvailable)
+	query := config.DB.Where("status != ?", models.TableStatusMaintenance)
+
+	at, message := parseOccupancyInstant(c)
+	if message != "" {
+		return tc.ErrorResponse(c, fiber.StatusBadRequest, message)
+	}
 
 	// Filter by minimum capacity if provided
 	capacity := c.Query("capacity")
@@ -82,14 +140,101 @@ func (tc *TableController) GetAvailableTables(c *fiber.Ctx) error {
 	// Filter by location if provided
 	location := c.Query("location")
 	if location != "" {
-		query = query.Where("location ILIKE ?", "%"+location+"%")
+		query = query.Where("LOWER(location) LIKE LOWER(?)", "%"+location+"%")
 	}
 
 	if err := query.Order("number ASC").Find(&tables).Error; err != nil {
 		return tc.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to fetch available tables")
 	}
 
-	return tc.SuccessResponse(c, tables, "Available tables retrieved successfully")
+	available := []models.Table{}
+	if c.Query("date") == "" {
+		// Tables free right now
+		if err := tc.reservationService.ResolveOccupancy(config.DB, tables, at); err != nil {
+			return tc.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to fetch available tables")
+		}
+		for _, table := range tables {
+			if table.Occupancy == models.TableStatusAvailable {
+				available = append(available, table)
+			}
+		}
+	} else {
+		// Tables free for a reservation starting at the requested date and time
+		for i := range tables {
+			duration := tc.reservationService.ResolveDuration(&tables[i], 0)
+			free, err := tc.reservationService.IsTableFree(config.DB, &tables[i], at, at.Add(time.Duration(duration)*time.Minute))
+			if err != nil {
+				return tc.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to fetch available tables")
+			}
+			if free {
+				tables[i].Occupancy = models.TableStatusAvailable
+				available = append(available, tables[i])
+			}
+		}
+	}
+
+	return tc.SuccessResponse(c, available, "Available tables retrieved successfully")
+}
+
+// GetTableAvailability gets free time slots per table for a date (public - for booking widget)
+// Query: date (required), party_size (required), time or from/to range, duration, location
+func (tc *TableController) GetTableAvailability(c *fiber.Ctx) error {
+	date, err := time.Parse("2006-01-02", c.Query("date"))
+	if err != nil {
+		return tc.ErrorResponse(c, fiber.StatusBadRequest, "Invalid date format. Use YYYY-MM-DD")
+	}
+
+	partySize, err := strconv.Atoi(c.Query("party_size"))
+	if err != nil || partySize <= 0 {
+		return tc.ErrorResponse(c, fiber.StatusBadRequest, "Party size must be a positive number")
+	}
+
+	duration := 0
+	if c.Query("duration") != "" {
+		duration, err = strconv.Atoi(c.Query("duration"))
+		if err != nil || duration <= 0 || duration > config.GetMaxReservationDuration() {
+			return tc.ErrorResponse(c, fiber.StatusBadRequest, "Invalid reservation duration")
+		}
+	}
+
+	query := services.AvailabilityQuery{
+		Date:      date,
+		PartySize: partySize,
+		Duration:  duration,
+		Location:  c.Query("location"),
+	}
+
+	if c.Query("time") != "" {
+		// Single slot
+		slotTime, err := time.Parse("15:04", c.Query("time"))
+		if err != nil {
+			return tc.ErrorResponse(c, fiber.StatusBadRequest, "Invalid time format. Use HH:MM")
+		}
+		query.From = utils.CombineDateTime(date, slotTime)
+		query.To = query.From
+	} else {
+		// Time range (defaults to the whole day)
+		fromTime, err := time.Parse("15:04", c.Query("from", "00:00"))
+		if err != nil {
+			return tc.ErrorResponse(c, fiber.StatusBadRequest, "Invalid from time format. Use HH:MM")
+		}
+		toTime, err := time.Parse("15:04", c.Query("to", "23:59"))
+		if err != nil {
+			return tc.ErrorResponse(c, fiber.StatusBadRequest, "Invalid to time format. Use HH:MM")
+		}
+		query.From = utils.CombineDateTime(date, fromTime)
+		query.To = utils.CombineDateTime(date, toTime)
+		if !query.To.After(query.From) {
+			return tc.ErrorResponse(c, fiber.StatusBadRequest, "Time range end must be after its start")
+		}
+	}
+
+	availability, err := tc.reservationService.GetTableAvailability(config.DB, query)
+	if err != nil {
+		return tc.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to fetch table availability")
+	}
+
+	return tc.SuccessResponse(c, availability, "Table availability retrieved successfully")
 }
 
 // GetTableByID gets a single table by ID
@@ -107,7 +252,17 @@ func (tc *TableController) GetTableByID(c *fiber.Ctx) error {
 		return tc.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to fetch table")
 	}
 
-	return tc.SuccessResponse(c, table, "Table retrieved successfully")
+	at, message := parseOccupancyInstant(c)
+	if message != "" {
+		return tc.ErrorResponse(c, fiber.StatusBadRequest, message)
+	}
+
+	tables := []models.Table{table}
+	if err := tc.reservationService.ResolveOccupancy(config.DB, tables, at); err != nil {
+		return tc.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to fetch table occupancy")
+	}
+
+	return tc.SuccessResponse(c, tables[0], "Table retrieved successfully")
 }
 
 // CreateTable creates a new table (admin only)
@@ -129,15 +284,30 @@ func (tc *TableController) CreateTable(c *fiber.Ctx) error {
 	if req.Status == "" {
 		req.Status = models.TableStatusAvailable
 	}
+	if !validateManualTableStatus(req.Status) {
+		return tc.ErrorResponse(c, fiber.StatusBadRequest, "Table status must be 'available' or 'maintenance'; occupancy is derived from reservations")
+	}
+
+	// Validate reservation duration
+	if req.ReservationDuration < 0 || req.ReservationDuration > config.GetMaxReservationDuration() {
+		return tc.ErrorResponse(c, fiber.StatusBadRequest, "Invalid reservation duration")
+	}
 
 	table := models.Table{
-		Number:   req.Number,
-		Capacity: req.Capacity,
-		Location: req.Location,
-		Status:   req.Status,
+		Number:              req.Number,
+		Capacity:            req.Capacity,
+		Location:            req.Location,
+		Status:              req.Status,
+		ReservationDuration: req.ReservationDuration,
 	}
 
-	if err := config.DB.Create(&table).Error; err != nil {
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&table).Error; err != nil {
+			return err
+		}
+		return tc.floorEventService.RecordTable(tx, models.FloorEventTableCreated, &table)
+	})
+	if err != nil {
 		return tc.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to create table")
 	}
 
@@ -182,11 +352,30 @@ func (tc *TableController) UpdateTable(c *fiber.Ctx) error {
 	if req.Location != "" {
 		table.Location = req.Location
 	}
+	eventType := models.FloorEventTableUpdated
 	if req.Status != "" {
+		if !validateManualTableStatus(req.Status) {
+			return tc.ErrorResponse(c, fiber.StatusBadRequest, "Table status must be 'available' or 'maintenance'; occupancy is derived from reservations")
+		}
+		if req.Status != table.Status {
+			eventType = models.FloorEventTableStatusChanged
+		}
 		table.Status = req.Status
 	}
+	if req.ReservationDuration != nil {
+		if *req.ReservationDuration < 0 || *req.ReservationDuration > config.GetMaxReservationDuration() {
+			return tc.ErrorResponse(c, fiber.StatusBadRequest, "Invalid reservation duration")
+		}
+		table.ReservationDuration = *req.ReservationDuration
+	}
 
-	if err := config.DB.Save(&table).Error; err != nil {
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&table).Error; err != nil {
+			return err
+		}
+		return tc.floorEventService.RecordTable(tx, eventType, &table)
+	})
+	if err != nil {
 		return tc.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to update table")
 	}
 
@@ -211,16 +400,19 @@ func (tc *TableController) DeleteTable(c *fiber.Ctx) error {
 	// Check if table has active reservations
 	var activeReservations int64
 	config.DB.Model(&models.Reservation{}).
-		Where("table_id = ? AND status IN ?", id, []models.ReservationStatus{
-			models.ReservationStatusPending,
-			models.ReservationStatusConfirmed,
-		}).Count(&activeReservations)
+		Where("(table_id = ? OR id IN (SELECT reservation_id FROM reservation_tables WHERE table_id = ?)) AND status IN ?", id, id, models.ActiveReservationStatuses).Count(&activeReservations)
 
 	if activeReservations > 0 {
 		return tc.ErrorResponse(c, fiber.StatusBadRequest, "Cannot delete table with active reservations")
 	}
 
-	if err := config.DB.Delete(&table).Error; err != nil {
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&table).Error; err != nil {
+			return err
+		}
+		return tc.floorEventService.RecordTable(tx, models.FloorEventTableDeleted, &table)
+	})
+	if err != nil {
 		return tc.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to delete table")
 	}
 
@@ -5,6 +5,7 @@ import (
 
 	"restaurant-booking-backend/config"
 	"restaurant-booking-backend/models"
+	"restaurant-booking-backend/services"
 
 	"github.com/gofiber/fiber/v2"
 	"gorm.io/gorm"
@@ -29,10 +30,10 @@ func (uc *UserController) GetAllUsers(c *fiber.Ctx) error {
 	// Search by name or phone if provided
 	search := c.Query("search")
 	if search != "" {
-		query = query.Where("name ILIKE ? OR phone ILIKE ?", "%"+search+"%", "%"+search+"%")
+		query = query.Where("LOWER(name) LIKE LOWER(?) OR phone LIKE ?", "%"+search+"%", "%"+search+"%")
 	}
 
-	if err := query.Select("id, phone, name, role, created_at, updated_at").Order("created_at DESC").Find(&users).Error; err != nil {
+	if err := query.Select("id, phone, name, email, role, no_show_count, created_at, updated_at").Order("created_at DESC").Find(&users).Error; err != nil {
 		return uc.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to fetch users")
 	}
 
@@ -47,7 +48,7 @@ func (uc *UserController) GetUserByID(c *fiber.Ctx) error {
 	}
 
 	var user models.User
-	if err := config.DB.Select("id, phone, name, role, created_at, updated_at").First(&user, id).Error; err != nil {
+	if err := config.DB.Select("id, phone, name, email, role, no_show_count, created_at, updated_at").First(&user, id).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return uc.ErrorResponse(c, fiber.StatusNotFound, "User not found")
 		}
@@ -110,6 +111,76 @@ func (uc *UserController) UpdateUserRole(c *fiber.Ctx) error {
 	return uc.SuccessResponse(c, user, "User role updated successfully")
 }
 
+// UpdateReminderPreference opts the current user in to or out of reservation reminders
+func (uc *UserController) UpdateReminderPreference(c *fiber.Ctx) error {
+	var req struct {
+		Enabled *bool `json:"enabled"`
+	}
+
+	if err := c.BodyParser(&req); err != nil {
+		return uc.ValidationErrorResponse(c, err.Error())
+	}
+
+	if req.Enabled == nil {
+		return uc.ValidationErrorResponse(c, "Enabled is required")
+	}
+
+	userID := uc.CurrentUserID(c)
+	if err := config.DB.Model(&models.User{}).Where("id = ?", userID).
+		Update("reminders_disabled", !*req.Enabled).Error; err != nil {
+		return uc.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to update reminder preference")
+	}
+
+	return uc.SuccessResponse(c, fiber.Map{"reminders_enabled": *req.Enabled}, "Reminder preference updated successfully")
+}
+
+// UpdateLanguagePreference sets the language and calendar the current user receives notifications in
+// An empty value goes back to the default (the calendar then follows the language)
+func (uc *UserController) UpdateLanguagePreference(c *fiber.Ctx) error {
+	var req struct {
+		Language *models.Language `json:"language"`
+		Calendar *models.Calendar `json:"calendar"`
+	}
+
+	if err := c.BodyParser(&req); err != nil {
+		return uc.ValidationErrorResponse(c, err.Error())
+	}
+
+	if req.Language == nil && req.Calendar == nil {
+		return uc.ValidationErrorResponse(c, "Language or calendar is required")
+	}
+
+	updates := map[string]interface{}{}
+	if req.Language != nil {
+		if *req.Language != "" && !req.Language.IsValid() {
+			return uc.ErrorResponse(c, fiber.StatusBadRequest, "Unsupported language. Use en or fa")
+		}
+		updates["language"] = *req.Language
+	}
+	if req.Calendar != nil {
+		if *req.Calendar != "" && !req.Calendar.IsValid() {
+			return uc.ErrorResponse(c, fiber.StatusBadRequest, "Unsupported calendar. Use gregorian or jalali")
+		}
+		updates["calendar"] = *req.Calendar
+	}
+
+	userID := uc.CurrentUserID(c)
+	if err := config.DB.Model(&models.User{}).Where("id = ?", userID).Updates(updates).Error; err != nil {
+		return uc.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to update language preference")
+	}
+
+	var user models.User
+	if err := config.DB.First(&user, userID).Error; err != nil {
+		return uc.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to fetch user")
+	}
+	locale := services.LocaleFor(&user)
+
+	return uc.SuccessResponse(c, fiber.Map{
+		"language": locale.Language,
+		"calendar": locale.Calendar,
+	}, "Language preference updated successfully")
+}
+
 // DeleteUser deletes a user (admin only)
 func (uc *UserController) DeleteUser(c *fiber.Ctx) error {
 	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
@@ -128,10 +199,7 @@ func (uc *UserController) DeleteUser(c *fiber.Ctx) error {
 	// Check if user has active reservations
 	var activeReservations int64
 	config.DB.Model(&models.Reservation{}).
-		Where("user_id = ? AND status IN ?", id, []models.ReservationStatus{
-			models.ReservationStatusPending,
-			models.ReservationStatusConfirmed,
-		}).Count(&activeReservations)
+		Where("user_id = ? AND status IN ?", id, models.ActiveReservationStatuses).Count(&activeReservations)
 
 	if activeReservations > 0 {
 		return uc.ErrorResponse(c, fiber.StatusBadRequest, "Cannot delete user with active reservations")
@@ -0,0 +1,288 @@
+package controllers
+
+import (
+	"strconv"
+	"time"
+
+	"restaurant-booking-backend/config"
+	"restaurant-booking-backend/models"
+	"restaurant-booking-backend/services"
+	"restaurant-booking-backend/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// WaitlistController waitlist controller
+type WaitlistController struct {
+	BaseController
+	reservationService  *services.ReservationService
+	waitlistService     *services.WaitlistService
+	notificationService *services.NotificationService
+	floorEventService   *services.FloorEventService
+	policyService       *services.BookingPolicyService
+}
+
+// NewWaitlistController creates a new waitlist controller
+func NewWaitlistController() *WaitlistController {
+	return &WaitlistController{
+		reservationService:  &services.ReservationService{},
+		waitlistService:     &services.WaitlistService{},
+		notificationService: &services.NotificationService{},
+		floorEventService:   &services.FloorEventService{},
+		policyService:       &services.BookingPolicyService{},
+	}
+}
+
+// JoinWaitlistRequest join waitlist request structure
+type JoinWaitlistRequest struct {
+	Date      string `json:"date" binding:"required"`       // Format: "2006-01-02"
+	Time      string `json:"time" binding:"required"`       // Format: "15:04"
+	PartySize int    `json:"party_size" binding:"required"` // Number of guests
+}
+
+// JoinWaitlist adds the current user to the waitlist for a fully booked date and time (customer only)
+func (wc *WaitlistController) JoinWaitlist(c *fiber.Ctx) error {
+	userID := c.Locals("user_id")
+	if userID == nil {
+		return wc.ErrorResponse(c, fiber.StatusUnauthorized, "User not authenticated")
+	}
+
+	var req JoinWaitlistRequest
+	if err := c.BodyParser(&req); err != nil {
+		return wc.ValidationErrorResponse(c, err.Error())
+	}
+
+	// Parse date and time
+	date, err := time.Parse("2006-01-02", req.Date)
+	if err != nil {
+		return wc.ErrorResponse(c, fiber.StatusBadRequest, "Invalid date format. Use YYYY-MM-DD")
+	}
+
+	clock, err := time.Parse("15:04", req.Time)
+	if err != nil {
+		return wc.ErrorResponse(c, fiber.StatusBadRequest, "Invalid time format. Use HH:MM")
+	}
+
+	start := utils.CombineDateTime(date, clock)
+	if start.Before(time.Now()) {
+		return wc.ErrorResponse(c, fiber.StatusBadRequest, "Cannot join the waitlist for a time in the past")
+	}
+
+	if req.PartySize <= 0 {
+		return wc.ValidationErrorResponse(c, "Party size must be at least 1")
+	}
+
+	// Waitlisted customers are bound by the booking policy as if they booked directly
+	policy, err := wc.policyService.GetPolicy(config.DB)
+	if err != nil {
+		return wc.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to fetch booking policy")
+	}
+	if err := wc.policyService.CheckTiming(policy, start); err != nil {
+		return wc.DomainErrorResponse(c, err)
+	}
+	if _, err := wc.policyService.CheckCustomer(config.DB, policy, userID.(uint)); err != nil {
+		return wc.DomainErrorResponse(c, err)
+	}
+
+	// Check if the restaurant is open at the requested time
+	end := start.Add(time.Duration(config.GetDefaultReservationDuration()) * time.Minute)
+	if err := wc.reservationService.ValidateOpeningHours(config.DB, start, end); err != nil {
+		return wc.DomainErrorResponse(c, err)
+	}
+
+	// Check if user is already waiting for this date and time
+	var existing int64
+	config.DB.Model(&models.WaitlistEntry{}).
+		Where("user_id = ? AND date = ? AND time = ? AND status IN ?", userID.(uint), date, clock.Format("15:04:05"), []models.WaitlistStatus{
+			models.WaitlistStatusWaiting,
+			models.WaitlistStatusOffered,
+		}).Count(&existing)
+
+	if existing > 0 {
+		return wc.ErrorResponse(c, fiber.StatusConflict, "You are already on the waitlist for this date and time")
+	}
+
+	entry := models.WaitlistEntry{
+		UserID:    userID.(uint),
+		Date:      date,
+		Time:      clock,
+		PartySize: req.PartySize,
+		Status:    models.WaitlistStatusWaiting,
+	}
+
+	if err := config.DB.Create(&entry).Error; err != nil {
+		return wc.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to join waitlist")
+	}
+
+	return wc.SuccessResponse(c, entry, "Joined waitlist successfully")
+}
+
+// GetUserWaitlist gets waitlist entries of the current user (customer only)
+// Offers that were not claimed in time are released by the expire_waitlist_offers job
+func (wc *WaitlistController) GetUserWaitlist(c *fiber.Ctx) error {
+	userID := c.Locals("user_id")
+	if userID == nil {
+		return wc.ErrorResponse(c, fiber.StatusUnauthorized, "User not authenticated")
+	}
+
+	var entries []models.WaitlistEntry
+	query := config.DB.Where("user_id = ?", userID.(uint)).Preload("Reservation.Table")
+
+	// Filter by status if provided
+	status := c.Query("status")
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	if err := query.Order("date DESC, time DESC").Find(&entries).Error; err != nil {
+		return wc.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to fetch waitlist")
+	}
+
+	return wc.SuccessResponse(c, entries, "Waitlist retrieved successfully")
+}
+
+// ClaimWaitlistOffer accepts the table offered to a waitlisted customer and confirms the reservation held for it (customer only)
+func (wc *WaitlistController) ClaimWaitlistOffer(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return wc.ErrorResponse(c, fiber.StatusBadRequest, "Invalid waitlist entry ID")
+	}
+
+	userID := c.Locals("user_id")
+	if userID == nil {
+		return wc.ErrorResponse(c, fiber.StatusUnauthorized, "User not authenticated")
+	}
+
+	var entry models.WaitlistEntry
+	if err := config.DB.Preload("Reservation.Tables").
+		Where("id = ? AND user_id = ?", id, userID.(uint)).First(&entry).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return wc.ErrorResponse(c, fiber.StatusNotFound, "Waitlist entry not found")
+		}
+		return wc.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to fetch waitlist entry")
+	}
+
+	// Get locks of the held tables before confirming the reservation
+	if entry.Reservation != nil {
+		unlockTables := services.SharedTableLocks().Lock(entry.Reservation.HeldTableIDs())
+		defer unlockTables()
+	}
+
+	// Use database transaction to ensure atomicity
+	tx := config.DB.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	// Reload entry within transaction with lock
+	if err := tx.Set("gorm:query_option", "FOR UPDATE").First(&entry, entry.ID).Error; err != nil {
+		tx.Rollback()
+		return wc.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to fetch waitlist entry")
+	}
+
+	if entry.Status != models.WaitlistStatusOffered || entry.ReservationID == nil ||
+		entry.OfferExpiresAt == nil || entry.OfferExpiresAt.Before(time.Now()) {
+		tx.Rollback()
+		return wc.ErrorResponse(c, fiber.StatusConflict, "No open offer to claim for this waitlist entry")
+	}
+
+	var reservation models.Reservation
+	if err := tx.Set("gorm:query_option", "FOR UPDATE").Preload("Tables").First(&reservation, *entry.ReservationID).Error; err != nil {
+		tx.Rollback()
+		return wc.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to fetch reservation")
+	}
+
+	// Confirm the held reservation so it is not cancelled as unconfirmed; staff may have confirmed it already
+	if reservation.Status != models.ReservationStatusConfirmed {
+		if err := wc.reservationService.TransitionStatus(tx, &reservation, models.ReservationStatusConfirmed, userID.(uint), "Waitlist offer claimed"); err != nil {
+			tx.Rollback()
+			return wc.DomainErrorResponse(c, err)
+		}
+		if err := tx.Save(&reservation).Error; err != nil {
+			tx.Rollback()
+			return wc.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to claim offer")
+		}
+		if err := wc.notificationService.QueueReservationStatusUpdated(tx, &reservation); err != nil {
+			tx.Rollback()
+			return wc.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to claim offer")
+		}
+		if err := wc.floorEventService.RecordReservationStatus(tx, &reservation); err != nil {
+			tx.Rollback()
+			return wc.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to claim offer")
+		}
+	}
+
+	entry.Status = models.WaitlistStatusClaimed
+	if err := tx.Save(&entry).Error; err != nil {
+		tx.Rollback()
+		return wc.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to claim offer")
+	}
+
+	// Commit transaction
+	if err := tx.Commit().Error; err != nil {
+		return wc.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to commit claim")
+	}
+
+	// Load relationships for response (outside transaction)
+	config.DB.Preload("Reservation.Table").Preload("Reservation.Tables").First(&entry, entry.ID)
+
+	return wc.SuccessResponse(c, entry, "Offer claimed successfully")
+}
+
+// LeaveWaitlist removes the current user from the waitlist, releasing any table held for them (customer only)
+func (wc *WaitlistController) LeaveWaitlist(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return wc.ErrorResponse(c, fiber.StatusBadRequest, "Invalid waitlist entry ID")
+	}
+
+	userID := c.Locals("user_id")
+	if userID == nil {
+		return wc.ErrorResponse(c, fiber.StatusUnauthorized, "User not authenticated")
+	}
+
+	var entry models.WaitlistEntry
+	if err := config.DB.Where("id = ? AND user_id = ?", id, userID.(uint)).First(&entry).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return wc.ErrorResponse(c, fiber.StatusNotFound, "Waitlist entry not found")
+		}
+		return wc.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to fetch waitlist entry")
+	}
+
+	if !entry.IsOpen() {
+		return wc.ErrorResponse(c, fiber.StatusBadRequest, "Waitlist entry is already closed")
+	}
+
+	if err := wc.waitlistService.CloseOffer(entry.ID, models.WaitlistStatusCancelled, userID.(uint)); err != nil {
+		return wc.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to leave waitlist")
+	}
+
+	return wc.SuccessResponse(c, nil, "Left waitlist successfully")
+}
+
+// GetAllWaitlistEntries gets all waitlist entries (admin only)
+func (wc *WaitlistController) GetAllWaitlistEntries(c *fiber.Ctx) error {
+	var entries []models.WaitlistEntry
+	query := config.DB.Preload("User").Preload("Reservation.Table")
+
+	// Filter by status if provided
+	status := c.Query("status")
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	// Filter by date if provided
+	date := c.Query("date")
+	if date != "" {
+		query = query.Where("date = ?", date)
+	}
+
+	if err := query.Order("date ASC, time ASC, created_at ASC").Find(&entries).Error; err != nil {
+		return wc.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to fetch waitlist")
+	}
+
+	return wc.SuccessResponse(c, entries, "Waitlist retrieved successfully")
+}
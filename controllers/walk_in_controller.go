@@ -0,0 +1,330 @@
+package controllers
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"restaurant-booking-backend/config"
+	"restaurant-booking-backend/models"
+	"restaurant-booking-backend/services"
+	"restaurant-booking-backend/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// WalkInController walk-in queue controller
+type WalkInController struct {
+	BaseController
+	reservationService *services.ReservationService
+	floorEventService  *services.FloorEventService
+	// tableLocks serializes bookings of the same tables to prevent concurrent reservations
+	tableLocks *services.TableLocks
+}
+
+// NewWalkInController creates a new walk-in queue controller
+func NewWalkInController() *WalkInController {
+	return &WalkInController{
+		reservationService: &services.ReservationService{},
+		floorEventService:  &services.FloorEventService{},
+		tableLocks:         services.SharedTableLocks(),
+	}
+}
+
+// AddWalkInRequest add walk-in request structure
+type AddWalkInRequest struct {
+	Name       string `json:"name" binding:"required"`
+	Phone      string `json:"phone" binding:"required"`
+	PartySize  int    `json:"party_size" binding:"required"` // Number of guests
+	QuotedWait *int   `json:"quoted_wait"`                   // Optional wait quoted in minutes (defaults to the estimate)
+}
+
+// SeatWalkInRequest seat walk-in request structure
+type SeatWalkInRequest struct {
+	TableID            uint `json:"table_id"`             // Table to seat the party at (or table_combination_id)
+	TableCombinationID uint `json:"table_combination_id"` // Table combination to seat a larger party at
+	Duration           int  `json:"duration"`             // Optional duration in minutes (defaults to table/restaurant default)
+}
+
+// waitingPartySizes returns party sizes of waiting walk-ins created before the given time
+func waitingPartySizes(before time.Time) ([]int, error) {
+	var sizes []int
+	err := config.DB.Model(&models.WalkIn{}).
+		Where("status = ? AND created_at < ?", models.WalkInStatusWaiting, before).
+		Order("created_at ASC").Pluck("party_size", &sizes).Error
+	return sizes, err
+}
+
+// GetWalkIns gets the walk-in queue with current wait estimates (admin only)
+func (wc *WalkInController) GetWalkIns(c *fiber.Ctx) error {
+	var walkIns []models.WalkIn
+	query := config.DB.Preload("Reservation.Table")
+
+	// Filter by status (defaults to guests still waiting)
+	status := c.Query("status", string(models.WalkInStatusWaiting))
+	if status != "all" {
+		query = query.Where("status = ?", status)
+	}
+
+	if err := query.Order("created_at ASC").Find(&walkIns).Error; err != nil {
+		return wc.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to fetch walk-ins")
+	}
+
+	// Estimate the wait of each waiting party given the parties ahead of it
+	now := time.Now()
+	var ahead []int
+	for i := range walkIns {
+		if walkIns[i].Status != models.WalkInStatusWaiting {
+			continue
+		}
+		if estimate, err := wc.reservationService.EstimateWalkInWait(config.DB, walkIns[i].PartySize, ahead, now); err == nil {
+			walkIns[i].EstimatedWait = &estimate.Minutes
+		}
+		ahead = append(ahead, walkIns[i].PartySize)
+	}
+
+	return wc.SuccessResponse(c, walkIns, "Walk-ins retrieved successfully")
+}
+
+// GetWalkInWaitEstimate estimates the wait for a new walk-in party (admin only)
+func (wc *WalkInController) GetWalkInWaitEstimate(c *fiber.Ctx) error {
+	partySize, err := strconv.Atoi(c.Query("party_size"))
+	if err != nil || partySize <= 0 {
+		return wc.ErrorResponse(c, fiber.StatusBadRequest, "Invalid party_size")
+	}
+
+	now := time.Now()
+	ahead, err := waitingPartySizes(now)
+	if err != nil {
+		return wc.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to fetch walk-ins")
+	}
+
+	estimate, err := wc.reservationService.EstimateWalkInWait(config.DB, partySize, ahead, now)
+	if err != nil {
+		return wc.DomainErrorResponse(c, err)
+	}
+
+	return wc.SuccessResponse(c, estimate, "Wait estimate retrieved successfully")
+}
+
+// AddWalkIn adds a party to the walk-in queue (admin only)
+func (wc *WalkInController) AddWalkIn(c *fiber.Ctx) error {
+	var req AddWalkInRequest
+	if err := c.BodyParser(&req); err != nil {
+		return wc.ValidationErrorResponse(c, err.Error())
+	}
+
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		return wc.ErrorResponse(c, fiber.StatusBadRequest, "Name is required")
+	}
+
+	req.Phone = strings.TrimSpace(req.Phone)
+	if !utils.ValidatePhoneNumber(req.Phone) {
+		return wc.ErrorResponse(c, fiber.StatusBadRequest, "Invalid phone number format")
+	}
+
+	if req.PartySize <= 0 {
+		return wc.ValidationErrorResponse(c, "Party size must be at least 1")
+	}
+
+	// Quote the current estimate unless the host quoted a wait
+	now := time.Now()
+	ahead, err := waitingPartySizes(now)
+	if err != nil {
+		return wc.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to fetch walk-ins")
+	}
+	estimate, err := wc.reservationService.EstimateWalkInWait(config.DB, req.PartySize, ahead, now)
+	if err != nil {
+		return wc.DomainErrorResponse(c, err)
+	}
+
+	walkIn := models.WalkIn{
+		Name:          req.Name,
+		Phone:         req.Phone,
+		PartySize:     req.PartySize,
+		QuotedWait:    estimate.Minutes,
+		Status:        models.WalkInStatusWaiting,
+		EstimatedWait: &estimate.Minutes,
+	}
+	if req.QuotedWait != nil {
+		if *req.QuotedWait < 0 {
+			return wc.ValidationErrorResponse(c, "Quoted wait cannot be negative")
+		}
+		walkIn.QuotedWait = *req.QuotedWait
+	}
+
+	if err := config.DB.Create(&walkIn).Error; err != nil {
+		return wc.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to add walk-in")
+	}
+
+	return wc.SuccessResponse(c, walkIn, "Walk-in added successfully")
+}
+
+// SeatWalkIn seats a waiting walk-in party at a table by creating a seated reservation (admin only)
+// Uses mutex and database transaction to prevent concurrent reservation conflicts
+func (wc *WalkInController) SeatWalkIn(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return wc.ErrorResponse(c, fiber.StatusBadRequest, "Invalid walk-in ID")
+	}
+
+	var req SeatWalkInRequest
+	if err := c.BodyParser(&req); err != nil {
+		return wc.ValidationErrorResponse(c, err.Error())
+	}
+
+	if req.TableID == 0 && req.TableCombinationID == 0 {
+		return wc.ValidationErrorResponse(c, "table_id or table_combination_id is required")
+	}
+
+	// Validate requested duration
+	if req.Duration < 0 || req.Duration > config.GetMaxReservationDuration() {
+		return wc.ErrorResponse(c, fiber.StatusBadRequest, "Invalid reservation duration")
+	}
+
+	var walkIn models.WalkIn
+	if err := config.DB.First(&walkIn, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return wc.ErrorResponse(c, fiber.StatusNotFound, "Walk-in not found")
+		}
+		return wc.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to fetch walk-in")
+	}
+
+	if walkIn.Status != models.WalkInStatusWaiting {
+		return wc.ErrorResponse(c, fiber.StatusBadRequest, "Walk-in is no longer waiting")
+	}
+
+	// Get or create the guest user by phone
+	var user models.User
+	if err := config.DB.Where("phone = ?", walkIn.Phone).First(&user).Error; err != nil {
+		if err != gorm.ErrRecordNotFound {
+			return wc.ErrorResponse(c, fiber.StatusInternalServerError, "Database error while searching for user")
+		}
+		// User doesn't exist, create new user without password
+		user = models.User{
+			Phone:    walkIn.Phone,
+			Password: "", // Empty password - user must set password to login
+			Name:     walkIn.Name,
+			Role:     models.RoleCustomer,
+		}
+		if err := config.DB.Create(&user).Error; err != nil {
+			return wc.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to create user")
+		}
+	}
+
+	// Get locks of every table the party takes to prevent concurrent reservations for the same tables
+	tableIDs, err := wc.reservationService.TargetTableIDs(config.DB, req.TableID, req.TableCombinationID)
+	if err != nil {
+		return wc.DomainErrorResponse(c, err)
+	}
+	unlockTables := wc.tableLocks.Lock(tableIDs)
+	defer unlockTables()
+
+	// Use database transaction to ensure atomicity
+	tx := config.DB.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	// Reload walk-in within transaction with lock
+	if err := tx.Set("gorm:query_option", "FOR UPDATE").First(&walkIn, id).Error; err != nil {
+		tx.Rollback()
+		return wc.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to fetch walk-in")
+	}
+
+	// Double-check status within transaction
+	if walkIn.Status != models.WalkInStatusWaiting {
+		tx.Rollback()
+		return wc.ErrorResponse(c, fiber.StatusBadRequest, "Walk-in is no longer waiting")
+	}
+
+	// Load the table or table combination and lock the rows (SELECT FOR UPDATE)
+	target, err := wc.reservationService.LoadBookingTarget(tx, req.TableID, req.TableCombinationID)
+	if err != nil {
+		tx.Rollback()
+		return wc.DomainErrorResponse(c, err)
+	}
+
+	// Check party size, opening hours and overlapping reservations from now on
+	now := time.Now()
+	date, clock := utils.SplitDateTime(now)
+	duration, err := wc.reservationService.ValidateBooking(tx, target, utils.CombineDateTime(date, clock), walkIn.PartySize, req.Duration, 0)
+	if err != nil {
+		tx.Rollback()
+		return wc.DomainErrorResponse(c, err)
+	}
+
+	// Create reservation for the seated party
+	reservation := models.Reservation{
+		UserID:    user.ID,
+		Date:      date,
+		Time:      clock,
+		Duration:  duration,
+		PartySize: walkIn.PartySize,
+		Status:    models.ReservationStatusSeated,
+		SeatedAt:  &now,
+	}
+	target.Apply(&reservation)
+
+	if err := tx.Create(&reservation).Error; err != nil {
+		tx.Rollback()
+		return wc.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to create reservation")
+	}
+	if err := wc.reservationService.RecordCreated(tx, &reservation, wc.CurrentUserID(c), "Walk-in seated"); err != nil {
+		tx.Rollback()
+		return wc.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to create reservation")
+	}
+	if err := wc.floorEventService.RecordReservation(tx, models.FloorEventReservationSeated, &reservation); err != nil {
+		tx.Rollback()
+		return wc.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to create reservation")
+	}
+
+	walkIn.Status = models.WalkInStatusSeated
+	walkIn.ReservationID = &reservation.ID
+	walkIn.SeatedAt = &now
+	if err := tx.Save(&walkIn).Error; err != nil {
+		tx.Rollback()
+		return wc.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to seat walk-in")
+	}
+
+	// Commit transaction
+	if err := tx.Commit().Error; err != nil {
+		return wc.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to commit seating")
+	}
+
+	// Load relationships for response (outside transaction)
+	config.DB.Preload("Reservation.Table").Preload("Reservation.Tables").First(&walkIn, walkIn.ID)
+
+	return wc.SuccessResponse(c, walkIn, "Walk-in seated successfully")
+}
+
+// RemoveWalkIn removes a waiting party from the walk-in queue (admin only)
+func (wc *WalkInController) RemoveWalkIn(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return wc.ErrorResponse(c, fiber.StatusBadRequest, "Invalid walk-in ID")
+	}
+
+	var walkIn models.WalkIn
+	if err := config.DB.First(&walkIn, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return wc.ErrorResponse(c, fiber.StatusNotFound, "Walk-in not found")
+		}
+		return wc.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to fetch walk-in")
+	}
+
+	if walkIn.Status != models.WalkInStatusWaiting {
+		return wc.ErrorResponse(c, fiber.StatusBadRequest, "Walk-in is no longer waiting")
+	}
+
+	walkIn.Status = models.WalkInStatusRemoved
+	if err := config.DB.Save(&walkIn).Error; err != nil {
+		return wc.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to remove walk-in")
+	}
+
+	return wc.SuccessResponse(c, walkIn, "Walk-in removed successfully")
+}
@@ -23,10 +23,14 @@ import (
 	_ "embed"
 	"log"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"restaurant-booking-backend/config"
 	"restaurant-booking-backend/models"
 	"restaurant-booking-backend/routes"
+	"restaurant-booking-backend/services"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
@@ -54,11 +58,70 @@ func main() {
 		&models.Category{},
 		&models.Order{},
 		&models.OrderItem{},
+		&models.OpeningHours{},
+		&models.CalendarException{},
+		&models.TableCombination{},
+		&models.WaitlistEntry{},
+		&models.WalkIn{},
+		&models.StatusHistory{},
+		&models.BookingPolicy{},
+		&models.ScheduledJob{},
+		&models.ReservationReminder{},
+		&models.NotificationPreference{},
+		&models.OutboxMessage{},
+		&models.FloorEvent{},
+		&models.NotificationTemplate{},
+		&models.Promotion{},
 	); err != nil {
 		log.Fatal("Failed to migrate database:", err)
 	}
 	log.Println("Database migration completed")
 
+	// Table occupancy is now derived from reservations; reset legacy stored reserved/occupied statuses
+	if err := config.DB.Model(&models.Table{}).
+		Where("status IN ?", []models.TableStatus{models.TableStatusReserved, models.TableStatusOccupied}).
+		Update("status", models.TableStatusAvailable).Error; err != nil {
+		log.Fatal("Failed to reset legacy table statuses:", err)
+	}
+
+	// Run reservation lifecycle jobs (completion, no-shows, expiry, reminders) in the background
+	scheduler := services.NewScheduler(15 * time.Second)
+	lifecycleService := &services.ReservationLifecycleService{}
+	for _, job := range lifecycleService.Jobs() {
+		scheduler.Register(job)
+	}
+	floorEventService := &services.FloorEventService{}
+	for _, job := range floorEventService.Jobs() {
+		scheduler.Register(job)
+	}
+	if err := scheduler.Start(); err != nil {
+		log.Fatal("Failed to start scheduler:", err)
+	}
+
+	// Deliver queued notifications and promotion batches in the background
+	outboxWorker := services.NewOutboxWorker(2 * time.Second)
+	notificationService := &services.NotificationService{}
+	for topic, handler := range notificationService.OutboxHandlers() {
+		outboxWorker.Register(topic, handler)
+	}
+	promotionService := &services.PromotionService{}
+	for topic, handler := range promotionService.OutboxHandlers() {
+		outboxWorker.Register(topic, handler)
+	}
+	outboxWorker.Start()
+
+	// Push new notifications, floor events and kitchen tickets to connected clients
+	notificationStream := services.SharedNotificationStream()
+	if err := notificationStream.Start(); err != nil {
+		log.Fatal("Failed to start notification stream:", err)
+	}
+	floorEventStream := services.SharedFloorEventStream()
+	if err := floorEventStream.Start(); err != nil {
+		log.Fatal("Failed to start floor event stream:", err)
+	}
+	kitchenStream := services.SharedKitchenStream()
+	kitchenStream.Start()
+
 	// Create Fiber app
 	app := fiber.New(fiber.Config{
 		ErrorHandler: func(c *fiber.Ctx, err error) error {
@@ -85,7 +148,7 @@ func main() {
 	app.Use(cors.New(cors.Config{
 		AllowOrigins:     "http://localhost:3000",
 		AllowCredentials: true,
-		AllowHeaders:     "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With",
+		AllowHeaders:     "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With, Last-Event-ID",
 		AllowMethods:     "POST, OPTIONS, GET, PUT, DELETE, PATCH",
 	}))
 
@@ -111,8 +174,27 @@ func main() {
 	}
 
 	// Start server
-	log.Printf("Server is running on port %s...", port)
-	if err := app.Listen(":" + port); err != nil {
-		log.Fatal("Failed to start server:", err)
+	go func() {
+		log.Printf("Server is running on port %s...", port)
+		if err := app.Listen(":" + port); err != nil {
+			log.Fatal("Failed to start server:", err)
+		}
+	}()
+
+	// Wait for an interrupt, then let in-flight requests and jobs finish
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, os.Interrupt, syscall.SIGTERM)
+	<-quit
+
+	log.Println("Shutting down server...")
+	// End open streams so the server can shut down
+	notificationStream.Stop(5 * time.Second)
+	floorEventStream.Stop(5 * time.Second)
+	kitchenStream.Stop(5 * time.Second)
+	if err := app.ShutdownWithTimeout(10 * time.Second); err != nil {
+		log.Printf("Failed to shut down server: %v", err)
 	}
+	scheduler.Stop(30 * time.Second)
+	outboxWorker.Stop(30 * time.Second)
+	log.Println("Server stopped")
 }
@@ -28,22 +28,47 @@ func AuthMiddleware() fiber.Handler {
 			})
 		}
 
-		token := parts[1]
+		return authenticate(c, parts[1])
+	}
+}
+
+// StreamAuthMiddleware JWT authentication middleware for event streams
+// Browsers cannot set headers on EventSource connections, so the token may also be passed in the
+// access_token query parameter
+func StreamAuthMiddleware() fiber.Handler {
+	header := AuthMiddleware()
+	return func(c *fiber.Ctx) error {
+		if c.Get("Authorization") != "" {
+			return header(c)
+		}
 
-		// Validate token
-		claims, err := utils.ValidateToken(token)
-		if err != nil {
+		token := c.Query("access_token")
+		if token == "" {
 			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
 				"success": false,
-				"message": "Invalid or expired token",
+				"message": "Authorization header or access_token is required",
 			})
 		}
 
-		// Set user information in locals
-		c.Locals("user_id", claims.UserID)
-		c.Locals("user_phone", claims.Phone)
-		c.Locals("user_role", claims.Role)
+		return authenticate(c, token)
+	}
+}
 
-		return c.Next()
+// authenticate validates a token and continues as its user
+func authenticate(c *fiber.Ctx, token string) error {
+	// Validate token
+	claims, err := utils.ValidateToken(token)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid or expired token",
+		})
 	}
+
+	// Set user information in locals
+	c.Locals("user_id", claims.UserID)
+	c.Locals("user_phone", claims.Phone)
+	c.Locals("user_role", claims.Role)
+
+	return c.Next()
 }
@@ -0,0 +1,20 @@
+package models
+
+// NoShowPolicyAction what happens to bookings of customers over the no-show threshold
+type NoShowPolicyAction string
+
+const (
+	NoShowPolicyBlock   NoShowPolicyAction = "block"   // Customer cannot book online
+	NoShowPolicyDeposit NoShowPolicyAction = "deposit" // Customer must pay a deposit to book
+)
+
+// BookingPolicy customer booking rules (single row, edited by admins; zero values disable a rule)
+type BookingPolicy struct {
+	BaseModel
+	MaxActiveReservations int                `gorm:"not null;default:0" json:"max_active_reservations"`      // Upcoming reservations a customer may hold at once
+	MaxAdvanceDays        int                `gorm:"not null;default:0" json:"max_advance_days"`             // How many days ahead customers may book
+	MinLeadTime           int                `gorm:"not null;default:0" json:"min_lead_time"`                // Minutes between booking and reservation start
+	NoShowThreshold       int                `gorm:"not null;default:0" json:"no_show_threshold"`            // No-shows at which the no-show action applies
+	NoShowAction          NoShowPolicyAction `gorm:"type:varchar(20);default:'block'" json:"no_show_action"` // "block" or "deposit"
+	DepositAmount         float64            `gorm:"not null;default:0" json:"deposit_amount"`               // Deposit required by the "deposit" action
+}
@@ -0,0 +1,52 @@
+package models
+
+import (
+	"strconv"
+	"strings"
+)
+
+// FloorEventType floor event type
+type FloorEventType string
+
+const (
+	FloorEventTableCreated             FloorEventType = "table.created"
+	FloorEventTableUpdated             FloorEventType = "table.updated"
+	FloorEventTableStatusChanged       FloorEventType = "table.status_changed" // Manual service flag (available/maintenance) changed
+	FloorEventTableDeleted             FloorEventType = "table.deleted"
+	FloorEventReservationCreated       FloorEventType = "reservation.created"
+	FloorEventReservationModified      FloorEventType = "reservation.modified"
+	FloorEventReservationCancelled     FloorEventType = "reservation.cancelled"
+	FloorEventReservationSeated        FloorEventType = "reservation.seated"
+	FloorEventReservationStatusChanged FloorEventType = "reservation.status_changed" // Any other status change
+)
+
+// FloorEvent change on the restaurant floor pushed to host clients
+// Stored in the same transaction as the change, so hosts only see committed changes and can catch up after reconnecting
+type FloorEvent struct {
+	BaseModel
+	Type          FloorEventType `gorm:"type:varchar(50);not null" json:"type"`
+	ReservationID *uint          `gorm:"index" json:"reservation_id"`
+	TableIDs      string         `gorm:"type:varchar(255)" json:"-"` // Comma separated IDs of the affected tables
+	Data          string         `gorm:"type:text" json:"-"`         // JSON snapshot of the table or reservation after the change
+}
+
+// TableIDList returns the IDs of the affected tables
+func (e *FloorEvent) TableIDList() []uint {
+	ids := []uint{}
+	for _, value := range strings.Split(e.TableIDs, ",") {
+		id, err := strconv.ParseUint(value, 10, 64)
+		if err == nil {
+			ids = append(ids, uint(id))
+		}
+	}
+	return ids
+}
+
+// SetTableIDs sets the IDs of the affected tables
+func (e *FloorEvent) SetTableIDs(ids []uint) {
+	values := make([]string, 0, len(ids))
+	for _, id := range ids {
+		values = append(values, strconv.FormatUint(uint64(id), 10))
+	}
+	e.TableIDs = strings.Join(values, ",")
+}
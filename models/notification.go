@@ -9,6 +9,23 @@ const (
 	NotificationTypePromotion   NotificationType = "promotion"
 )
 
+// NotificationTypes lists every notification type
+var NotificationTypes = []NotificationType{
+	NotificationTypeReservation,
+	NotificationTypeSystem,
+	NotificationTypePromotion,
+}
+
+// IsValid checks if the type is a known notification type
+func (t NotificationType) IsValid() bool {
+	for _, notificationType := range NotificationTypes {
+		if t == notificationType {
+			return true
+		}
+	}
+	return false
+}
+
 // Notification notification model
 type Notification struct {
 	BaseModel
@@ -0,0 +1,61 @@
+package models
+
+import "strings"
+
+// NotificationChannel channel notifications are delivered over besides the in-app inbox
+type NotificationChannel string
+
+const (
+	NotificationChannelSMS     NotificationChannel = "sms"
+	NotificationChannelEmail   NotificationChannel = "email"
+	NotificationChannelWebhook NotificationChannel = "webhook"
+)
+
+// NotificationChannels lists every notification channel
+var NotificationChannels = []NotificationChannel{
+	NotificationChannelSMS,
+	NotificationChannelEmail,
+	NotificationChannelWebhook,
+}
+
+// DefaultNotificationChannels channels used when the user has no preference for a notification type
+// Users sign up by phone, so SMS reaches everyone
+var DefaultNotificationChannels = []NotificationChannel{NotificationChannelSMS}
+
+// IsValid checks if the channel is a known notification channel
+func (c NotificationChannel) IsValid() bool {
+	for _, channel := range NotificationChannels {
+		if c == channel {
+			return true
+		}
+	}
+	return false
+}
+
+// NotificationPreference channels a user receives one type of notifications over
+type NotificationPreference struct {
+	BaseModel
+	UserID   uint             `gorm:"not null;uniqueIndex:idx_notification_preference" json:"user_id"`
+	Type     NotificationType `gorm:"type:varchar(50);not null;uniqueIndex:idx_notification_preference" json:"type"`
+	Channels string           `gorm:"type:varchar(255);not null" json:"-"` // Comma separated, empty when opted out
+}
+
+// ChannelList returns the channels of the preference
+func (p *NotificationPreference) ChannelList() []NotificationChannel {
+	channels := []NotificationChannel{}
+	for _, channel := range strings.Split(p.Channels, ",") {
+		if channel != "" {
+			channels = append(channels, NotificationChannel(channel))
+		}
+	}
+	return channels
+}
+
+// SetChannels sets the channels of the preference
+func (p *NotificationPreference) SetChannels(channels []NotificationChannel) {
+	names := make([]string, len(channels))
+	for i, channel := range channels {
+		names[i] = string(channel)
+	}
+	p.Channels = strings.Join(names, ",")
+}
@@ -0,0 +1,120 @@
+package models
+
+// Language language notifications are written in
+type Language string
+
+const (
+	LanguageEnglish Language = "en"
+	LanguagePersian Language = "fa"
+)
+
+// Languages lists every supported language
+var Languages = []Language{
+	LanguageEnglish,
+	LanguagePersian,
+}
+
+// IsValid checks if the language is a supported language
+func (l Language) IsValid() bool {
+	for _, language := range Languages {
+		if l == language {
+			return true
+		}
+	}
+	return false
+}
+
+// Calendar calendar dates are written in
+type Calendar string
+
+const (
+	CalendarGregorian Calendar = "gregorian"
+	CalendarJalali    Calendar = "jalali" // Solar Hijri calendar used in Iran
+)
+
+// Calendars lists every supported calendar
+var Calendars = []Calendar{
+	CalendarGregorian,
+	CalendarJalali,
+}
+
+// IsValid checks if the calendar is a supported calendar
+func (c Calendar) IsValid() bool {
+	for _, calendar := range Calendars {
+		if c == calendar {
+			return true
+		}
+	}
+	return false
+}
+
+// NotificationEvent event a notification message is written for
+type NotificationEvent string
+
+const (
+	NotificationEventReservationCreated        NotificationEvent = "reservation_created"
+	NotificationEventReservationCreatedAdmin   NotificationEvent = "reservation_created_admin"
+	NotificationEventReservationCancelled      NotificationEvent = "reservation_cancelled"
+	NotificationEventReservationCancelledAdmin NotificationEvent = "reservation_cancelled_admin"
+	NotificationEventReservationStatusUpdated  NotificationEvent = "reservation_status_updated"
+	NotificationEventReservationModified       NotificationEvent = "reservation_modified"
+	NotificationEventReservationModifiedAdmin  NotificationEvent = "reservation_modified_admin"
+	NotificationEventReservationReminder       NotificationEvent = "reservation_reminder"
+	NotificationEventWaitlistOffer             NotificationEvent = "waitlist_offer"
+	NotificationEventWaitlistOfferExpired      NotificationEvent = "waitlist_offer_expired"
+)
+
+// NotificationEvents lists every notification event
+var NotificationEvents = []NotificationEvent{
+	NotificationEventReservationCreated,
+	NotificationEventReservationCreatedAdmin,
+	NotificationEventReservationCancelled,
+	NotificationEventReservationCancelledAdmin,
+	NotificationEventReservationStatusUpdated,
+	NotificationEventReservationModified,
+	NotificationEventReservationModifiedAdmin,
+	NotificationEventReservationReminder,
+	NotificationEventWaitlistOffer,
+	NotificationEventWaitlistOfferExpired,
+}
+
+// IsValid checks if the event is a known notification event
+func (e NotificationEvent) IsValid() bool {
+	for _, event := range NotificationEvents {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// notificationEventTypes notification type of the messages of each event, which decides the channels users get them on
+var notificationEventTypes = map[NotificationEvent]NotificationType{
+	NotificationEventReservationCreated:        NotificationTypeReservation,
+	NotificationEventReservationCreatedAdmin:   NotificationTypeReservation,
+	NotificationEventReservationCancelled:      NotificationTypeReservation,
+	NotificationEventReservationCancelledAdmin: NotificationTypeReservation,
+	NotificationEventReservationStatusUpdated:  NotificationTypeReservation,
+	NotificationEventReservationModified:       NotificationTypeReservation,
+	NotificationEventReservationModifiedAdmin:  NotificationTypeReservation,
+	NotificationEventReservationReminder:       NotificationTypeReservation,
+	NotificationEventWaitlistOffer:             NotificationTypeReservation,
+	NotificationEventWaitlistOfferExpired:      NotificationTypeReservation,
+}
+
+// Type returns the notification type the messages of the event are sent as (system for unknown events)
+func (e NotificationEvent) Type() NotificationType {
+	if notificationType, ok := notificationEventTypes[e]; ok {
+		return notificationType
+	}
+	return NotificationTypeSystem
+}
+
+// NotificationTemplate admin edited message of a notification event in one language
+// Events without one use the built-in template of the language
+type NotificationTemplate struct {
+	BaseModel
+	Event    NotificationEvent `gorm:"type:varchar(50);not null;uniqueIndex:idx_notification_template" json:"event"`
+	Language Language          `gorm:"type:varchar(10);not null;uniqueIndex:idx_notification_template" json:"language"`
+	Body     string            `gorm:"type:text;not null" json:"body"` // text/template source
+}
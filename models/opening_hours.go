@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+// OpeningHours weekly opening hours (one row per service, e.g. lunch and dinner)
+type OpeningHours struct {
+	BaseModel
+	Weekday   int    `gorm:"not null;index" json:"weekday"`              // 0 = Sunday ... 6 = Saturday
+	Name      string `gorm:"type:varchar(50)" json:"name"`               // Service name (e.g., "lunch", "dinner")
+	OpenTime  string `gorm:"type:varchar(5);not null" json:"open_time"`  // Format: "15:04"
+	CloseTime string `gorm:"type:varchar(5);not null" json:"close_time"` // Format: "15:04" (earlier than open_time = closes after midnight)
+}
+
+// CalendarExceptionType calendar exception type
+type CalendarExceptionType string
+
+const (
+	CalendarExceptionClosed       CalendarExceptionType = "closed"        // Holiday closure
+	CalendarExceptionSpecialHours CalendarExceptionType = "special_hours" // Replaces weekly hours for the date
+)
+
+// CalendarException holiday closure or special opening hours for a specific date
+type CalendarException struct {
+	BaseModel
+	Date      time.Time             `gorm:"type:date;not null;index" json:"date"`
+	Type      CalendarExceptionType `gorm:"type:varchar(20);not null" json:"type"`
+	Reason    string                `gorm:"type:varchar(255)" json:"reason"`   // Optional (e.g., "Nowruz")
+	OpenTime  string                `gorm:"type:varchar(5)" json:"open_time"`  // Format: "15:04" (special_hours only)
+	CloseTime string                `gorm:"type:varchar(5)" json:"close_time"` // Format: "15:04" (special_hours only)
+}
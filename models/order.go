@@ -1,5 +1,7 @@
 package models
 
+import "time"
+
 // OrderStatus order status type
 type OrderStatus string
 
@@ -12,6 +14,78 @@ const (
 	OrderStatusCancelled OrderStatus = "cancelled" // Cancelled
 )
 
+// OrderStatuses every order status in lifecycle order
+var OrderStatuses = []OrderStatus{
+	OrderStatusPending,
+	OrderStatusConfirmed,
+	OrderStatusPreparing,
+	OrderStatusReady,
+	OrderStatusDelivered,
+	OrderStatusCancelled,
+}
+
+// orderStatusTransitions statuses each status may move to; orders can only be cancelled before preparation starts
+var orderStatusTransitions = map[OrderStatus][]OrderStatus{
+	OrderStatusPending:   {OrderStatusConfirmed, OrderStatusCancelled},
+	OrderStatusConfirmed: {OrderStatusPreparing, OrderStatusCancelled},
+	OrderStatusPreparing: {OrderStatusReady},
+	OrderStatusReady:     {OrderStatusDelivered},
+}
+
+// KitchenOrderStatuses statuses of orders shown on the kitchen display
+var KitchenOrderStatuses = []OrderStatus{
+	OrderStatusConfirmed,
+	OrderStatusPreparing,
+}
+
+// orderStatusBumps status the kitchen moves an order to when it bumps its ticket
+var orderStatusBumps = map[OrderStatus]OrderStatus{
+	OrderStatusConfirmed: OrderStatusPreparing,
+	OrderStatusPreparing: OrderStatusReady,
+}
+
+// orderStatusRecalls status the kitchen moves an order back to when it recalls a bumped ticket
+var orderStatusRecalls = map[OrderStatus]OrderStatus{
+	OrderStatusReady: OrderStatusPreparing,
+}
+
+// IsValid checks if the status is a known order status
+func (s OrderStatus) IsValid() bool {
+	for _, status := range OrderStatuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// Transitions returns the statuses an order in this status may move to
+func (s OrderStatus) Transitions() []OrderStatus {
+	return orderStatusTransitions[s]
+}
+
+// CanTransitionTo checks if an order in this status may move to next
+func (s OrderStatus) CanTransitionTo(next OrderStatus) bool {
+	for _, status := range orderStatusTransitions[s] {
+		if next == status {
+			return true
+		}
+	}
+	return false
+}
+
+// BumpStatus returns the status a kitchen bump moves an order in this status to
+func (s OrderStatus) BumpStatus() (OrderStatus, bool) {
+	next, ok := orderStatusBumps[s]
+	return next, ok
+}
+
+// RecallStatus returns the status a kitchen recall moves an order in this status back to
+func (s OrderStatus) RecallStatus() (OrderStatus, bool) {
+	previous, ok := orderStatusRecalls[s]
+	return previous, ok
+}
+
 // Order order model
 type Order struct {
 	BaseModel
@@ -19,11 +93,55 @@ type Order struct {
 	Status     OrderStatus `gorm:"type:varchar(20);default:'pending'" json:"status"`
 	TotalPrice float64     `gorm:"not null" json:"total_price"` // Total price of all items
 
+	// Status transition timestamps and the admins who made them (used to measure kitchen times)
+	ConfirmedAt   *time.Time `json:"confirmed_at,omitempty"`
+	ConfirmedByID *uint      `json:"confirmed_by_id,omitempty"`
+	PreparingAt   *time.Time `json:"preparing_at,omitempty"`
+	PreparingByID *uint      `json:"preparing_by_id,omitempty"`
+	ReadyAt       *time.Time `json:"ready_at,omitempty"`
+	ReadyByID     *uint      `json:"ready_by_id,omitempty"`
+	DeliveredAt   *time.Time `json:"delivered_at,omitempty"`
+	DeliveredByID *uint      `json:"delivered_by_id,omitempty"`
+	CancelledAt   *time.Time `json:"cancelled_at,omitempty"`
+	CancelledByID *uint      `json:"cancelled_by_id,omitempty"`
+
 	// Relationships
 	User       User        `gorm:"foreignKey:UserID" json:"user,omitempty"`
 	OrderItems []OrderItem `gorm:"foreignKey:OrderID" json:"order_items,omitempty"`
 }
 
+// RecordTransition stores when and by whom the order entered the given status
+func (o *Order) RecordTransition(status OrderStatus, actorID uint, at time.Time) {
+	switch status {
+	case OrderStatusConfirmed:
+		o.ConfirmedAt, o.ConfirmedByID = &at, &actorID
+	case OrderStatusPreparing:
+		o.PreparingAt, o.PreparingByID = &at, &actorID
+	case OrderStatusReady:
+		o.ReadyAt, o.ReadyByID = &at, &actorID
+	case OrderStatusDelivered:
+		o.DeliveredAt, o.DeliveredByID = &at, &actorID
+	case OrderStatusCancelled:
+		o.CancelledAt, o.CancelledByID = &at, &actorID
+	}
+}
+
+// ClearTransition removes when and by whom the order entered the given status (used when the kitchen recalls it)
+func (o *Order) ClearTransition(status OrderStatus) {
+	switch status {
+	case OrderStatusConfirmed:
+		o.ConfirmedAt, o.ConfirmedByID = nil, nil
+	case OrderStatusPreparing:
+		o.PreparingAt, o.PreparingByID = nil, nil
+	case OrderStatusReady:
+		o.ReadyAt, o.ReadyByID = nil, nil
+	case OrderStatusDelivered:
+		o.DeliveredAt, o.DeliveredByID = nil, nil
+	case OrderStatusCancelled:
+		o.CancelledAt, o.CancelledByID = nil, nil
+	}
+}
+
 // OrderItem order item model (many-to-many relationship between Order and MenuItem)
 type OrderItem struct {
 	BaseModel
@@ -0,0 +1,42 @@
+package models
+
+import "time"
+
+// OutboxStatus outbox message status type
+type OutboxStatus string
+
+const (
+	OutboxStatusPending   OutboxStatus = "pending"   // Waiting for (another) delivery attempt
+	OutboxStatusDelivered OutboxStatus = "delivered" // Handled successfully
+	OutboxStatusDead      OutboxStatus = "dead"      // Gave up after the maximum number of attempts
+)
+
+// OutboxStatuses lists every outbox message status
+var OutboxStatuses = []OutboxStatus{
+	OutboxStatusPending,
+	OutboxStatusDelivered,
+	OutboxStatusDead,
+}
+
+// IsValid checks if the status is a known outbox message status
+func (s OutboxStatus) IsValid() bool {
+	for _, status := range OutboxStatuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// OutboxMessage side effect (e.g. a notification) stored in the same transaction as the change causing it
+// and handled by the outbox worker afterwards, so it is not lost when sending fails or the process stops
+type OutboxMessage struct {
+	BaseModel
+	Topic         string       `gorm:"type:varchar(100);not null;index" json:"topic"`
+	Payload       string       `gorm:"type:text;not null" json:"payload"` // JSON encoded
+	Status        OutboxStatus `gorm:"type:varchar(20);not null;default:'pending';index:idx_outbox_due" json:"status"`
+	Attempts      int          `gorm:"not null;default:0" json:"attempts"`
+	NextAttemptAt time.Time    `gorm:"not null;index:idx_outbox_due" json:"next_attempt_at"`
+	LastError     string       `gorm:"type:text" json:"last_error,omitempty"`
+	DeliveredAt   *time.Time   `json:"delivered_at,omitempty"`
+}
@@ -0,0 +1,92 @@
+package models
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// PromotionAudience users a promotion is sent to
+type PromotionAudience string
+
+const (
+	PromotionAudienceAll                PromotionAudience = "all"                 // Every customer
+	PromotionAudienceRecentReservations PromotionAudience = "recent_reservations" // Customers with a reservation in the last AudienceDays days
+	PromotionAudienceMenuItem           PromotionAudience = "menu_item"           // Customers who ordered MenuItemID
+	PromotionAudienceUsers              PromotionAudience = "users"               // Explicit list of users
+)
+
+// PromotionAudiences lists every promotion audience
+var PromotionAudiences = []PromotionAudience{
+	PromotionAudienceAll,
+	PromotionAudienceRecentReservations,
+	PromotionAudienceMenuItem,
+	PromotionAudienceUsers,
+}
+
+// IsValid checks if the audience is a known promotion audience
+func (a PromotionAudience) IsValid() bool {
+	for _, audience := range PromotionAudiences {
+		if a == audience {
+			return true
+		}
+	}
+	return false
+}
+
+// PromotionStatus promotion delivery status
+type PromotionStatus string
+
+const (
+	PromotionStatusQueued    PromotionStatus = "queued"  // Waiting for the first batch
+	PromotionStatusSending   PromotionStatus = "sending" // Batches are being sent
+	PromotionStatusCompleted PromotionStatus = "completed"
+	PromotionStatusCancelled PromotionStatus = "cancelled" // Stopped by an admin; remaining recipients are not sent to
+)
+
+// Promotion promotional message broadcast to an audience of users in batches
+type Promotion struct {
+	BaseModel
+	Message      string            `gorm:"type:text;not null" json:"message"`
+	Audience     PromotionAudience `gorm:"type:varchar(30);not null" json:"audience"`
+	AudienceDays int               `gorm:"not null;default:0" json:"audience_days,omitempty"` // Look-back window of the recent_reservations audience
+	MenuItemID   *uint             `json:"menu_item_id,omitempty"`                            // Menu item of the menu_item audience
+	UserIDs      string            `gorm:"type:text" json:"-"`                                // Comma separated IDs of the users audience
+	Status       PromotionStatus   `gorm:"type:varchar(20);not null;default:'queued';index" json:"status"`
+	CreatedByID  uint              `gorm:"not null" json:"created_by_id"`
+
+	// Progress
+	TotalRecipients int        `gorm:"not null;default:0" json:"total_recipients"` // Users in the audience when the promotion was created
+	SentCount       int        `gorm:"not null;default:0" json:"sent_count"`
+	SkippedCount    int        `gorm:"not null;default:0" json:"skipped_count"` // Users who opted out of promotions
+	LastUserID      uint       `gorm:"not null;default:0" json:"-"`             // Recipients are sent to in ID order; last one handled
+	MaxUserID       uint       `gorm:"not null;default:0" json:"-"`             // Users created after the promotion are left out
+	StartedAt       *time.Time `json:"started_at,omitempty"`
+	CompletedAt     *time.Time `json:"completed_at,omitempty"`
+}
+
+// UserIDList returns the IDs of the users audience
+func (p *Promotion) UserIDList() []uint {
+	ids := []uint{}
+	for _, value := range strings.Split(p.UserIDs, ",") {
+		id, err := strconv.ParseUint(value, 10, 64)
+		if err == nil {
+			ids = append(ids, uint(id))
+		}
+	}
+	return ids
+}
+
+// SetUserIDs sets the IDs of the users audience
+func (p *Promotion) SetUserIDs(ids []uint) {
+	values := make([]string, 0, len(ids))
+	for _, id := range ids {
+		values = append(values, strconv.FormatUint(uint64(id), 10))
+	}
+	p.UserIDs = strings.Join(values, ",")
+}
+
+// IsActive checks if the promotion still has batches to send
+func (p *Promotion) IsActive() bool {
+	return p.Status == PromotionStatusQueued || p.Status == PromotionStatusSending
+}
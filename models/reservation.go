@@ -1,6 +1,10 @@
 package models
 
-import "time"
+import (
+	"time"
+
+	"restaurant-booking-backend/utils"
+)
 
 // ReservationStatus reservation status type
 type ReservationStatus string
@@ -8,21 +12,123 @@ type ReservationStatus string
 const (
 	ReservationStatusPending   ReservationStatus = "pending"
 	ReservationStatusConfirmed ReservationStatus = "confirmed"
+	ReservationStatusSeated    ReservationStatus = "seated" // Guests are at the table
 	ReservationStatusCancelled ReservationStatus = "cancelled"
 	ReservationStatusCompleted ReservationStatus = "completed"
+	ReservationStatusNoShow    ReservationStatus = "no_show" // Guests did not arrive
 )
 
+// ReservationStatuses every reservation status in lifecycle order
+var ReservationStatuses = []ReservationStatus{
+	ReservationStatusPending,
+	ReservationStatusConfirmed,
+	ReservationStatusSeated,
+	ReservationStatusCompleted,
+	ReservationStatusCancelled,
+	ReservationStatusNoShow,
+}
+
+// reservationStatusTransitions statuses each status may move to; completed, cancelled and no_show are final
+var reservationStatusTransitions = map[ReservationStatus][]ReservationStatus{
+	ReservationStatusPending:   {ReservationStatusConfirmed, ReservationStatusSeated, ReservationStatusCancelled, ReservationStatusNoShow},
+	ReservationStatusConfirmed: {ReservationStatusSeated, ReservationStatusCancelled, ReservationStatusNoShow},
+	ReservationStatusSeated:    {ReservationStatusCompleted},
+}
+
+// IsValid checks if the status is a known reservation status
+func (s ReservationStatus) IsValid() bool {
+	for _, status := range ReservationStatuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// Transitions returns the statuses a reservation in this status may move to
+func (s ReservationStatus) Transitions() []ReservationStatus {
+	return reservationStatusTransitions[s]
+}
+
+// CanTransitionTo checks if a reservation in this status may move to next
+func (s ReservationStatus) CanTransitionTo(next ReservationStatus) bool {
+	for _, status := range reservationStatusTransitions[s] {
+		if next == status {
+			return true
+		}
+	}
+	return false
+}
+
+// ActiveReservationStatuses statuses that hold a table for their time window
+var ActiveReservationStatuses = []ReservationStatus{
+	ReservationStatusPending,
+	ReservationStatusConfirmed,
+	ReservationStatusSeated,
+}
+
 // Reservation reservation model
 type Reservation struct {
 	BaseModel
-	UserID   uint              `gorm:"not null;index" json:"user_id"`
-	TableID  uint              `gorm:"not null;index" json:"table_id"`
-	Date     time.Time         `gorm:"type:date;not null" json:"date"`
-	Time     time.Time         `gorm:"type:time;not null" json:"time"`
-	Status   ReservationStatus `gorm:"type:varchar(20);default:'pending'" json:"status"`
-	
+	UserID             uint              `gorm:"not null;index" json:"user_id"`
+	TableID            uint              `gorm:"not null;index" json:"table_id"`              // Primary table (first member table for combinations)
+	TableCombinationID *uint             `gorm:"index" json:"table_combination_id,omitempty"` // Set when booked against a table combination
+	Date               time.Time         `gorm:"type:date;not null" json:"date"`
+	Time               time.Time         `gorm:"type:time;not null" json:"time"`
+	Duration           int               `gorm:"not null;default:120" json:"duration"` // Duration in minutes
+	PartySize          int               `gorm:"not null;default:1" json:"party_size"` // Number of guests
+	Status             ReservationStatus `gorm:"type:varchar(20);default:'pending'" json:"status"`
+	DepositAmount      float64           `gorm:"not null;default:0" json:"deposit_amount,omitempty"` // Deposit required by the booking policy
+	SeatedAt           *time.Time        `json:"seated_at,omitempty"`                                // When the guests were seated
+	CompletedAt        *time.Time        `json:"completed_at,omitempty"`                             // When the guests left the table
+	Version            int               `gorm:"not null;default:0" json:"-"`                        // Bumped on every modification; older confirmation links are rejected
+
 	// Relationships
-	User  User  `gorm:"foreignKey:UserID" json:"user,omitempty"`
-	Table Table `gorm:"foreignKey:TableID" json:"table,omitempty"`
+	User             User              `gorm:"foreignKey:UserID" json:"user,omitempty"`
+	Table            Table             `gorm:"foreignKey:TableID" json:"table,omitempty"`
+	TableCombination *TableCombination `gorm:"foreignKey:TableCombinationID" json:"table_combination,omitempty"`
+	Tables           []Table           `gorm:"many2many:reservation_tables;" json:"tables,omitempty"` // All tables held (combinations only)
+}
+
+// HeldTableIDs returns IDs of every table the reservation holds
+// Requires Tables to be loaded for combination reservations
+func (r *Reservation) HeldTableIDs() []uint {
+	if len(r.Tables) == 0 {
+		return []uint{r.TableID}
+	}
+	ids := make([]uint, 0, len(r.Tables))
+	for _, table := range r.Tables {
+		ids = append(ids, table.ID)
+	}
+	return ids
 }
 
+// StartTime returns the reservation start as a single instant in restaurant time
+func (r *Reservation) StartTime() time.Time {
+	return utils.CombineDateTime(r.Date, r.Time)
+}
+
+// EndTime returns the instant the reservation releases its table
+func (r *Reservation) EndTime() time.Time {
+	return r.StartTime().Add(time.Duration(r.Duration) * time.Minute)
+}
+
+// Overlaps checks if the reservation window intersects [start, end)
+func (r *Reservation) Overlaps(start, end time.Time) bool {
+	return r.StartTime().Before(end) && start.Before(r.EndTime())
+}
+
+// IsModifiable checks if the date, time, tables or party size of the reservation may still change
+func (r *Reservation) IsModifiable() bool {
+	return r.Status == ReservationStatusPending || r.Status == ReservationStatusConfirmed
+}
+
+// IsActive checks if the reservation currently holds its table
+func (r *Reservation) IsActive() bool {
+	for _, status := range ActiveReservationStatuses {
+		if r.Status == status {
+			return true
+		}
+	}
+	return false
+}
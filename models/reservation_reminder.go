@@ -0,0 +1,8 @@
+package models
+
+// ReservationReminder reminder sent for a reservation (prevents sending the same reminder twice, also across restarts)
+type ReservationReminder struct {
+	BaseModel
+	ReservationID uint `gorm:"not null;uniqueIndex:idx_reservation_reminder" json:"reservation_id"`
+	Offset        int  `gorm:"not null;uniqueIndex:idx_reservation_reminder" json:"offset"` // Minutes before the reservation start
+}
@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// ScheduledJob persisted schedule of a periodic background job (one row per job)
+type ScheduledJob struct {
+	BaseModel
+	Name         string     `gorm:"type:varchar(100);uniqueIndex;not null" json:"name"`
+	NextRunAt    time.Time  `gorm:"not null;index" json:"next_run_at"`
+	LastRunAt    *time.Time `json:"last_run_at"`
+	LastDuration int        `gorm:"not null;default:0" json:"last_duration"` // Duration of the last run in milliseconds
+	LastError    string     `gorm:"type:text" json:"last_error"`             // Empty when the last run succeeded
+	RunCount     int        `gorm:"not null;default:0" json:"run_count"`
+}
@@ -0,0 +1,23 @@
+package models
+
+// StatusHistoryEntity type of record a status change belongs to
+type StatusHistoryEntity string
+
+const (
+	StatusHistoryEntityReservation StatusHistoryEntity = "reservation"
+	StatusHistoryEntityOrder       StatusHistoryEntity = "order"
+)
+
+// StatusHistory status change audit trail entry of a reservation or order (CreatedAt is the time of the change)
+type StatusHistory struct {
+	BaseModel
+	EntityType StatusHistoryEntity `gorm:"type:varchar(20);not null;index:idx_status_history_entity" json:"entity_type"`
+	EntityID   uint                `gorm:"not null;index:idx_status_history_entity" json:"entity_id"`
+	FromStatus string              `gorm:"type:varchar(20)" json:"from_status"` // Empty when the record was created
+	ToStatus   string              `gorm:"type:varchar(20);not null" json:"to_status"`
+	ActorID    *uint               `gorm:"index" json:"actor_id"` // User who made the change (nil for system jobs)
+	Reason     string              `gorm:"type:text" json:"reason"`
+
+	// Relationships
+	Actor *User `gorm:"foreignKey:ActorID" json:"actor,omitempty"`
+}
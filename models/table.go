@@ -3,22 +3,30 @@ package models
 // TableStatus table status type
 type TableStatus string
 
+// Only available and maintenance are stored on the table (manual service flag);
+// reserved and occupied are derived from reservations at a point in time
 const (
-	TableStatusAvailable TableStatus = "available"
-	TableStatusReserved  TableStatus = "reserved"
-	TableStatusOccupied  TableStatus = "occupied"
+	TableStatusAvailable   TableStatus = "available"
+	TableStatusReserved    TableStatus = "reserved"
+	TableStatusOccupied    TableStatus = "occupied"
 	TableStatusMaintenance TableStatus = "maintenance"
 )
 
 // Table table model
 type Table struct {
 	BaseModel
-	Number   int         `gorm:"not null;uniqueIndex" json:"number"`
-	Capacity int         `gorm:"not null" json:"capacity"`
-	Location string      `gorm:"not null" json:"location"`
-	Status   TableStatus `gorm:"type:varchar(20);default:'available'" json:"status"`
-	
+	Number              int         `gorm:"not null;uniqueIndex" json:"number"`
+	Capacity            int         `gorm:"not null" json:"capacity"`
+	Location            string      `gorm:"not null" json:"location"`
+	Status              TableStatus `gorm:"type:varchar(20);default:'available'" json:"status"` // Manual service flag: available or maintenance
+	ReservationDuration int         `gorm:"default:0" json:"reservation_duration"`              // Default reservation length in minutes (0 = restaurant default)
+	Occupancy           TableStatus `gorm:"-" json:"occupancy,omitempty"`                       // Derived from reservations (not stored)
+
 	// Relationships
 	Reservations []Reservation `gorm:"foreignKey:TableID" json:"reservations,omitempty"`
 }
 
+// IsOutOfService checks if the table is manually taken out of service
+func (t *Table) IsOutOfService() bool {
+	return t.Status == TableStatusMaintenance
+}
@@ -0,0 +1,21 @@
+package models
+
+// TableCombination group of adjacent tables that can be pushed together for larger parties
+type TableCombination struct {
+	BaseModel
+	Name     string `gorm:"not null" json:"name"`          // Display name (e.g., "Window 1+2")
+	Capacity int    `gorm:"not null" json:"capacity"`      // Combined capacity
+	IsActive bool   `gorm:"default:true" json:"is_active"` // Combination can be booked
+
+	// Relationships
+	Tables []Table `gorm:"many2many:table_combination_tables;" json:"tables,omitempty"`
+}
+
+// TableIDs returns IDs of member tables
+func (tc *TableCombination) TableIDs() []uint {
+	ids := make([]uint, 0, len(tc.Tables))
+	for _, table := range tc.Tables {
+		ids = append(ids, table.ID)
+	}
+	return ids
+}
@@ -20,8 +20,15 @@ type User struct {
 	Password string   `gorm:"not null" json:"-"`
 	Name     string   `gorm:"not null" json:"name"`               // First name
 	LastName string   `gorm:"type:varchar(100)" json:"last_name"` // Last name (optional)
+	Email    string   `gorm:"type:varchar(255)" json:"email"`     // Email address for email notifications (optional)
 	Role     UserRole `gorm:"type:varchar(20);default:'customer'" json:"role"`
 
+	NoShowCount       int  `gorm:"not null;default:0" json:"no_show_count"`          // Reservations the user did not show up for
+	RemindersDisabled bool `gorm:"not null;default:false" json:"reminders_disabled"` // User opted out of reservation reminders
+
+	Language Language `gorm:"type:varchar(10)" json:"language"` // Notification language; empty for the default language
+	Calendar Calendar `gorm:"type:varchar(20)" json:"calendar"` // Calendar of dates in notifications; empty to follow the language
+
 	// Relationships
 	Reservations  []Reservation  `gorm:"foreignKey:UserID" json:"reservations,omitempty"`
 	Notifications []Notification `gorm:"foreignKey:UserID" json:"notifications,omitempty"`
@@ -0,0 +1,44 @@
+package models
+
+import (
+	"time"
+
+	"restaurant-booking-backend/utils"
+)
+
+// WaitlistStatus waitlist entry status type
+type WaitlistStatus string
+
+const (
+	WaitlistStatusWaiting   WaitlistStatus = "waiting"   // Waiting for a table to free up
+	WaitlistStatusOffered   WaitlistStatus = "offered"   // A freed table is held for the customer until OfferExpiresAt
+	WaitlistStatusClaimed   WaitlistStatus = "claimed"   // Customer accepted the offered table
+	WaitlistStatusExpired   WaitlistStatus = "expired"   // Offer or requested time passed without a claim
+	WaitlistStatusCancelled WaitlistStatus = "cancelled" // Customer left the waitlist
+)
+
+// WaitlistEntry customer waiting for a fully booked date and time
+type WaitlistEntry struct {
+	BaseModel
+	UserID         uint           `gorm:"not null;index" json:"user_id"`
+	Date           time.Time      `gorm:"type:date;not null;index" json:"date"`
+	Time           time.Time      `gorm:"type:time;not null" json:"time"`
+	PartySize      int            `gorm:"not null" json:"party_size"` // Number of guests
+	Status         WaitlistStatus `gorm:"type:varchar(20);default:'waiting';index" json:"status"`
+	ReservationID  *uint          `gorm:"index" json:"reservation_id,omitempty"` // Reservation held for the customer while offered
+	OfferExpiresAt *time.Time     `json:"offer_expires_at,omitempty"`            // Deadline to claim the offered table
+
+	// Relationships
+	User        User         `gorm:"foreignKey:UserID" json:"user,omitempty"`
+	Reservation *Reservation `gorm:"foreignKey:ReservationID" json:"reservation,omitempty"`
+}
+
+// StartTime returns the requested time as a single instant in restaurant time
+func (w *WaitlistEntry) StartTime() time.Time {
+	return utils.CombineDateTime(w.Date, w.Time)
+}
+
+// IsOpen checks if the entry is still waiting for or holding an offer
+func (w *WaitlistEntry) IsOpen() bool {
+	return w.Status == WaitlistStatusWaiting || w.Status == WaitlistStatusOffered
+}
@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// WalkInStatus walk-in queue entry status type
+type WalkInStatus string
+
+const (
+	WalkInStatusWaiting WalkInStatus = "waiting" // Waiting at the door
+	WalkInStatusSeated  WalkInStatus = "seated"  // Seated at a table
+	WalkInStatusRemoved WalkInStatus = "removed" // Left or removed from the queue
+)
+
+// WalkIn guest waiting at the door without a reservation
+type WalkIn struct {
+	BaseModel
+	Name          string       `gorm:"not null" json:"name"`
+	Phone         string       `gorm:"type:varchar(20);not null;index" json:"phone"`
+	PartySize     int          `gorm:"not null" json:"party_size"`  // Number of guests
+	QuotedWait    int          `gorm:"not null" json:"quoted_wait"` // Wait quoted to the guest in minutes
+	Status        WalkInStatus `gorm:"type:varchar(20);default:'waiting';index" json:"status"`
+	ReservationID *uint        `gorm:"index" json:"reservation_id,omitempty"` // Reservation created when seated
+	SeatedAt      *time.Time   `json:"seated_at,omitempty"`
+	EstimatedWait *int         `gorm:"-" json:"estimated_wait,omitempty"` // Current estimated wait in minutes (waiting entries only)
+
+	// Relationships
+	Reservation *Reservation `gorm:"foreignKey:ReservationID" json:"reservation,omitempty"`
+}
@@ -19,6 +19,16 @@ var (
 	userController         = controllers.UserController{}
 	categoryController     = controllers.CategoryController{}
 	orderController        = controllers.OrderController{}
+	openingHoursController = controllers.OpeningHoursController{}
+	combinationController  = controllers.TableCombinationController{}
+	waitlistController     = controllers.NewWaitlistController()
+	walkInController       = controllers.NewWalkInController()
+	policyController       = controllers.BookingPolicyController{}
+	outboxController       = controllers.OutboxController{}
+	floorController        = controllers.FloorController{}
+	kitchenController      = controllers.KitchenController{}
+	templateController     = controllers.NotificationTemplateController{}
+	promotionController    = controllers.PromotionController{}
 )
 
 // SetupRoutes sets up API routes
@@ -49,9 +59,14 @@ func SetupRoutes(app *fiber.App) {
 	tables := api.Group("/tables")
 	{
 		tables.Get("/available", tableController.GetAvailableTables)
+		tables.Get("/availability", tableController.GetTableAvailability)
+		tables.Get("/combinations", combinationController.GetTableCombinations)
 		tables.Get("/statuses", tableController.GetTableStatuses)
 	}
 
+	// Opening hours routes (public - for customers to see when the restaurant is open)
+	api.Get("/opening-hours", openingHoursController.GetOpeningHours)
+
 	// Category routes (public - for customers to view categories)
 	categories := api.Group("/categories")
 	{
@@ -59,17 +74,36 @@ func SetupRoutes(app *fiber.App) {
 		categories.Get("/:id", categoryController.GetCategoryByID)
 	}
 
+	// Reservation confirmation link routes (public) - the signed token authorizes the customer
+	reservationLinks := api.Group("/reservation-links")
+	{
+		reservationLinks.Get("/:token", reservationController.GetReservationByToken)
+		reservationLinks.Post("/:token/confirm", reservationController.ConfirmReservationByToken)
+		reservationLinks.Post("/:token/cancel", reservationController.CancelReservationByToken)
+	}
+
+	// Notification stream (authenticated) - also accepts the token as a query parameter for EventSource clients
+	api.Get("/notifications/stream", middleware.StreamAuthMiddleware(), notificationController.StreamNotifications)
+
+	// Floor event stream (admin only) - keeps host clients in sync with table and reservation changes
+	api.Get("/admin/floor/events", middleware.StreamAuthMiddleware(), middleware.RequireAdmin(), floorController.StreamFloorEvents)
+
+	// Kitchen display stream (admin only)
+	api.Get("/admin/kitchen/stream", middleware.StreamAuthMiddleware(), middleware.RequireAdmin(), kitchenController.StreamTickets)
+
 	// Protected routes
 	protected := api.Group("", middleware.AuthMiddleware())
 	{
 		// Example protected route
 		protected.Get("/profile", getProfile)
+		protected.Put("/profile/reminders", userController.UpdateReminderPreference)
+		protected.Put("/profile/language", userController.UpdateLanguagePreference)
 
 		// Admin only routes
-		admin := protected.Group("", middleware.RequireAdmin())
+		admin := protected.Group("/admin", middleware.RequireAdmin())
 		{
 			// User management routes (admin only)
-			adminUsers := admin.Group("/admin/users")
+			adminUsers := admin.Group("/users")
 			{
 				adminUsers.Get("", userController.GetAllUsers)
 				adminUsers.Get("/:id", userController.GetUserByID)
@@ -78,7 +112,7 @@ func SetupRoutes(app *fiber.App) {
 			}
 
 			// Menu management routes (admin only)
-			adminMenu := admin.Group("/admin/menu")
+			adminMenu := admin.Group("/menu")
 			{
 				adminMenu.Post("", menuController.CreateMenuItem)
 				adminMenu.Put("/:id", menuController.UpdateMenuItem)
@@ -86,7 +120,7 @@ func SetupRoutes(app *fiber.App) {
 			}
 
 			// Category management routes (admin only)
-			adminCategories := admin.Group("/admin/categories")
+			adminCategories := admin.Group("/categories")
 			{
 				adminCategories.Post("", categoryController.CreateCategory)
 				adminCategories.Put("/:id", categoryController.UpdateCategory)
@@ -94,7 +128,7 @@ func SetupRoutes(app *fiber.App) {
 			}
 
 			// Table management routes (admin only)
-			adminTables := admin.Group("/admin/tables")
+			adminTables := admin.Group("/tables")
 			{
 				adminTables.Get("", tableController.GetAllTables)
 				adminTables.Get("/:id", tableController.GetTableByID)
@@ -103,47 +137,142 @@ func SetupRoutes(app *fiber.App) {
 				adminTables.Delete("/:id", tableController.DeleteTable)
 			}
 
+			// Table combination routes - tables pushed together for larger parties (admin only)
+			adminCombinations := admin.Group("/table-combinations")
+			{
+				adminCombinations.Get("", combinationController.GetAllTableCombinations)
+				adminCombinations.Get("/:id", combinationController.GetTableCombinationByID)
+				adminCombinations.Post("", combinationController.CreateTableCombination)
+				adminCombinations.Put("/:id", combinationController.UpdateTableCombination)
+				adminCombinations.Delete("/:id", combinationController.DeleteTableCombination)
+			}
+
 			// Reservation management routes (admin only)
-			adminReservations := admin.Group("/admin/reservations")
+			adminReservations := admin.Group("/reservations")
 			{
 				adminReservations.Post("", reservationController.CreateReservationByAdmin)
 				adminReservations.Get("", reservationController.GetAllReservations)
 				adminReservations.Get("/statuses", reservationController.GetReservationStatuses)
 				adminReservations.Get("/:id", reservationController.GetReservationByID)
+				adminReservations.Get("/:id/history", reservationController.GetReservationHistory)
+				adminReservations.Put("/:id", reservationController.ModifyReservation)
 				adminReservations.Put("/:id/status", reservationController.UpdateReservationStatus)
 				adminReservations.Delete("/:id", reservationController.CancelReservation)
 			}
 
+			// Opening hours management routes (admin only)
+			adminOpeningHours := admin.Group("/opening-hours")
+			{
+				adminOpeningHours.Get("", openingHoursController.GetAllOpeningHours)
+				adminOpeningHours.Post("", openingHoursController.CreateOpeningHours)
+				adminOpeningHours.Put("/:id", openingHoursController.UpdateOpeningHours)
+				adminOpeningHours.Delete("/:id", openingHoursController.DeleteOpeningHours)
+			}
+
+			// Calendar exception routes - holiday closures and special hours (admin only)
+			adminCalendar := admin.Group("/calendar-exceptions")
+			{
+				adminCalendar.Get("", openingHoursController.GetAllCalendarExceptions)
+				adminCalendar.Post("", openingHoursController.CreateCalendarException)
+				adminCalendar.Put("/:id", openingHoursController.UpdateCalendarException)
+				adminCalendar.Delete("/:id", openingHoursController.DeleteCalendarException)
+			}
+
+			// Booking policy routes - limits for customer bookings (admin only)
+			adminPolicy := admin.Group("/booking-policy")
+			{
+				adminPolicy.Get("", policyController.GetBookingPolicy)
+				adminPolicy.Put("", policyController.UpdateBookingPolicy)
+			}
+
+			// Notification outbox routes - inspect and retry failed deliveries (admin only)
+			adminOutbox := admin.Group("/outbox")
+			{
+				adminOutbox.Get("", outboxController.GetOutboxMessages)
+				adminOutbox.Get("/:id", outboxController.GetOutboxMessageByID)
+				adminOutbox.Post("/retry", outboxController.RetryDeadOutboxMessages)
+				adminOutbox.Post("/:id/retry", outboxController.RetryOutboxMessage)
+			}
+
+			// Notification template routes - messages of each notification event per language (admin only)
+			adminTemplates := admin.Group("/notification-templates")
+			{
+				adminTemplates.Get("", templateController.GetNotificationTemplates)
+				adminTemplates.Put("/:event/:language", templateController.UpdateNotificationTemplate)
+				adminTemplates.Delete("/:event/:language", templateController.ResetNotificationTemplate)
+			}
+
+			// Promotion routes - broadcast promotions to customers in batches (admin only)
+			adminPromotions := admin.Group("/promotions")
+			{
+				adminPromotions.Get("", promotionController.GetPromotions)
+				adminPromotions.Get("/:id", promotionController.GetPromotionByID)
+				adminPromotions.Post("", promotionController.CreatePromotion)
+				adminPromotions.Post("/:id/cancel", promotionController.CancelPromotion)
+			}
+
+			// Waitlist routes (admin only)
+			admin.Get("/waitlist", waitlistController.GetAllWaitlistEntries)
+
+			// Walk-in queue routes - guests waiting at the door (admin only)
+			adminWalkIns := admin.Group("/walk-ins")
+			{
+				adminWalkIns.Get("", walkInController.GetWalkIns)
+				adminWalkIns.Get("/estimate", walkInController.GetWalkInWaitEstimate)
+				adminWalkIns.Post("", walkInController.AddWalkIn)
+				adminWalkIns.Post("/:id/seat", walkInController.SeatWalkIn)
+				adminWalkIns.Delete("/:id", walkInController.RemoveWalkIn)
+			}
+
 			// Order management routes (admin only)
-			adminOrders := admin.Group("/admin/orders")
+			adminOrders := admin.Group("/orders")
 			{
 				adminOrders.Post("", orderController.CreateOrderByAdmin)
 				adminOrders.Get("", orderController.GetAllOrders)
 				adminOrders.Get("/statuses", orderController.GetOrderStatuses)
 				adminOrders.Get("/:id", orderController.GetOrderByID)
+				adminOrders.Get("/:id/history", orderController.GetOrderHistory)
 				adminOrders.Put("/:id/status", orderController.UpdateOrderStatus)
 			}
-		}
 
-		// Customer only routes
-		customer := protected.Group("", middleware.RequireCustomer())
-		{
-			// Reservation routes (customer)
-			customerReservations := customer.Group("/reservations")
+			// Kitchen display routes (admin only)
+			adminKitchen := admin.Group("/kitchen")
 			{
-				customerReservations.Post("", reservationController.CreateReservation)
-				customerReservations.Get("", reservationController.GetUserReservations)
-				customerReservations.Get("/:id", reservationController.GetReservationByID)
-				customerReservations.Delete("/:id", reservationController.CancelReservation)
+				adminKitchen.Get("/stations", kitchenController.GetStations)
+				adminKitchen.Get("/tickets", kitchenController.GetTickets)
+				adminKitchen.Post("/tickets/:id/bump", kitchenController.BumpTicket)
+				adminKitchen.Post("/tickets/:id/recall", kitchenController.RecallTicket)
 			}
+		}
 
-			// Order routes (customer)
-			customerOrders := customer.Group("/orders")
-			{
-				customerOrders.Post("", orderController.CreateOrder)
-				customerOrders.Get("", orderController.GetUserOrders)
-				customerOrders.Get("/:id", orderController.GetOrderByID)
-			}
+		// Customer only routes: each group checks the role itself, as the check of a group without a
+		// prefix would apply to every route registered after it
+
+		// Reservation routes (customer)
+		customerReservations := protected.Group("/reservations", middleware.RequireCustomer())
+		{
+			customerReservations.Post("", reservationController.CreateReservation)
+			customerReservations.Get("", reservationController.GetUserReservations)
+			customerReservations.Get("/:id", reservationController.GetReservationByID)
+			customerReservations.Put("/:id", reservationController.ModifyReservation)
+			customerReservations.Delete("/:id", reservationController.CancelReservation)
+		}
+
+		// Waitlist routes (customer) - join when a slot is fully booked and claim freed tables
+		customerWaitlist := protected.Group("/waitlist", middleware.RequireCustomer())
+		{
+			customerWaitlist.Post("", waitlistController.JoinWaitlist)
+			customerWaitlist.Get("", waitlistController.GetUserWaitlist)
+			customerWaitlist.Post("/:id/claim", waitlistController.ClaimWaitlistOffer)
+			customerWaitlist.Delete("/:id", waitlistController.LeaveWaitlist)
+		}
+
+		// Order routes (customer)
+		customerOrders := protected.Group("/orders", middleware.RequireCustomer())
+		{
+			customerOrders.Post("", orderController.CreateOrder)
+			customerOrders.Get("", orderController.GetUserOrders)
+			customerOrders.Get("/:id", orderController.GetOrderByID)
 		}
 
 		// Notification routes (for all authenticated users)
@@ -151,6 +280,8 @@ func SetupRoutes(app *fiber.App) {
 		{
 			notifications.Get("", notificationController.GetUserNotifications)
 			notifications.Get("/count", notificationController.GetUnreadNotificationsCount)
+			notifications.Get("/preferences", notificationController.GetNotificationPreferences)
+			notifications.Put("/preferences", notificationController.UpdateNotificationPreferences)
 			notifications.Put("/:id/read", notificationController.MarkNotificationAsRead)
 			notifications.Delete("/:id", notificationController.DeleteNotification)
 		}
@@ -165,6 +296,12 @@ func healthCheck(c *fiber.Ctx) error {
 	})
 }
 
+// profileResponse user profile, also carrying the ID under the user_id name used by the auth token claims
+type profileResponse struct {
+	models.User
+	UserID uint `json:"user_id"`
+}
+
 // getProfile gets current user profile with all information
 func getProfile(c *fiber.Ctx) error {
 	userID := c.Locals("user_id")
@@ -199,6 +336,6 @@ func getProfile(c *fiber.Ctx) error {
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
 		"success": true,
 		"message": "Profile retrieved successfully",
-		"data":    user,
+		"data":    profileResponse{User: user, UserID: user.ID},
 	})
 }
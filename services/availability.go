@@ -0,0 +1,164 @@
+package services
+
+import (
+	"time"
+
+	"restaurant-booking-backend/config"
+	"restaurant-booking-backend/models"
+	"restaurant-booking-backend/utils"
+
+	"gorm.io/gorm"
+)
+
+// TimeWindow time interval [Start, End) in restaurant time
+type TimeWindow struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+// Contains checks if [start, end) lies fully inside the window
+func (w TimeWindow) Contains(start, end time.Time) bool {
+	return !start.Before(w.Start) && !end.After(w.End)
+}
+
+// AvailabilityQuery availability search parameters
+type AvailabilityQuery struct {
+	Date      time.Time // Day to search
+	From      time.Time // Earliest slot start
+	To        time.Time // Latest slot start (equal to From to check a single slot)
+	PartySize int       // Number of guests
+	Duration  int       // Optional duration override in minutes
+	Location  string    // Optional table location filter
+}
+
+// TimeSlot bookable time slot of a table
+type TimeSlot struct {
+	Time      string `json:"time"`     // Format: "15:04"
+	EndTime   string `json:"end_time"` // Format: "15:04"
+	Available bool   `json:"available"`
+}
+
+// TableAvailability slot availability of a single table
+type TableAvailability struct {
+	Table    models.Table `json:"table"`
+	Duration int          `json:"duration"` // Reservation duration used for this table in minutes
+	Slots    []TimeSlot   `json:"slots"`
+}
+
+// newTimeWindow builds a window from opening and closing clock times on a date
+func newTimeWindow(date, opening, closing time.Time) TimeWindow {
+	start := utils.CombineDateTime(date, opening)
+	end := utils.CombineDateTime(date, closing)
+	if !end.After(start) {
+		// Closes after midnight
+		end = end.AddDate(0, 0, 1)
+	}
+	return TimeWindow{Start: start, End: end}
+}
+
+// GetTableAvailability returns per-table slot availability for a day based on actual reservations
+func (rs *ReservationService) GetTableAvailability(tx *gorm.DB, q AvailabilityQuery) ([]TableAvailability, error) {
+	windows, err := rs.openingHours.ServiceWindows(tx, q.Date)
+	if err != nil {
+		return nil, err
+	}
+
+	var tables []models.Table
+	query := tx.Where("capacity >= ? AND status != ?", q.PartySize, models.TableStatusMaintenance)
+	if q.Location != "" {
+		query = query.Where("LOWER(location) LIKE LOWER(?)", "%"+q.Location+"%")
+	}
+	if err := query.Order("number ASC").Find(&tables).Error; err != nil {
+		return nil, err
+	}
+
+	result := []TableAvailability{}
+	if len(tables) == 0 {
+		return result, nil
+	}
+
+	// Load active reservations of all candidate tables once
+	tableIDs := make([]uint, 0, len(tables))
+	for _, table := range tables {
+		tableIDs = append(tableIDs, table.ID)
+	}
+	dayStart := utils.CombineDateTime(q.Date, time.Time{})
+	reservations, err := rs.findActiveReservations(tx, tableIDs, dayStart, dayStart.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, err
+	}
+	reservationsByTable := make(map[uint][]models.Reservation)
+	for _, reservation := range reservations {
+		for _, tableID := range reservation.HeldTableIDs() {
+			reservationsByTable[tableID] = append(reservationsByTable[tableID], reservation)
+		}
+	}
+
+	now := time.Now()
+	for _, table := range tables {
+		// Skip tables the party is too small for
+		if err := rs.ValidatePartySize(&table, q.PartySize); err != nil {
+			continue
+		}
+
+		duration := rs.ResolveDuration(&table, q.Duration)
+		length := time.Duration(duration) * time.Minute
+
+		slots := []TimeSlot{}
+		for _, start := range slotStarts(windows, q.From, q.To, length) {
+			end := start.Add(length)
+			available := !start.Before(now) && insideAnyWindow(windows, start, end)
+			for i := range reservationsByTable[table.ID] {
+				if !available {
+					break
+				}
+				available = !reservationsByTable[table.ID][i].Overlaps(start, end)
+			}
+
+			slots = append(slots, TimeSlot{
+				Time:      start.Format("15:04"),
+				EndTime:   end.Format("15:04"),
+				Available: available,
+			})
+		}
+
+		result = append(result, TableAvailability{
+			Table:    table,
+			Duration: duration,
+			Slots:    slots,
+		})
+	}
+
+	return result, nil
+}
+
+// slotStarts returns candidate slot starts between from and to
+// A single requested time is always returned (and later marked unavailable if outside opening hours);
+// a range is expanded into the slot grid of each window, keeping only slots that fit before closing
+func slotStarts(windows []TimeWindow, from, to time.Time, length time.Duration) []time.Time {
+	if from.Equal(to) {
+		return []time.Time{from}
+	}
+
+	interval := time.Duration(config.GetReservationSlotInterval()) * time.Minute
+	var starts []time.Time
+	for _, window := range windows {
+		for start := window.Start; !start.Add(length).After(window.End); start = start.Add(interval) {
+			if start.Before(from) || start.After(to) {
+				continue
+			}
+			starts = append(starts, start)
+		}
+	}
+	return starts
+}
+
+// insideAnyWindow checks if [start, end) fits inside one of the windows
+func insideAnyWindow(windows []TimeWindow, start, end time.Time) bool {
+	for _, window := range windows {
+		if window.Contains(start, end) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,95 @@
+package services
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"restaurant-booking-backend/models"
+	"restaurant-booking-backend/utils"
+
+	"gorm.io/gorm"
+)
+
+// BookingPolicyService applies the admin-configured booking policy to customer bookings
+type BookingPolicyService struct{}
+
+// GetPolicy returns the booking policy (every rule disabled when none is configured yet)
+func (bps *BookingPolicyService) GetPolicy(tx *gorm.DB) (*models.BookingPolicy, error) {
+	var policy models.BookingPolicy
+	if err := tx.Order("id ASC").First(&policy).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return &models.BookingPolicy{NoShowAction: models.NoShowPolicyBlock}, nil
+		}
+		return nil, err
+	}
+	return &policy, nil
+}
+
+// CheckTiming checks the minimum lead time and maximum advance window for a booking starting at start
+func (bps *BookingPolicyService) CheckTiming(policy *models.BookingPolicy, start time.Time) error {
+	now := time.Now()
+
+	if policy.MinLeadTime > 0 && start.Before(now.Add(time.Duration(policy.MinLeadTime)*time.Minute)) {
+		return newDomainError(ReasonBelowMinLeadTime,
+			fmt.Sprintf("Reservations must be made at least %d minutes in advance", policy.MinLeadTime))
+	}
+
+	if policy.MaxAdvanceDays > 0 {
+		// Compare calendar days in restaurant time so the whole last day stays bookable
+		local := now.In(utils.RestaurantLocation())
+		lastDay := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, utils.RestaurantLocation()).
+			AddDate(0, 0, policy.MaxAdvanceDays+1)
+		if !start.Before(lastDay) {
+			return newDomainError(ReasonBeyondAdvanceWindow,
+				fmt.Sprintf("Reservations can be made at most %d days in advance", policy.MaxAdvanceDays))
+		}
+	}
+
+	return nil
+}
+
+// CheckCustomer checks the customer's upcoming reservations and no-show record against the policy and
+// returns the deposit the booking requires (0 when none)
+// Locks the user row so concurrent bookings of the same customer are counted correctly
+func (bps *BookingPolicyService) CheckCustomer(tx *gorm.DB, policy *models.BookingPolicy, userID uint) (float64, error) {
+	var user models.User
+	if err := tx.Set("gorm:query_option", "FOR UPDATE").First(&user, userID).Error; err != nil {
+		return 0, err
+	}
+
+	if policy.NoShowThreshold > 0 && user.NoShowCount >= policy.NoShowThreshold && policy.NoShowAction == models.NoShowPolicyBlock {
+		return 0, &DomainError{
+			Status:  http.StatusForbidden,
+			Code:    ReasonCustomerBlocked,
+			Message: "Online booking is unavailable for this account due to missed reservations. Please contact the restaurant",
+		}
+	}
+
+	if policy.MaxActiveReservations > 0 {
+		local := time.Now().In(utils.RestaurantLocation())
+		today := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, time.UTC)
+
+		var active int64
+		if err := tx.Model(&models.Reservation{}).
+			Where("user_id = ? AND status IN ? AND date >= ?", userID, []models.ReservationStatus{
+				models.ReservationStatusPending,
+				models.ReservationStatusConfirmed,
+			}, today).Count(&active).Error; err != nil {
+			return 0, err
+		}
+		if active >= int64(policy.MaxActiveReservations) {
+			return 0, &DomainError{
+				Status:  http.StatusConflict,
+				Code:    ReasonMaxActiveReservations,
+				Message: fmt.Sprintf("You can hold at most %d upcoming reservations", policy.MaxActiveReservations),
+			}
+		}
+	}
+
+	if policy.NoShowThreshold > 0 && user.NoShowCount >= policy.NoShowThreshold && policy.NoShowAction == models.NoShowPolicyDeposit {
+		return policy.DepositAmount, nil
+	}
+
+	return 0, nil
+}
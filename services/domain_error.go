@@ -0,0 +1,54 @@
+package services
+
+import "net/http"
+
+// Booking rule violation reason codes
+const (
+	ReasonPartySizeInvalid      = "party_size_invalid"
+	ReasonPartyExceedsCapacity  = "party_exceeds_capacity"
+	ReasonPartyBelowMinimumFill = "party_below_minimum_fill"
+	ReasonRestaurantClosed      = "restaurant_closed"
+	ReasonOutsideOpeningHours   = "outside_opening_hours"
+	ReasonTableRequired         = "table_required"
+	ReasonTableNotFound         = "table_not_found"
+	ReasonTableOutOfService     = "table_out_of_service"
+	ReasonTableAlreadyReserved  = "table_already_reserved"
+	ReasonNoTableAvailable      = "no_table_available"
+
+	// Reservation and order status changes
+	ReasonInvalidStatus            = "invalid_status"
+	ReasonInvalidStatusTransition  = "invalid_status_transition"
+	ReasonNoShowBeforeStart        = "no_show_before_start"
+	ReasonReservationNotModifiable = "reservation_not_modifiable"
+	ReasonDepositRequired          = "deposit_required"
+
+	// Booking policies
+	ReasonMaxActiveReservations = "max_active_reservations"
+	ReasonBeyondAdvanceWindow   = "beyond_advance_window"
+	ReasonBelowMinLeadTime      = "below_min_lead_time"
+	ReasonCustomerBlocked       = "customer_blocked"
+
+	// Reservation confirmation links
+	ReasonConfirmationLinkInvalid  = "confirmation_link_invalid"
+	ReasonConfirmationLinkExpired  = "confirmation_link_expired"
+	ReasonConfirmationLinkOutdated = "confirmation_link_outdated"
+	ReasonReservationNotFound      = "reservation_not_found"
+)
+
+// DomainError business rule violation with HTTP status and machine-readable reason code
+// Reason codes of other domains than bookings are declared next to the service returning them
+type DomainError struct {
+	Status  int    // HTTP status code to respond with
+	Code    string // Machine-readable reason code
+	Message string // Human-readable message
+}
+
+// Error implements error interface
+func (e *DomainError) Error() string {
+	return e.Message
+}
+
+// newDomainError creates a domain error responded to with 400 Bad Request
+func newDomainError(code, message string) *DomainError {
+	return &DomainError{Status: http.StatusBadRequest, Code: code, Message: message}
+}
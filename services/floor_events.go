@@ -0,0 +1,249 @@
+package services
+
+import (
+	"encoding/json"
+	"log"
+	"sync"
+	"time"
+
+	"restaurant-booking-backend/config"
+	"restaurant-booking-backend/models"
+
+	"gorm.io/gorm"
+)
+
+// floorEventStreamBuffer events buffered per host client; a client falling further behind is dropped
+// and catches up from its last received ID when it reconnects
+const floorEventStreamBuffer = 256
+
+// floorEventBatchSize maximum number of floor events loaded per query
+const floorEventBatchSize = 500
+
+// FloorEventService records changes on the restaurant floor for host clients
+type FloorEventService struct{}
+
+// RecordTable records a table change; pass the transaction of the change
+func (fes *FloorEventService) RecordTable(tx *gorm.DB, eventType models.FloorEventType, table *models.Table) error {
+	return fes.record(tx, eventType, nil, []uint{table.ID}, table)
+}
+
+// RecordReservation records a reservation change; pass the transaction of the change
+// Requires Tables to be loaded for combination reservations
+func (fes *FloorEventService) RecordReservation(tx *gorm.DB, eventType models.FloorEventType, reservation *models.Reservation) error {
+	return fes.record(tx, eventType, &reservation.ID, reservation.HeldTableIDs(), reservation)
+}
+
+// RecordReservationStatus records the status change of a reservation, as a seat or cancellation when it is one
+func (fes *FloorEventService) RecordReservationStatus(tx *gorm.DB, reservation *models.Reservation) error {
+	eventType := models.FloorEventReservationStatusChanged
+	switch reservation.Status {
+	case models.ReservationStatusSeated:
+		eventType = models.FloorEventReservationSeated
+	case models.ReservationStatusCancelled:
+		eventType = models.FloorEventReservationCancelled
+	}
+	return fes.RecordReservation(tx, eventType, reservation)
+}
+
+// record stores a floor event with a snapshot of the changed record
+func (fes *FloorEventService) record(tx *gorm.DB, eventType models.FloorEventType, reservationID *uint, tableIDs []uint, data interface{}) error {
+	body, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	event := models.FloorEvent{
+		Type:          eventType,
+		ReservationID: reservationID,
+		Data:          string(body),
+	}
+	event.SetTableIDs(tableIDs)
+	return tx.Create(&event).Error
+}
+
+// GetEventsAfter returns up to limit floor events with an ID above afterID, oldest first
+// Stops before floor events that may still be committing, so events that commit out of ID order are not skipped
+func (fes *FloorEventService) GetEventsAfter(afterID uint, limit int) ([]models.FloorEvent, error) {
+	settled, err := fes.GetLatestEventID()
+	if err != nil {
+		return nil, err
+	}
+
+	var events []models.FloorEvent
+	err = config.DB.Where("id > ? AND id <= ?", afterID, settled).Order("id ASC").Limit(limit).Find(&events).Error
+	return events, err
+}
+
+// GetLatestEventID returns the ID of the newest settled floor event (0 when there are none)
+// Floor events that may still be committing are left out, so they are pushed once they show up
+func (fes *FloorEventService) GetLatestEventID() (uint, error) {
+	return settledID(config.DB, &models.FloorEvent{})
+}
+
+// PruneEvents deletes floor events past the retention period; returns the number deleted
+func (fes *FloorEventService) PruneEvents() (int, error) {
+	cutoff := time.Now().Add(-time.Duration(config.GetFloorEventRetention()) * time.Hour)
+	result := config.DB.Unscoped().Where("created_at < ?", cutoff).Delete(&models.FloorEvent{})
+	return int(result.RowsAffected), result.Error
+}
+
+// Jobs returns the floor event jobs to register with the scheduler
+func (fes *FloorEventService) Jobs() []Job {
+	return []Job{
+		{Name: "prune_floor_events", Interval: time.Hour, Run: countingJob("Pruned", "floor event(s)", fes.PruneEvents)},
+	}
+}
+
+// FloorEventSubscription live floor events of a single host client
+type FloorEventSubscription struct {
+	Events <-chan models.FloorEvent // Closed when the subscription is dropped or the stream stops
+	events chan models.FloorEvent
+}
+
+// FloorEventStream pushes newly recorded floor events to every subscribed host client
+// Events are picked up from the database, so only committed changes are pushed, including those of other app instances
+type FloorEventStream struct {
+	poll          time.Duration
+	mu            sync.Mutex
+	subscriptions map[*FloorEventSubscription]struct{}
+	lastID        uint // Floor events up to this ID were pushed; later ones are pushed in ID order
+	stopped       bool
+	stop          chan struct{}
+	done          chan struct{}
+	stopOnce      sync.Once
+	floorEvents   FloorEventService
+}
+
+// sharedFloorEventStream floor event stream shared by the API and the server lifecycle
+var sharedFloorEventStream = NewFloorEventStream(time.Second)
+
+// SharedFloorEventStream returns the floor event stream host clients subscribe to
+func SharedFloorEventStream() *FloorEventStream {
+	return sharedFloorEventStream
+}
+
+// NewFloorEventStream creates a stream that checks for new floor events every poll
+func NewFloorEventStream(poll time.Duration) *FloorEventStream {
+	return &FloorEventStream{
+		poll:          poll,
+		subscriptions: map[*FloorEventSubscription]struct{}{},
+		stop:          make(chan struct{}),
+		done:          make(chan struct{}),
+	}
+}
+
+// Start starts pushing floor events recorded from now on
+func (fs *FloorEventStream) Start() error {
+	lastID, err := fs.floorEvents.GetLatestEventID()
+	if err != nil {
+		return err
+	}
+
+	fs.mu.Lock()
+	fs.lastID = lastID
+	fs.mu.Unlock()
+
+	go fs.loop()
+	return nil
+}
+
+// Stop stops pushing floor events, ends every subscription and waits up to timeout for the current poll
+func (fs *FloorEventStream) Stop(timeout time.Duration) {
+	fs.stopOnce.Do(func() {
+		close(fs.stop)
+
+		fs.mu.Lock()
+		fs.stopped = true
+		for subscription := range fs.subscriptions {
+			close(subscription.events)
+		}
+		fs.subscriptions = map[*FloorEventSubscription]struct{}{}
+		fs.mu.Unlock()
+	})
+
+	select {
+	case <-fs.done:
+	case <-time.After(timeout):
+		log.Printf("Floor event stream did not stop within %s", timeout)
+	}
+}
+
+// Subscribe subscribes to new floor events; call Unsubscribe once done
+func (fs *FloorEventStream) Subscribe() *FloorEventSubscription {
+	events := make(chan models.FloorEvent, floorEventStreamBuffer)
+	subscription := &FloorEventSubscription{Events: events, events: events}
+
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	if fs.stopped {
+		close(events)
+		return subscription
+	}
+	fs.subscriptions[subscription] = struct{}{}
+	return subscription
+}
+
+// Unsubscribe ends a subscription
+func (fs *FloorEventStream) Unsubscribe(subscription *FloorEventSubscription) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	fs.remove(subscription)
+}
+
+// remove drops a subscription and closes its channel (callers hold mu)
+func (fs *FloorEventStream) remove(subscription *FloorEventSubscription) {
+	if _, ok := fs.subscriptions[subscription]; !ok {
+		return
+	}
+	delete(fs.subscriptions, subscription)
+	close(subscription.events)
+}
+
+// loop pushes new floor events every poll until the stream is stopped
+func (fs *FloorEventStream) loop() {
+	defer close(fs.done)
+
+	ticker := time.NewTicker(fs.poll)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-fs.stop:
+			return
+		case <-ticker.C:
+			fs.Push()
+		}
+	}
+}
+
+// Push pushes floor events recorded since the last push to every subscriber; returns the number of events
+func (fs *FloorEventStream) Push() int {
+	fs.mu.Lock()
+	lastID := fs.lastID
+	fs.mu.Unlock()
+
+	events, err := fs.floorEvents.GetEventsAfter(lastID, floorEventBatchSize)
+	if err != nil {
+		log.Printf("Failed to load new floor events: %v", err)
+		return 0
+	}
+
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	for _, event := range events {
+		if event.ID > fs.lastID {
+			fs.lastID = event.ID
+		}
+		for subscription := range fs.subscriptions {
+			select {
+			case subscription.events <- event:
+			default:
+				log.Printf("Dropping floor event subscription: too far behind")
+				fs.remove(subscription)
+			}
+		}
+	}
+	return len(events)
+}
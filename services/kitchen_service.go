@@ -0,0 +1,393 @@
+package services
+
+import (
+	"log"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"restaurant-booking-backend/config"
+	"restaurant-booking-backend/models"
+
+	"gorm.io/gorm"
+)
+
+// Kitchen display reason codes
+const (
+	ReasonOrderNotFound         = "order_not_found"
+	ReasonUnknownKitchenStation = "unknown_kitchen_station"
+)
+
+// kitchenRecallWindow how long bumped (ready) tickets stay listed for the kitchen to recall
+const kitchenRecallWindow = 30 * time.Minute
+
+// kitchenStreamLookback how far back the kitchen stream looks for changed orders on each poll, so orders saved
+// shortly before a poll but committed after it are not missed
+const kitchenStreamLookback = 10 * time.Second
+
+// kitchenStreamBuffer changed orders buffered per kitchen display; a display falling further behind is dropped
+// and reloads its tickets when it reconnects
+const kitchenStreamBuffer = 256
+
+// KitchenTicketItem order item on a kitchen ticket
+type KitchenTicketItem struct {
+	OrderItemID uint                `json:"order_item_id"`
+	MenuItemID  uint                `json:"menu_item_id"`
+	Name        string              `json:"name"`
+	Category    models.MenuCategory `json:"category"`
+	Station     string              `json:"station"`
+	Quantity    int                 `json:"quantity"`
+}
+
+// KitchenTicket kitchen display view of an order
+type KitchenTicket struct {
+	OrderID              uint                `json:"order_id"`
+	Status               models.OrderStatus  `json:"status"`
+	Customer             string              `json:"customer"`
+	Items                []KitchenTicketItem `json:"items"`
+	FiredAt              time.Time           `json:"fired_at"` // When the order was confirmed and sent to the kitchen
+	PreparingAt          *time.Time          `json:"preparing_at,omitempty"`
+	ReadyAt              *time.Time          `json:"ready_at,omitempty"`
+	ElapsedSeconds       int                 `json:"elapsed_seconds"`        // Since fired; stops once the order is ready
+	StatusElapsedSeconds int                 `json:"status_elapsed_seconds"` // Since the order entered its current status
+	UpdatedAt            time.Time           `json:"updated_at"`
+}
+
+// IsActive checks if the ticket is still being worked on (shown on the display)
+func (t *KitchenTicket) IsActive() bool {
+	return slices.Contains(models.KitchenOrderStatuses, t.Status)
+}
+
+// KitchenService kitchen display tickets, station routing and bump/recall actions
+type KitchenService struct {
+	orderService OrderService
+}
+
+// Stations returns the names of every kitchen station, the default station last
+func (ks *KitchenService) Stations() []string {
+	var names []string
+	for _, station := range config.GetKitchenStations() {
+		names = append(names, station.Name)
+	}
+	if defaultStation := config.GetDefaultKitchenStation(); !slices.Contains(names, defaultStation) {
+		names = append(names, defaultStation)
+	}
+	return names
+}
+
+// StationFor returns the station items of a menu category are routed to
+func (ks *KitchenService) StationFor(category models.MenuCategory) string {
+	for _, station := range config.GetKitchenStations() {
+		if slices.Contains(station.Categories, string(category)) {
+			return station.Name
+		}
+	}
+	return config.GetDefaultKitchenStation()
+}
+
+// ValidateStation checks that a station exists (empty selects every station)
+func (ks *KitchenService) ValidateStation(station string) error {
+	if station == "" || slices.Contains(ks.Stations(), station) {
+		return nil
+	}
+	return &DomainError{Status: http.StatusBadRequest, Code: ReasonUnknownKitchenStation, Message: "Unknown kitchen station"}
+}
+
+// BuildTicket builds the ticket of an order for a station (empty for every station); returns nil when none of
+// the order items are routed to the station
+// Requires OrderItems.MenuItem and User to be loaded
+func (ks *KitchenService) BuildTicket(order *models.Order, station string, now time.Time) *KitchenTicket {
+	ticket := &KitchenTicket{
+		OrderID:     order.ID,
+		Status:      order.Status,
+		Customer:    order.User.Name,
+		Items:       []KitchenTicketItem{},
+		FiredAt:     order.CreatedAt,
+		PreparingAt: order.PreparingAt,
+		ReadyAt:     order.ReadyAt,
+		UpdatedAt:   order.UpdatedAt,
+	}
+	if order.ConfirmedAt != nil {
+		ticket.FiredAt = *order.ConfirmedAt
+	}
+
+	for _, item := range order.OrderItems {
+		itemStation := ks.StationFor(item.MenuItem.Category)
+		if station != "" && itemStation != station {
+			continue
+		}
+		ticket.Items = append(ticket.Items, KitchenTicketItem{
+			OrderItemID: item.ID,
+			MenuItemID:  item.MenuItemID,
+			Name:        item.MenuItem.Name,
+			Category:    item.MenuItem.Category,
+			Station:     itemStation,
+			Quantity:    item.Quantity,
+		})
+	}
+	if len(ticket.Items) == 0 {
+		return nil
+	}
+
+	// Elapsed time stops once the kitchen is done with the order
+	end := now
+	if order.ReadyAt != nil && !ticket.IsActive() {
+		end = *order.ReadyAt
+	} else if order.CancelledAt != nil {
+		end = *order.CancelledAt
+	}
+	ticket.ElapsedSeconds = secondsBetween(ticket.FiredAt, end)
+
+	statusStart := ticket.FiredAt
+	if order.Status == models.OrderStatusPreparing && order.PreparingAt != nil {
+		statusStart = *order.PreparingAt
+	}
+	if ticket.IsActive() {
+		ticket.StatusElapsedSeconds = secondsBetween(statusStart, now)
+	}
+	return ticket
+}
+
+// secondsBetween returns the whole seconds from start to end (0 when end is before start)
+func secondsBetween(start, end time.Time) int {
+	if end.Before(start) {
+		return 0
+	}
+	return int(end.Sub(start) / time.Second)
+}
+
+// GetTickets returns the tickets of active orders for a station (empty for every station), oldest first
+// With ready set, it returns the tickets bumped to ready within the recall window instead, newest first
+func (ks *KitchenService) GetTickets(station string, ready bool) ([]KitchenTicket, error) {
+	now := time.Now()
+	query := config.DB.Preload("User").Preload("OrderItems.MenuItem")
+	if ready {
+		query = query.Where("status = ? AND ready_at >= ?", models.OrderStatusReady, now.Add(-kitchenRecallWindow)).Order("ready_at DESC")
+	} else {
+		query = query.Where("status IN ?", models.KitchenOrderStatuses).Order("confirmed_at ASC, id ASC")
+	}
+
+	var orders []models.Order
+	if err := query.Find(&orders).Error; err != nil {
+		return nil, err
+	}
+
+	tickets := []KitchenTicket{}
+	for i := range orders {
+		if ticket := ks.BuildTicket(&orders[i], station, now); ticket != nil {
+			tickets = append(tickets, *ticket)
+		}
+	}
+	return tickets, nil
+}
+
+// Bump moves an order on to its next kitchen status (confirmed to preparing, preparing to ready)
+func (ks *KitchenService) Bump(orderID, actorID uint) (*models.Order, error) {
+	return ks.changeStatus(orderID, func(tx *gorm.DB, order *models.Order) error {
+		next, ok := order.Status.BumpStatus()
+		if !ok {
+			return &DomainError{Status: http.StatusConflict, Code: ReasonInvalidStatusTransition, Message: "Only confirmed or preparing orders can be bumped"}
+		}
+		return ks.orderService.TransitionStatus(tx, order, next, actorID, "Bumped on kitchen display")
+	})
+}
+
+// Recall moves a bumped (ready) order back onto the kitchen display
+func (ks *KitchenService) Recall(orderID, actorID uint, reason string) (*models.Order, error) {
+	if reason == "" {
+		reason = "Recalled on kitchen display"
+	}
+	return ks.changeStatus(orderID, func(tx *gorm.DB, order *models.Order) error {
+		return ks.orderService.RecallStatus(tx, order, actorID, reason)
+	})
+}
+
+// changeStatus applies a status change to a locked order within a transaction
+func (ks *KitchenService) changeStatus(orderID uint, change func(tx *gorm.DB, order *models.Order) error) (*models.Order, error) {
+	var order models.Order
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Set("gorm:query_option", "FOR UPDATE").First(&order, orderID).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return &DomainError{Status: http.StatusNotFound, Code: ReasonOrderNotFound, Message: "Order not found"}
+			}
+			return err
+		}
+		if err := change(tx, &order); err != nil {
+			return err
+		}
+		return tx.Save(&order).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Load relationships for the ticket (outside transaction)
+	if err := config.DB.Preload("User").Preload("OrderItems.MenuItem").First(&order, order.ID).Error; err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
+// KitchenSubscription changed orders for a single kitchen display
+type KitchenSubscription struct {
+	Orders <-chan models.Order // Closed when the subscription is dropped or the stream stops
+	orders chan models.Order
+}
+
+// KitchenStream pushes orders that were fired to the kitchen to every subscribed kitchen display whenever they change
+// Changes are picked up from the database, so only committed changes are pushed, including those of other app instances
+type KitchenStream struct {
+	poll          time.Duration
+	mu            sync.Mutex
+	subscriptions map[*KitchenSubscription]struct{}
+	since         time.Time          // Start of the next poll window
+	pushed        map[uint]time.Time // UpdatedAt of orders pushed within the lookback, to skip repeats
+	stopped       bool
+	stop          chan struct{}
+	done          chan struct{}
+	stopOnce      sync.Once
+}
+
+// sharedKitchenStream kitchen stream shared by the API and the server lifecycle
+var sharedKitchenStream = NewKitchenStream(time.Second)
+
+// SharedKitchenStream returns the kitchen stream kitchen displays subscribe to
+func SharedKitchenStream() *KitchenStream {
+	return sharedKitchenStream
+}
+
+// NewKitchenStream creates a stream that checks for changed orders every poll
+func NewKitchenStream(poll time.Duration) *KitchenStream {
+	return &KitchenStream{
+		poll:          poll,
+		subscriptions: map[*KitchenSubscription]struct{}{},
+		pushed:        map[uint]time.Time{},
+		stop:          make(chan struct{}),
+		done:          make(chan struct{}),
+	}
+}
+
+// Start starts pushing orders changed from now on
+func (ks *KitchenStream) Start() {
+	ks.mu.Lock()
+	ks.since = time.Now()
+	ks.mu.Unlock()
+
+	go ks.loop()
+}
+
+// Stop stops pushing orders, ends every subscription and waits up to timeout for the current poll
+func (ks *KitchenStream) Stop(timeout time.Duration) {
+	ks.stopOnce.Do(func() {
+		close(ks.stop)
+
+		ks.mu.Lock()
+		ks.stopped = true
+		for subscription := range ks.subscriptions {
+			close(subscription.orders)
+		}
+		ks.subscriptions = map[*KitchenSubscription]struct{}{}
+		ks.mu.Unlock()
+	})
+
+	select {
+	case <-ks.done:
+	case <-time.After(timeout):
+		log.Printf("Kitchen stream did not stop within %s", timeout)
+	}
+}
+
+// Subscribe subscribes to changed orders; call Unsubscribe once done
+func (ks *KitchenStream) Subscribe() *KitchenSubscription {
+	orders := make(chan models.Order, kitchenStreamBuffer)
+	subscription := &KitchenSubscription{Orders: orders, orders: orders}
+
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+
+	if ks.stopped {
+		close(orders)
+		return subscription
+	}
+	ks.subscriptions[subscription] = struct{}{}
+	return subscription
+}
+
+// Unsubscribe ends a subscription
+func (ks *KitchenStream) Unsubscribe(subscription *KitchenSubscription) {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+	ks.remove(subscription)
+}
+
+// remove drops a subscription and closes its channel (callers hold mu)
+func (ks *KitchenStream) remove(subscription *KitchenSubscription) {
+	if _, ok := ks.subscriptions[subscription]; !ok {
+		return
+	}
+	delete(ks.subscriptions, subscription)
+	close(subscription.orders)
+}
+
+// loop pushes changed orders every poll until the stream is stopped
+func (ks *KitchenStream) loop() {
+	defer close(ks.done)
+
+	ticker := time.NewTicker(ks.poll)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ks.stop:
+			return
+		case <-ticker.C:
+			ks.Push()
+		}
+	}
+}
+
+// Push pushes fired orders changed since the last push to every subscriber; returns the number of orders
+func (ks *KitchenStream) Push() int {
+	now := time.Now()
+	ks.mu.Lock()
+	since := ks.since.Add(-kitchenStreamLookback)
+	ks.mu.Unlock()
+
+	var orders []models.Order
+	if err := config.DB.Preload("User").Preload("OrderItems.MenuItem").
+		Where("confirmed_at IS NOT NULL AND updated_at >= ?", since).Order("updated_at ASC").
+		Find(&orders).Error; err != nil {
+		log.Printf("Failed to load changed orders: %v", err)
+		return 0
+	}
+
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+
+	ks.since = now
+	for id, updatedAt := range ks.pushed {
+		if updatedAt.Before(since) {
+			delete(ks.pushed, id)
+		}
+	}
+
+	pushed := 0
+	for _, order := range orders {
+		if updatedAt, ok := ks.pushed[order.ID]; ok && !order.UpdatedAt.After(updatedAt) {
+			continue // Already pushed this version
+		}
+		ks.pushed[order.ID] = order.UpdatedAt
+		pushed++
+
+		for subscription := range ks.subscriptions {
+			select {
+			case subscription.orders <- order:
+			default:
+				log.Printf("Dropping kitchen subscription: too far behind")
+				ks.remove(subscription)
+			}
+		}
+	}
+	return pushed
+}
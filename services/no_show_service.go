@@ -0,0 +1,27 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"restaurant-booking-backend/config"
+	"restaurant-booking-backend/models"
+)
+
+// NoShowService marks confirmed reservations whose guests never arrived as no-show
+// Runs as the mark_no_shows job of the reservation lifecycle scheduler
+type NoShowService struct {
+	lifecycleService ReservationLifecycleService
+}
+
+// MarkNoShows marks confirmed reservations that started more than the grace period ago as no-show,
+// releases their tables to the waitlist and returns how many were marked
+func (nss *NoShowService) MarkNoShows() (int, error) {
+	grace := config.GetNoShowGracePeriod()
+	cutoff := time.Now().Add(-time.Duration(grace) * time.Minute)
+
+	return nss.lifecycleService.transitionDue(models.ReservationStatusConfirmed, models.ReservationStatusNoShow, cutoff,
+		func(reservation *models.Reservation) bool {
+			return reservation.StartTime().Before(cutoff)
+		}, fmt.Sprintf("Guests did not arrive within %d minutes of the reservation start", grace))
+}
@@ -0,0 +1,217 @@
+package services
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/smtp"
+	"os"
+	"sync"
+	"time"
+
+	"restaurant-booking-backend/config"
+	"restaurant-booking-backend/models"
+)
+
+// ErrNoRecipientAddress user has no address for the channel (e.g. no email address)
+var ErrNoRecipientAddress = errors.New("user has no address for this channel")
+
+// NotificationSender delivers notifications over one channel
+type NotificationSender interface {
+	Send(user *models.User, notification *models.Notification) error
+}
+
+// NotificationSenders returns the senders of every configured channel
+// With a notification file path set, channels without settings write to that file instead
+func NotificationSenders() map[models.NotificationChannel]NotificationSender {
+	senders := map[models.NotificationChannel]NotificationSender{}
+
+	if sms := config.GetSMSConfig(); sms.APIURL != "" {
+		senders[models.NotificationChannelSMS] = &SMSSender{config: sms}
+	}
+	if smtpConfig := config.GetSMTPConfig(); smtpConfig.Host != "" {
+		senders[models.NotificationChannelEmail] = &EmailSender{config: smtpConfig}
+	}
+	if webhook := config.GetWebhookConfig(); webhook.URL != "" {
+		senders[models.NotificationChannelWebhook] = &WebhookSender{config: webhook}
+	}
+
+	if path := config.GetNotificationFilePath(); path != "" {
+		for _, channel := range models.NotificationChannels {
+			if _, ok := senders[channel]; !ok {
+				senders[channel] = &FileSender{Channel: channel, Path: path}
+			}
+		}
+	}
+
+	return senders
+}
+
+// notificationHTTPClient HTTP client of the SMS gateway and webhook
+var notificationHTTPClient = &http.Client{Timeout: 10 * time.Second}
+
+// postJSON posts a JSON body and fails on non-2xx responses
+func postJSON(url string, body []byte, headers map[string]string) error {
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := notificationHTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("%s responded with status %d", url, resp.StatusCode)
+	}
+	return nil
+}
+
+// SMSSender sends notifications as text messages through an HTTP SMS gateway
+type SMSSender struct {
+	config config.SMSConfig
+}
+
+// Send sends the notification to the user's phone number
+func (s *SMSSender) Send(user *models.User, notification *models.Notification) error {
+	if user.Phone == "" {
+		return ErrNoRecipientAddress
+	}
+
+	headers := map[string]string{}
+	if s.config.APIKey != "" {
+		headers["Authorization"] = "Bearer " + s.config.APIKey
+	}
+
+	body, err := json.Marshal(map[string]string{
+		"sender":   s.config.Sender,
+		"receptor": user.Phone,
+		"message":  notification.Message,
+	})
+	if err != nil {
+		return err
+	}
+
+	return postJSON(s.config.APIURL, body, headers)
+}
+
+// EmailSender sends notifications as plain text emails over SMTP
+type EmailSender struct {
+	config config.SMTPConfig
+}
+
+// Send sends the notification to the user's email address
+func (s *EmailSender) Send(user *models.User, notification *models.Notification) error {
+	if user.Email == "" {
+		return ErrNoRecipientAddress
+	}
+
+	// Non-ASCII subjects (e.g. Persian) are encoded as RFC 2047 words so mail clients decode them
+	subject := mime.QEncoding.Encode("utf-8", LocaleFor(user).EmailSubject(notification.Type))
+	message := fmt.Sprintf(
+		"From: %s\r\nTo: %s\r\nSubject: %s\r\nMIME-Version: 1.0\r\nContent-Type: text/plain; charset=UTF-8\r\n\r\n%s\r\n",
+		s.config.From,
+		user.Email,
+		subject,
+		notification.Message,
+	)
+
+	var auth smtp.Auth
+	if s.config.Username != "" {
+		auth = smtp.PlainAuth("", s.config.Username, s.config.Password, s.config.Host)
+	}
+
+	return smtp.SendMail(s.config.Host+":"+s.config.Port, auth, s.config.From, []string{user.Email}, []byte(message))
+}
+
+// WebhookSender posts notifications as JSON to an external endpoint (e.g. a messaging bot or CRM)
+type WebhookSender struct {
+	config config.WebhookConfig
+}
+
+// webhookPayload body posted by WebhookSender and written by FileSender
+type webhookPayload struct {
+	Channel        models.NotificationChannel `json:"channel,omitempty"`
+	NotificationID uint                       `json:"notification_id"`
+	UserID         uint                       `json:"user_id"`
+	Phone          string                     `json:"phone"`
+	Email          string                     `json:"email,omitempty"`
+	Type           models.NotificationType    `json:"type"`
+	Message        string                     `json:"message"`
+	CreatedAt      time.Time                  `json:"created_at"`
+}
+
+// newWebhookPayload builds the payload of a notification
+func newWebhookPayload(user *models.User, notification *models.Notification) webhookPayload {
+	return webhookPayload{
+		NotificationID: notification.ID,
+		UserID:         user.ID,
+		Phone:          user.Phone,
+		Email:          user.Email,
+		Type:           notification.Type,
+		Message:        notification.Message,
+		CreatedAt:      notification.CreatedAt,
+	}
+}
+
+// Send posts the notification to the webhook; the body is signed in the X-Signature header when a secret is set
+func (s *WebhookSender) Send(user *models.User, notification *models.Notification) error {
+	body, err := json.Marshal(newWebhookPayload(user, notification))
+	if err != nil {
+		return err
+	}
+
+	headers := map[string]string{}
+	if s.config.Secret != "" {
+		mac := hmac.New(sha256.New, []byte(s.config.Secret))
+		mac.Write(body)
+		headers["X-Signature"] = "sha256=" + hex.EncodeToString(mac.Sum(nil))
+	}
+
+	return postJSON(s.config.URL, body, headers)
+}
+
+// fileSenderMu serializes writes of file senders sharing a file
+var fileSenderMu sync.Mutex
+
+// FileSender appends notifications as JSON lines to a local file instead of sending them
+// Stands in for real channels in development and tests
+type FileSender struct {
+	Channel models.NotificationChannel
+	Path    string
+}
+
+// Send appends the notification to the file
+func (s *FileSender) Send(user *models.User, notification *models.Notification) error {
+	payload := newWebhookPayload(user, notification)
+	payload.Channel = s.Channel
+
+	line, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	fileSenderMu.Lock()
+	defer fileSenderMu.Unlock()
+
+	file, err := os.OpenFile(s.Path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = file.Write(append(line, '\n'))
+	return err
+}
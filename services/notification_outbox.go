@@ -0,0 +1,193 @@
+package services
+
+import (
+	"encoding/json"
+	"log"
+
+	"restaurant-booking-backend/models"
+
+	"gorm.io/gorm"
+)
+
+// Notification outbox topics
+const (
+	OutboxTopicReservationCreated       = "reservation.created"
+	OutboxTopicReservationCancelled     = "reservation.cancelled"
+	OutboxTopicReservationModified      = "reservation.modified"
+	OutboxTopicReservationStatusUpdated = "reservation.status_updated"
+	OutboxTopicReservationReminder      = "reservation.reminder"
+	OutboxTopicWaitlistOffered          = "waitlist.offered"
+	OutboxTopicWaitlistOfferExpired     = "waitlist.offer_expired"
+	OutboxTopicNotificationDelivery     = "notification.delivery"
+)
+
+// reservationEvent payload of reservation outbox topics
+type reservationEvent struct {
+	ReservationID uint                     `json:"reservation_id"`
+	Status        models.ReservationStatus `json:"status,omitempty"`   // New status, for status updates
+	Previous      *models.Reservation      `json:"previous,omitempty"` // Table, date, time and party size before a modification
+	Offset        int                      `json:"offset,omitempty"`   // Minutes before the start, for reminders
+}
+
+// waitlistEvent payload of waitlist outbox topics
+type waitlistEvent struct {
+	EntryID       uint `json:"entry_id"`
+	ReservationID uint `json:"reservation_id,omitempty"` // Reservation holding the offered tables
+}
+
+// notificationDelivery payload of notification delivery messages
+type notificationDelivery struct {
+	NotificationID uint                       `json:"notification_id"`
+	Channel        models.NotificationChannel `json:"channel"`
+}
+
+// QueueReservationCreated queues the notifications of a new reservation in tx
+func (ns *NotificationService) QueueReservationCreated(tx *gorm.DB, reservation *models.Reservation) error {
+	return ns.outboxService.Enqueue(tx, OutboxTopicReservationCreated, reservationEvent{ReservationID: reservation.ID})
+}
+
+// QueueReservationCancelled queues the notifications of a cancelled reservation in tx
+func (ns *NotificationService) QueueReservationCancelled(tx *gorm.DB, reservation *models.Reservation) error {
+	return ns.outboxService.Enqueue(tx, OutboxTopicReservationCancelled, reservationEvent{ReservationID: reservation.ID})
+}
+
+// QueueReservationModified queues the notifications of a modified reservation in tx
+func (ns *NotificationService) QueueReservationModified(tx *gorm.DB, reservation, previous *models.Reservation) error {
+	return ns.outboxService.Enqueue(tx, OutboxTopicReservationModified, reservationEvent{
+		ReservationID: reservation.ID,
+		Previous: &models.Reservation{
+			TableID:   previous.TableID,
+			Date:      previous.Date,
+			Time:      previous.Time,
+			PartySize: previous.PartySize,
+		},
+	})
+}
+
+// QueueReservationStatusUpdated queues the notification of a reservation status change in tx
+func (ns *NotificationService) QueueReservationStatusUpdated(tx *gorm.DB, reservation *models.Reservation) error {
+	return ns.outboxService.Enqueue(tx, OutboxTopicReservationStatusUpdated, reservationEvent{
+		ReservationID: reservation.ID,
+		Status:        reservation.Status,
+	})
+}
+
+// QueueReservationReminder queues the reminder of a reservation starting in offset minutes in tx
+func (ns *NotificationService) QueueReservationReminder(tx *gorm.DB, reservation *models.Reservation, offset int) error {
+	return ns.outboxService.Enqueue(tx, OutboxTopicReservationReminder, reservationEvent{
+		ReservationID: reservation.ID,
+		Offset:        offset,
+	})
+}
+
+// QueueWaitlistOffer queues the notification of a waitlist offer in tx
+func (ns *NotificationService) QueueWaitlistOffer(tx *gorm.DB, entry *models.WaitlistEntry, reservation *models.Reservation) error {
+	return ns.outboxService.Enqueue(tx, OutboxTopicWaitlistOffered, waitlistEvent{EntryID: entry.ID, ReservationID: reservation.ID})
+}
+
+// QueueWaitlistOfferExpired queues the notification of an expired waitlist offer in tx
+func (ns *NotificationService) QueueWaitlistOfferExpired(tx *gorm.DB, entry *models.WaitlistEntry) error {
+	return ns.outboxService.Enqueue(tx, OutboxTopicWaitlistOfferExpired, waitlistEvent{EntryID: entry.ID})
+}
+
+// OutboxHandlers returns the handlers of the notification outbox topics
+func (ns *NotificationService) OutboxHandlers() map[string]OutboxHandler {
+	return map[string]OutboxHandler{
+		OutboxTopicReservationCreated: reservationEventHandler(func(ns *NotificationService, reservation *models.Reservation, event *reservationEvent) error {
+			return ns.SendReservationCreatedNotification(reservation)
+		}),
+		OutboxTopicReservationCancelled: reservationEventHandler(func(ns *NotificationService, reservation *models.Reservation, event *reservationEvent) error {
+			return ns.SendReservationCancelledNotification(reservation)
+		}),
+		OutboxTopicReservationModified: reservationEventHandler(func(ns *NotificationService, reservation *models.Reservation, event *reservationEvent) error {
+			return ns.SendReservationModifiedNotification(reservation, event.Previous)
+		}),
+		OutboxTopicReservationStatusUpdated: reservationEventHandler(func(ns *NotificationService, reservation *models.Reservation, event *reservationEvent) error {
+			// Report the status the reservation changed to, even if it changed again since
+			reservation.Status = event.Status
+			return ns.SendReservationStatusUpdatedNotification(reservation)
+		}),
+		OutboxTopicReservationReminder: reservationEventHandler(func(ns *NotificationService, reservation *models.Reservation, event *reservationEvent) error {
+			return ns.SendReservationReminderNotification(reservation, event.Offset)
+		}),
+		OutboxTopicWaitlistOffered: waitlistEventHandler(func(ns *NotificationService, entry *models.WaitlistEntry, reservation *models.Reservation) error {
+			return ns.SendWaitlistOfferNotification(entry, reservation)
+		}),
+		OutboxTopicWaitlistOfferExpired: waitlistEventHandler(func(ns *NotificationService, entry *models.WaitlistEntry, reservation *models.Reservation) error {
+			return ns.SendWaitlistOfferExpiredNotification(entry)
+		}),
+		OutboxTopicNotificationDelivery: ns.handleDelivery,
+	}
+}
+
+// reservationEventHandler loads the reservation of an event and sends its notifications within the outbox transaction
+func reservationEventHandler(send func(ns *NotificationService, reservation *models.Reservation, event *reservationEvent) error) OutboxHandler {
+	return func(tx *gorm.DB, payload []byte) error {
+		var event reservationEvent
+		if err := json.Unmarshal(payload, &event); err != nil {
+			return err
+		}
+
+		var reservation models.Reservation
+		if err := tx.Unscoped().Preload("User").Preload("Table").First(&reservation, event.ReservationID).Error; err != nil {
+			return err
+		}
+
+		return send(&NotificationService{tx: tx}, &reservation, &event)
+	}
+}
+
+// waitlistEventHandler loads the waitlist entry (and held reservation) of an event and sends its
+// notification within the outbox transaction
+func waitlistEventHandler(send func(ns *NotificationService, entry *models.WaitlistEntry, reservation *models.Reservation) error) OutboxHandler {
+	return func(tx *gorm.DB, payload []byte) error {
+		var event waitlistEvent
+		if err := json.Unmarshal(payload, &event); err != nil {
+			return err
+		}
+
+		var entry models.WaitlistEntry
+		if err := tx.Unscoped().First(&entry, event.EntryID).Error; err != nil {
+			return err
+		}
+
+		var reservation *models.Reservation
+		if event.ReservationID != 0 {
+			reservation = &models.Reservation{}
+			if err := tx.Unscoped().Preload("Table").First(reservation, event.ReservationID).Error; err != nil {
+				return err
+			}
+		}
+
+		return send(&NotificationService{tx: tx}, &entry, reservation)
+	}
+}
+
+// handleDelivery sends a stored notification over one channel
+// Notifications the user deleted, channels that are no longer configured and users without an
+// address for the channel are skipped
+func (ns *NotificationService) handleDelivery(tx *gorm.DB, payload []byte) error {
+	var delivery notificationDelivery
+	if err := json.Unmarshal(payload, &delivery); err != nil {
+		return err
+	}
+
+	var notification models.Notification
+	if err := tx.Preload("User").First(&notification, delivery.NotificationID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil
+		}
+		return err
+	}
+
+	sender, ok := NotificationSenders()[delivery.Channel]
+	if !ok {
+		log.Printf("Skipped notification %d: channel %s is not configured", notification.ID, delivery.Channel)
+		return nil
+	}
+
+	if err := sender.Send(&notification.User, &notification); err != nil && err != ErrNoRecipientAddress {
+		return err
+	}
+	return nil
+}
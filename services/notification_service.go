@@ -1,132 +1,329 @@
 package services
 
 import (
-	"fmt"
 	"log"
 
 	"restaurant-booking-backend/config"
 	"restaurant-booking-backend/models"
+	"restaurant-booking-backend/utils"
+
+	"gorm.io/gorm"
 )
 
 // NotificationService notification service
-type NotificationService struct{}
+// Notifications are stored in the inbox right away and delivered over external channels through the outbox
+type NotificationService struct {
+	tx              *gorm.DB // Transaction to write notifications in (nil for none)
+	outboxService   OutboxService
+	templateService NotificationTemplateService
+}
+
+// withTx returns a notification service writing in tx
+func (ns *NotificationService) withTx(tx *gorm.DB) *NotificationService {
+	return &NotificationService{tx: tx}
+}
+
+// db returns the database handle notifications are written with
+func (ns *NotificationService) db() *gorm.DB {
+	if ns.tx != nil {
+		return ns.tx
+	}
+	return config.DB
+}
 
 // SendNotification sends a notification to a user
+// The notification is stored together with an outbox message per channel the user receives this type of
+// notifications over; the outbox worker delivers them
 func (ns *NotificationService) SendNotification(userID uint, message string, notificationType models.NotificationType) error {
-	// Create notification in database
-	notification := models.Notification{
-		UserID:  userID,
-		Message: message,
-		Type:    notificationType,
+	return ns.db().Transaction(func(tx *gorm.DB) error {
+		// Create notification in database
+		notification := models.Notification{
+			UserID:  userID,
+			Message: message,
+			Type:    notificationType,
+		}
+
+		if err := tx.Create(&notification).Error; err != nil {
+			log.Printf("Failed to create notification: %v", err)
+			return err
+		}
+
+		log.Printf("[NOTIFICATION] User ID: %d | Type: %s | Message: %s", userID, notificationType, message)
+
+		channels, err := ns.withTx(tx).GetChannels(userID, notificationType)
+		if err != nil {
+			return err
+		}
+
+		senders := NotificationSenders()
+		for _, channel := range channels {
+			if _, ok := senders[channel]; !ok {
+				continue // Channel is not configured
+			}
+			if err := ns.outboxService.Enqueue(tx, OutboxTopicNotificationDelivery, notificationDelivery{
+				NotificationID: notification.ID,
+				Channel:        channel,
+			}); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// GetChannels returns the channels a user receives a type of notifications over
+func (ns *NotificationService) GetChannels(userID uint, notificationType models.NotificationType) ([]models.NotificationChannel, error) {
+	var preference models.NotificationPreference
+	err := ns.db().Where("user_id = ? AND type = ?", userID, notificationType).First(&preference).Error
+	if err == gorm.ErrRecordNotFound {
+		return models.DefaultNotificationChannels, nil
+	}
+	if err != nil {
+		return nil, err
 	}
+	return preference.ChannelList(), nil
+}
 
-	if err := config.DB.Create(&notification).Error; err != nil {
-		log.Printf("Failed to create notification: %v", err)
-		return err
+// GetPreferences returns the channels of every notification type for a user
+func (ns *NotificationService) GetPreferences(userID uint) (map[models.NotificationType][]models.NotificationChannel, error) {
+	var preferences []models.NotificationPreference
+	if err := ns.db().Where("user_id = ?", userID).Find(&preferences).Error; err != nil {
+		return nil, err
 	}
 
-	// For MVP: Log to console
-	// TODO: Later can be replaced with email or WebSocket
-	log.Printf("[NOTIFICATION] User ID: %d | Type: %s | Message: %s", userID, notificationType, message)
+	result := make(map[models.NotificationType][]models.NotificationChannel, len(models.NotificationTypes))
+	for _, notificationType := range models.NotificationTypes {
+		result[notificationType] = models.DefaultNotificationChannels
+	}
+	for i := range preferences {
+		result[preferences[i].Type] = preferences[i].ChannelList()
+	}
+	return result, nil
+}
 
-	return nil
+// UpdatePreferences sets the channels of the given notification types for a user
+func (ns *NotificationService) UpdatePreferences(userID uint, preferences map[models.NotificationType][]models.NotificationChannel) error {
+	return ns.db().Transaction(func(tx *gorm.DB) error {
+		for notificationType, channels := range preferences {
+			var preference models.NotificationPreference
+			if err := tx.Where(models.NotificationPreference{UserID: userID, Type: notificationType}).
+				FirstOrInit(&preference).Error; err != nil {
+				return err
+			}
+			preference.SetChannels(channels)
+			if err := tx.Save(&preference).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// GetNotificationsAfter returns up to limit notifications of a user with an ID above afterID, oldest first
+// Lets stream clients catch up on what they missed while disconnected
+// Stops before notifications that may still be committing, which the stream pushes once they settle
+func (ns *NotificationService) GetNotificationsAfter(userID uint, afterID uint, limit int) ([]models.Notification, error) {
+	settled, err := settledID(ns.db(), &models.Notification{})
+	if err != nil {
+		return nil, err
+	}
+
+	var notifications []models.Notification
+	err = ns.db().Where("user_id = ? AND id > ? AND id <= ?", userID, afterID, settled).Order("id ASC").Limit(limit).
+		Find(&notifications).Error
+	return notifications, err
+}
+
+// GetLatestNotificationID returns the ID of the newest settled notification of a user (0 when there are none)
+// Notifications that may still be committing are left out, so they are pushed once they show up
+func (ns *NotificationService) GetLatestNotificationID(userID uint) (uint, error) {
+	settled, err := settledID(ns.db(), &models.Notification{})
+	if err != nil {
+		return 0, err
+	}
+
+	var id uint
+	err = ns.db().Model(&models.Notification{}).Where("user_id = ? AND id <= ?", userID, settled).
+		Select("COALESCE(MAX(id), 0)").Scan(&id).Error
+	return id, err
+}
+
+// addConfirmationLink adds the link to confirm (or cancel) a pending reservation without logging in
+func addConfirmationLink(reservation *models.Reservation, data *NotificationData) {
+	if reservation.Status != models.ReservationStatusPending {
+		return
+	}
+	link, err := ConfirmationLink(reservation)
+	if err != nil {
+		log.Printf("Failed to create confirmation link of reservation %d: %v", reservation.ID, err)
+		return
+	}
+	data.ConfirmationLink = link
+	data.ConfirmationDeadline = ConfirmationDeadline(reservation).In(utils.RestaurantLocation())
 }
 
 // SendReservationCreatedNotification sends notification when reservation is created
 func (ns *NotificationService) SendReservationCreatedNotification(reservation *models.Reservation) error {
 	// Load relationships if not loaded
 	if reservation.User.ID == 0 {
-		config.DB.Preload("User").Preload("Table").First(reservation, reservation.ID)
+		ns.db().Preload("User").Preload("Table").First(reservation, reservation.ID)
 	}
 
 	// Notification to customer
-	customerMessage := fmt.Sprintf(
-		"Your reservation for table #%d on %s at %s has been created successfully. Status: %s",
-		reservation.Table.Number,
-		reservation.Date.Format("2006-01-02"),
-		reservation.Time.Format("15:04"),
-		reservation.Status,
-	)
-
-	if err := ns.SendNotification(reservation.UserID, customerMessage, models.NotificationTypeReservation); err != nil {
+	data := reservationNotificationData(reservation)
+	addConfirmationLink(reservation, &data)
+
+	if err := ns.notifyUser(reservation.UserID, models.NotificationEventReservationCreated, data); err != nil {
 		return err
 	}
 
 	// Notification to all admins
-	var admins []models.User
-	if err := config.DB.Where("role = ?", models.RoleAdmin).Find(&admins).Error; err == nil {
-		adminMessage := fmt.Sprintf(
-			"New reservation created: User %s (ID: %d) reserved table #%d on %s at %s",
-			reservation.User.Name,
-			reservation.UserID,
-			reservation.Table.Number,
-			reservation.Date.Format("2006-01-02"),
-			reservation.Time.Format("15:04"),
-		)
-
-		for _, admin := range admins {
-			ns.SendNotification(admin.ID, adminMessage, models.NotificationTypeReservation)
-		}
-	}
-
-	return nil
+	return ns.notifyAdmins(models.NotificationEventReservationCreatedAdmin, data)
 }
 
 // SendReservationCancelledNotification sends notification when reservation is cancelled
 func (ns *NotificationService) SendReservationCancelledNotification(reservation *models.Reservation) error {
 	// Load relationships if not loaded
 	if reservation.User.ID == 0 {
-		config.DB.Preload("User").Preload("Table").First(reservation, reservation.ID)
+		ns.db().Preload("User").Preload("Table").First(reservation, reservation.ID)
 	}
 
 	// Notification to customer
-	customerMessage := fmt.Sprintf(
-		"Your reservation for table #%d on %s at %s has been cancelled.",
-		reservation.Table.Number,
-		reservation.Date.Format("2006-01-02"),
-		reservation.Time.Format("15:04"),
-	)
-
-	if err := ns.SendNotification(reservation.UserID, customerMessage, models.NotificationTypeReservation); err != nil {
+	data := reservationNotificationData(reservation)
+	if err := ns.notifyUser(reservation.UserID, models.NotificationEventReservationCancelled, data); err != nil {
 		return err
 	}
 
 	// Notification to all admins
-	var admins []models.User
-	if err := config.DB.Where("role = ?", models.RoleAdmin).Find(&admins).Error; err == nil {
-		adminMessage := fmt.Sprintf(
-			"Reservation cancelled: User %s (ID: %d) cancelled reservation for table #%d on %s at %s",
-			reservation.User.Name,
-			reservation.UserID,
-			reservation.Table.Number,
-			reservation.Date.Format("2006-01-02"),
-			reservation.Time.Format("15:04"),
-		)
-
-		for _, admin := range admins {
-			ns.SendNotification(admin.ID, adminMessage, models.NotificationTypeReservation)
-		}
-	}
-
-	return nil
+	return ns.notifyAdmins(models.NotificationEventReservationCancelledAdmin, data)
 }
 
 // SendReservationStatusUpdatedNotification sends notification when reservation status is updated
 func (ns *NotificationService) SendReservationStatusUpdatedNotification(reservation *models.Reservation) error {
 	// Load relationships if not loaded
 	if reservation.User.ID == 0 {
-		config.DB.Preload("User").Preload("Table").First(reservation, reservation.ID)
+		ns.db().Preload("User").Preload("Table").First(reservation, reservation.ID)
 	}
 
 	// Notification to customer
-	customerMessage := fmt.Sprintf(
-		"Your reservation for table #%d on %s at %s has been updated. New status: %s",
-		reservation.Table.Number,
-		reservation.Date.Format("2006-01-02"),
-		reservation.Time.Format("15:04"),
-		reservation.Status,
-	)
+	return ns.notifyUser(reservation.UserID, models.NotificationEventReservationStatusUpdated, reservationNotificationData(reservation))
+}
+
+// SendWaitlistOfferNotification sends notification when a freed table is held for a waitlisted customer
+func (ns *NotificationService) SendWaitlistOfferNotification(entry *models.WaitlistEntry, reservation *models.Reservation) error {
+	// Load relationships if not loaded
+	if reservation.Table.ID == 0 {
+		ns.db().Preload("Table").First(reservation, reservation.ID)
+	}
 
-	return ns.SendNotification(reservation.UserID, customerMessage, models.NotificationTypeReservation)
+	data := NotificationData{
+		TableNumber:    reservation.Table.Number,
+		PartySize:      entry.PartySize,
+		Date:           entry.Date,
+		Time:           entry.Time,
+		OfferExpiresAt: entry.OfferExpiresAt.In(utils.RestaurantLocation()),
+	}
+
+	return ns.notifyUser(entry.UserID, models.NotificationEventWaitlistOffer, data)
 }
 
+// SendWaitlistOfferExpiredNotification sends notification when a waitlist offer was not claimed in time
+func (ns *NotificationService) SendWaitlistOfferExpiredNotification(entry *models.WaitlistEntry) error {
+	data := NotificationData{
+		PartySize: entry.PartySize,
+		Date:      entry.Date,
+		Time:      entry.Time,
+	}
+
+	return ns.notifyUser(entry.UserID, models.NotificationEventWaitlistOfferExpired, data)
+}
+
+// SendReservationModifiedNotification sends notification when the date, time, table or party size of a reservation is changed
+func (ns *NotificationService) SendReservationModifiedNotification(reservation *models.Reservation, previous *models.Reservation) error {
+	// Load relationships if not loaded
+	if reservation.User.ID == 0 {
+		ns.db().Preload("User").Preload("Table").First(reservation, reservation.ID)
+	}
+	if previous.Table.ID == 0 {
+		ns.db().First(&previous.Table, previous.TableID)
+	}
+
+	// Notification to customer
+	data := reservationNotificationData(reservation)
+	data.PreviousTableNumber = previous.Table.Number
+	data.PreviousDate = previous.Date
+	data.PreviousTime = previous.Time
+	data.PreviousPartySize = previous.PartySize
+
+	// Links sent before the change no longer work
+	addConfirmationLink(reservation, &data)
+
+	if err := ns.notifyUser(reservation.UserID, models.NotificationEventReservationModified, data); err != nil {
+		return err
+	}
+
+	// Notification to all admins
+	return ns.notifyAdmins(models.NotificationEventReservationModifiedAdmin, data)
+}
+
+// SendReservationReminderNotification reminds the customer of a reservation starting in offset minutes
+func (ns *NotificationService) SendReservationReminderNotification(reservation *models.Reservation, offset int) error {
+	// Load relationships if not loaded
+	if reservation.Table.ID == 0 {
+		ns.db().Preload("Table").First(reservation, reservation.ID)
+	}
+
+	data := reservationNotificationData(reservation)
+	data.ReminderOffset = offset
+
+	return ns.notifyUser(reservation.UserID, models.NotificationEventReservationReminder, data)
+}
+
+// reservationNotificationData returns the template values of a reservation
+func reservationNotificationData(reservation *models.Reservation) NotificationData {
+	return NotificationData{
+		CustomerName:  reservation.User.Name,
+		CustomerID:    reservation.UserID,
+		TableNumber:   reservation.Table.Number,
+		Date:          reservation.Date,
+		Time:          reservation.Time,
+		PartySize:     reservation.PartySize,
+		Status:        string(reservation.Status),
+		DepositAmount: reservation.DepositAmount,
+	}
+}
+
+// notify sends the message of an event to a user as the notification type of the event, written in the user's
+// language and calendar
+func (ns *NotificationService) notify(user *models.User, event models.NotificationEvent, data NotificationData) error {
+	message, err := ns.templateService.Render(ns.db(), event, LocaleFor(user), data)
+	if err != nil {
+		return err
+	}
+	return ns.SendNotification(user.ID, message, event.Type())
+}
+
+// notifyUser sends the message of an event to the user with the given ID
+func (ns *NotificationService) notifyUser(userID uint, event models.NotificationEvent, data NotificationData) error {
+	var user models.User
+	if err := ns.db().First(&user, userID).Error; err != nil {
+		return err
+	}
+	return ns.notify(&user, event, data)
+}
+
+// notifyAdmins sends the message of an event to every admin
+func (ns *NotificationService) notifyAdmins(event models.NotificationEvent, data NotificationData) error {
+	var admins []models.User
+	if err := ns.db().Where("role = ?", models.RoleAdmin).Find(&admins).Error; err != nil {
+		return err
+	}
+
+	for i := range admins {
+		if err := ns.notify(&admins[i], event, data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,195 @@
+package services
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"restaurant-booking-backend/config"
+	"restaurant-booking-backend/models"
+)
+
+// notificationStreamBuffer notifications buffered per subscriber; a subscriber falling further behind
+// is dropped and resumes from its last received ID when it reconnects
+const notificationStreamBuffer = 64
+
+// notificationStreamBatchSize maximum number of notifications loaded per poll
+const notificationStreamBatchSize = 500
+
+// NotificationSubscription live notifications of a single user
+type NotificationSubscription struct {
+	UserID        uint
+	Notifications <-chan models.Notification // Closed when the subscription is dropped or the stream stops
+	notifications chan models.Notification
+}
+
+// NotificationStream pushes newly stored notifications to subscribed users
+// Notifications are picked up from the database rather than when they are sent, so only committed
+// notifications are pushed, including those created by other app instances
+type NotificationStream struct {
+	poll          time.Duration
+	mu            sync.Mutex
+	subscriptions map[uint]map[*NotificationSubscription]struct{}
+	lastID        uint // Notifications up to this ID were pushed; later ones are pushed in ID order
+	stopped       bool
+	stop          chan struct{}
+	done          chan struct{}
+	stopOnce      sync.Once
+}
+
+// sharedNotificationStream notification stream shared by the API and the server lifecycle
+var sharedNotificationStream = NewNotificationStream(time.Second)
+
+// SharedNotificationStream returns the notification stream clients subscribe to
+func SharedNotificationStream() *NotificationStream {
+	return sharedNotificationStream
+}
+
+// NewNotificationStream creates a stream that checks for new notifications every poll
+func NewNotificationStream(poll time.Duration) *NotificationStream {
+	return &NotificationStream{
+		poll:          poll,
+		subscriptions: map[uint]map[*NotificationSubscription]struct{}{},
+		stop:          make(chan struct{}),
+		done:          make(chan struct{}),
+	}
+}
+
+// Start starts pushing notifications created from now on
+func (ns *NotificationStream) Start() error {
+	lastID, err := settledID(config.DB, &models.Notification{})
+	if err != nil {
+		return err
+	}
+
+	ns.mu.Lock()
+	ns.lastID = lastID
+	ns.mu.Unlock()
+
+	go ns.loop()
+	return nil
+}
+
+// Stop stops pushing notifications, ends every subscription and waits up to timeout for the current poll
+func (ns *NotificationStream) Stop(timeout time.Duration) {
+	ns.stopOnce.Do(func() {
+		close(ns.stop)
+
+		ns.mu.Lock()
+		ns.stopped = true
+		for _, subscriptions := range ns.subscriptions {
+			for subscription := range subscriptions {
+				close(subscription.notifications)
+			}
+		}
+		ns.subscriptions = map[uint]map[*NotificationSubscription]struct{}{}
+		ns.mu.Unlock()
+	})
+
+	select {
+	case <-ns.done:
+	case <-time.After(timeout):
+		log.Printf("Notification stream did not stop within %s", timeout)
+	}
+}
+
+// Subscribe subscribes to the new notifications of a user; call Unsubscribe once done
+func (ns *NotificationStream) Subscribe(userID uint) *NotificationSubscription {
+	notifications := make(chan models.Notification, notificationStreamBuffer)
+	subscription := &NotificationSubscription{
+		UserID:        userID,
+		Notifications: notifications,
+		notifications: notifications,
+	}
+
+	ns.mu.Lock()
+	defer ns.mu.Unlock()
+
+	if ns.stopped {
+		close(notifications)
+		return subscription
+	}
+	if ns.subscriptions[userID] == nil {
+		ns.subscriptions[userID] = map[*NotificationSubscription]struct{}{}
+	}
+	ns.subscriptions[userID][subscription] = struct{}{}
+	return subscription
+}
+
+// Unsubscribe ends a subscription
+func (ns *NotificationStream) Unsubscribe(subscription *NotificationSubscription) {
+	ns.mu.Lock()
+	defer ns.mu.Unlock()
+	ns.remove(subscription)
+}
+
+// remove drops a subscription and closes its channel (callers hold mu)
+func (ns *NotificationStream) remove(subscription *NotificationSubscription) {
+	subscriptions := ns.subscriptions[subscription.UserID]
+	if _, ok := subscriptions[subscription]; !ok {
+		return
+	}
+	delete(subscriptions, subscription)
+	if len(subscriptions) == 0 {
+		delete(ns.subscriptions, subscription.UserID)
+	}
+	close(subscription.notifications)
+}
+
+// loop pushes new notifications every poll until the stream is stopped
+func (ns *NotificationStream) loop() {
+	defer close(ns.done)
+
+	ticker := time.NewTicker(ns.poll)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ns.stop:
+			return
+		case <-ticker.C:
+			ns.Push()
+		}
+	}
+}
+
+// Push pushes notifications created since the last push to their subscribers; returns the number pushed
+func (ns *NotificationStream) Push() int {
+	ns.mu.Lock()
+	lastID := ns.lastID
+	ns.mu.Unlock()
+
+	// Stop before notifications that may still be committing, so none are skipped when they show up out of ID order
+	settled, err := settledID(config.DB, &models.Notification{})
+	if err != nil {
+		log.Printf("Failed to load new notifications: %v", err)
+		return 0
+	}
+
+	var notifications []models.Notification
+	if err := config.DB.Where("id > ? AND id <= ?", lastID, settled).Order("id ASC").Limit(notificationStreamBatchSize).
+		Find(&notifications).Error; err != nil {
+		log.Printf("Failed to load new notifications: %v", err)
+		return 0
+	}
+
+	ns.mu.Lock()
+	defer ns.mu.Unlock()
+
+	pushed := 0
+	for _, notification := range notifications {
+		if notification.ID > ns.lastID {
+			ns.lastID = notification.ID
+		}
+		for subscription := range ns.subscriptions[notification.UserID] {
+			select {
+			case subscription.notifications <- notification:
+				pushed++
+			default:
+				log.Printf("Dropping notification subscription of user %d: too far behind", notification.UserID)
+				ns.remove(subscription)
+			}
+		}
+	}
+	return pushed
+}
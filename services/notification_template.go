@@ -0,0 +1,398 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"text/template"
+	"time"
+
+	"restaurant-booking-backend/config"
+	"restaurant-booking-backend/models"
+	"restaurant-booking-backend/utils"
+
+	"gorm.io/gorm"
+)
+
+// Notification template reason codes
+const (
+	ReasonUnknownNotificationEvent    = "unknown_notification_event"
+	ReasonUnsupportedLanguage         = "unsupported_language"
+	ReasonInvalidNotificationTemplate = "invalid_notification_template"
+)
+
+// Locale language and calendar a notification is written in
+type Locale struct {
+	Language models.Language
+	Calendar models.Calendar
+}
+
+// DefaultLanguage returns the notification language of users who have not chosen one
+func DefaultLanguage() models.Language {
+	language := models.Language(config.GetDefaultLanguage())
+	if !language.IsValid() {
+		return models.LanguageEnglish
+	}
+	return language
+}
+
+// LocaleFor returns the locale of the notifications of a user
+// Users without a calendar get the Jalali calendar in Persian and the Gregorian calendar otherwise
+func LocaleFor(user *models.User) Locale {
+	language := user.Language
+	if !language.IsValid() {
+		language = DefaultLanguage()
+	}
+
+	calendar := user.Calendar
+	if !calendar.IsValid() {
+		calendar = models.CalendarGregorian
+		if language == models.LanguagePersian {
+			calendar = models.CalendarJalali
+		}
+	}
+
+	return Locale{Language: language, Calendar: calendar}
+}
+
+// Date formats the date of t, e.g. 2024-03-20 in English or ۱۴۰۳/۰۱/۰۱ in Persian
+func (l Locale) Date(t time.Time) string {
+	year, month, day := t.Year(), int(t.Month()), t.Day()
+	if l.Calendar == models.CalendarJalali {
+		year, month, day = utils.ToJalali(t)
+	}
+
+	separator := "-"
+	if l.Language == models.LanguagePersian {
+		separator = "/"
+	}
+	return l.digits(fmt.Sprintf("%04d%s%02d%s%02d", year, separator, month, separator, day))
+}
+
+// Time formats the clock time of t
+func (l Locale) Time(t time.Time) string {
+	return l.digits(t.Format("15:04"))
+}
+
+// DateTime formats the date and clock time of t
+func (l Locale) DateTime(t time.Time) string {
+	return l.Date(t) + " " + l.Time(t)
+}
+
+// Number formats a whole number
+func (l Locale) Number(n interface{}) string {
+	return l.digits(fmt.Sprint(n))
+}
+
+// Amount formats an amount of money
+func (l Locale) Amount(amount float64) string {
+	return l.digits(fmt.Sprintf("%.0f", amount))
+}
+
+// Duration formats a number of minutes as e.g. "2 hours" or "1 day"
+func (l Locale) Duration(minutes int) string {
+	unit, size := "minute", 1
+	switch {
+	case minutes%1440 == 0:
+		unit, size = "day", 1440
+	case minutes%60 == 0:
+		unit, size = "hour", 60
+	}
+	count := minutes / size
+
+	if l.Language == models.LanguagePersian {
+		units := map[string]string{"minute": "دقیقه", "hour": "ساعت", "day": "روز"}
+		return l.digits(fmt.Sprintf("%d %s", count, units[unit]))
+	}
+	if count == 1 {
+		return "1 " + unit
+	}
+	return fmt.Sprintf("%d %ss", count, unit)
+}
+
+// Status returns the label of a reservation status
+func (l Locale) Status(status string) string {
+	if label, ok := statusLabels[l.Language][status]; ok {
+		return label
+	}
+	return status
+}
+
+// EmailSubject returns the email subject of a notification type in the language of the locale
+func (l Locale) EmailSubject(notificationType models.NotificationType) string {
+	subjects, ok := emailSubjects[l.Language]
+	if !ok {
+		subjects = emailSubjects[models.LanguageEnglish]
+	}
+	if subject, ok := subjects[notificationType]; ok {
+		return subject
+	}
+	return subjects[models.NotificationTypeSystem]
+}
+
+// digits writes the digits of s in the script of the language
+func (l Locale) digits(s string) string {
+	if l.Language == models.LanguagePersian {
+		return utils.PersianDigits(s)
+	}
+	return s
+}
+
+// funcs returns the functions notification templates of the locale can call
+func (l Locale) funcs() template.FuncMap {
+	return template.FuncMap{
+		"date":     l.Date,
+		"time":     l.Time,
+		"datetime": l.DateTime,
+		"number":   l.Number,
+		"amount":   l.Amount,
+		"duration": l.Duration,
+		"status":   l.Status,
+	}
+}
+
+// statusLabels reservation status labels per language (statuses without one are written as is)
+var statusLabels = map[models.Language]map[string]string{
+	models.LanguagePersian: {
+		string(models.ReservationStatusPending):   "در انتظار تأیید",
+		string(models.ReservationStatusConfirmed): "تأیید شده",
+		string(models.ReservationStatusSeated):    "پذیرش شده",
+		string(models.ReservationStatusCancelled): "لغو شده",
+		string(models.ReservationStatusCompleted): "انجام شده",
+		string(models.ReservationStatusNoShow):    "عدم حضور",
+	},
+}
+
+// emailSubjects email subjects of notification types per language
+var emailSubjects = map[models.Language]map[models.NotificationType]string{
+	models.LanguageEnglish: {
+		models.NotificationTypeReservation: "Your reservation",
+		models.NotificationTypePromotion:   "Special offer",
+		models.NotificationTypeSystem:      "Restaurant notification",
+	},
+	models.LanguagePersian: {
+		models.NotificationTypeReservation: "رزرو شما",
+		models.NotificationTypePromotion:   "پیشنهاد ویژه",
+		models.NotificationTypeSystem:      "اطلاعیه رستوران",
+	},
+}
+
+// NotificationData values notification templates can use
+// Instants (ConfirmationDeadline, OfferExpiresAt) are in restaurant time; Date and Time are the reservation
+// date and clock time
+type NotificationData struct {
+	CustomerName         string
+	CustomerID           uint
+	TableNumber          int
+	Date                 time.Time
+	Time                 time.Time
+	PartySize            int
+	Status               string
+	DepositAmount        float64
+	ConfirmationLink     string    // Set while the reservation awaits confirmation
+	ConfirmationDeadline time.Time // Deadline to confirm a reservation without a deposit
+	OfferExpiresAt       time.Time // Deadline to claim a waitlist offer
+	PreviousTableNumber  int       // Before a modification
+	PreviousDate         time.Time
+	PreviousTime         time.Time
+	PreviousPartySize    int
+	ReminderOffset       int // Minutes before the reservation a reminder is sent
+}
+
+// sampleNotificationData template values templates are checked against before they are saved
+var sampleNotificationData = NotificationData{
+	CustomerName:         "Sara",
+	CustomerID:           1,
+	TableNumber:          5,
+	Date:                 time.Date(2024, 3, 20, 0, 0, 0, 0, time.UTC),
+	Time:                 time.Date(0, 1, 1, 19, 30, 0, 0, time.UTC),
+	PartySize:            4,
+	Status:               string(models.ReservationStatusPending),
+	DepositAmount:        500000,
+	ConfirmationLink:     "http://localhost:3000/reservations/respond?token=sample",
+	ConfirmationDeadline: time.Date(2024, 3, 19, 19, 30, 0, 0, time.UTC),
+	OfferExpiresAt:       time.Date(2024, 3, 20, 19, 45, 0, 0, time.UTC),
+	PreviousTableNumber:  3,
+	PreviousDate:         time.Date(2024, 3, 19, 0, 0, 0, 0, time.UTC),
+	PreviousTime:         time.Date(0, 1, 1, 20, 0, 0, 0, time.UTC),
+	PreviousPartySize:    2,
+	ReminderOffset:       120,
+}
+
+// defaultNotificationTemplates built-in templates per event and language (Go text/template syntax)
+var defaultNotificationTemplates = map[models.NotificationEvent]map[models.Language]string{
+	models.NotificationEventReservationCreated: {
+		models.LanguageEnglish: `Your reservation for table #{{number .TableNumber}} on {{date .Date}} at {{time .Time}} has been created successfully. Status: {{status .Status}}.` +
+			`{{if .DepositAmount}} A deposit of {{amount .DepositAmount}} is required to confirm it.{{end}}` +
+			`{{if .ConfirmationLink}}{{if .DepositAmount}} To cancel it, visit {{.ConfirmationLink}}{{else}} Please confirm or cancel it by {{datetime .ConfirmationDeadline}}: {{.ConfirmationLink}}{{end}}{{end}}`,
+		models.LanguagePersian: `رزرو شما برای میز شماره {{number .TableNumber}} در تاریخ {{date .Date}} ساعت {{time .Time}} با موفقیت ثبت شد. وضعیت: {{status .Status}}.` +
+			`{{if .DepositAmount}} برای تأیید آن پرداخت بیعانه {{amount .DepositAmount}} لازم است.{{end}}` +
+			`{{if .ConfirmationLink}}{{if .DepositAmount}} برای لغو آن به این نشانی بروید: {{.ConfirmationLink}}{{else}} لطفاً تا {{datetime .ConfirmationDeadline}} آن را تأیید یا لغو کنید: {{.ConfirmationLink}}{{end}}{{end}}`,
+	},
+	models.NotificationEventReservationCreatedAdmin: {
+		models.LanguageEnglish: `New reservation created: User {{.CustomerName}} (ID: {{number .CustomerID}}) reserved table #{{number .TableNumber}} on {{date .Date}} at {{time .Time}}`,
+		models.LanguagePersian: `رزرو جدید: کاربر {{.CustomerName}} (شناسه: {{number .CustomerID}}) میز شماره {{number .TableNumber}} را برای تاریخ {{date .Date}} ساعت {{time .Time}} رزرو کرد`,
+	},
+	models.NotificationEventReservationCancelled: {
+		models.LanguageEnglish: `Your reservation for table #{{number .TableNumber}} on {{date .Date}} at {{time .Time}} has been cancelled.`,
+		models.LanguagePersian: `رزرو شما برای میز شماره {{number .TableNumber}} در تاریخ {{date .Date}} ساعت {{time .Time}} لغو شد.`,
+	},
+	models.NotificationEventReservationCancelledAdmin: {
+		models.LanguageEnglish: `Reservation cancelled: User {{.CustomerName}} (ID: {{number .CustomerID}}) cancelled reservation for table #{{number .TableNumber}} on {{date .Date}} at {{time .Time}}`,
+		models.LanguagePersian: `لغو رزرو: کاربر {{.CustomerName}} (شناسه: {{number .CustomerID}}) رزرو میز شماره {{number .TableNumber}} در تاریخ {{date .Date}} ساعت {{time .Time}} را لغو کرد`,
+	},
+	models.NotificationEventReservationStatusUpdated: {
+		models.LanguageEnglish: `Your reservation for table #{{number .TableNumber}} on {{date .Date}} at {{time .Time}} has been updated. New status: {{status .Status}}`,
+		models.LanguagePersian: `رزرو شما برای میز شماره {{number .TableNumber}} در تاریخ {{date .Date}} ساعت {{time .Time}} به‌روزرسانی شد. وضعیت جدید: {{status .Status}}`,
+	},
+	models.NotificationEventReservationModified: {
+		models.LanguageEnglish: `Your reservation has been changed from table #{{number .PreviousTableNumber}} on {{date .PreviousDate}} at {{time .PreviousTime}} (party of {{number .PreviousPartySize}}) to table #{{number .TableNumber}} on {{date .Date}} at {{time .Time}} (party of {{number .PartySize}}).` +
+			`{{if .ConfirmationLink}}{{if .DepositAmount}} To cancel it, visit {{.ConfirmationLink}}{{else}} Please confirm or cancel it by {{datetime .ConfirmationDeadline}}: {{.ConfirmationLink}}{{end}}{{end}}`,
+		models.LanguagePersian: `رزرو شما از میز شماره {{number .PreviousTableNumber}} در تاریخ {{date .PreviousDate}} ساعت {{time .PreviousTime}} ({{number .PreviousPartySize}} نفر) به میز شماره {{number .TableNumber}} در تاریخ {{date .Date}} ساعت {{time .Time}} ({{number .PartySize}} نفر) تغییر کرد.` +
+			`{{if .ConfirmationLink}}{{if .DepositAmount}} برای لغو آن به این نشانی بروید: {{.ConfirmationLink}}{{else}} لطفاً تا {{datetime .ConfirmationDeadline}} آن را تأیید یا لغو کنید: {{.ConfirmationLink}}{{end}}{{end}}`,
+	},
+	models.NotificationEventReservationModifiedAdmin: {
+		models.LanguageEnglish: `Reservation modified: User {{.CustomerName}} (ID: {{number .CustomerID}}) moved reservation from table #{{number .PreviousTableNumber}} on {{date .PreviousDate}} at {{time .PreviousTime}} to table #{{number .TableNumber}} on {{date .Date}} at {{time .Time}} (party of {{number .PartySize}})`,
+		models.LanguagePersian: `تغییر رزرو: کاربر {{.CustomerName}} (شناسه: {{number .CustomerID}}) رزرو را از میز شماره {{number .PreviousTableNumber}} در تاریخ {{date .PreviousDate}} ساعت {{time .PreviousTime}} به میز شماره {{number .TableNumber}} در تاریخ {{date .Date}} ساعت {{time .Time}} ({{number .PartySize}} نفر) منتقل کرد`,
+	},
+	models.NotificationEventReservationReminder: {
+		models.LanguageEnglish: `Reminder: your reservation for table #{{number .TableNumber}} is in {{duration .ReminderOffset}}, on {{date .Date}} at {{time .Time}}. We look forward to seeing you!`,
+		models.LanguagePersian: `یادآوری: رزرو شما برای میز شماره {{number .TableNumber}} {{duration .ReminderOffset}} دیگر است، در تاریخ {{date .Date}} ساعت {{time .Time}}. منتظر دیدار شما هستیم!`,
+	},
+	models.NotificationEventWaitlistOffer: {
+		models.LanguageEnglish: `Good news! Table #{{number .TableNumber}} is now available for your party of {{number .PartySize}} on {{date .Date}} at {{time .Time}}. It is held for you until {{time .OfferExpiresAt}} - claim it from your waitlist or it will be offered to the next guest.`,
+		models.LanguagePersian: `خبر خوب! میز شماره {{number .TableNumber}} برای {{number .PartySize}} نفر در تاریخ {{date .Date}} ساعت {{time .Time}} آزاد شد. این میز تا ساعت {{time .OfferExpiresAt}} برای شما نگه داشته می‌شود؛ آن را از فهرست انتظار خود بگیرید، وگرنه به مهمان بعدی پیشنهاد می‌شود.`,
+	},
+	models.NotificationEventWaitlistOfferExpired: {
+		models.LanguageEnglish: `Your waitlist offer for {{date .Date}} at {{time .Time}} has expired and the table was released.`,
+		models.LanguagePersian: `مهلت پیشنهاد فهرست انتظار شما برای تاریخ {{date .Date}} ساعت {{time .Time}} به پایان رسید و میز آزاد شد.`,
+	},
+}
+
+// defaultNotificationTemplate returns the built-in template of an event in a language, falling back to English
+func defaultNotificationTemplate(event models.NotificationEvent, language models.Language) string {
+	if body, ok := defaultNotificationTemplates[event][language]; ok {
+		return body
+	}
+	return defaultNotificationTemplates[event][models.LanguageEnglish]
+}
+
+// renderNotificationTemplate renders a template body in a locale
+func renderNotificationTemplate(body string, locale Locale, data NotificationData) (string, error) {
+	tmpl, err := template.New("notification").Funcs(locale.funcs()).Parse(body)
+	if err != nil {
+		return "", err
+	}
+
+	var message strings.Builder
+	if err := tmpl.Execute(&message, data); err != nil {
+		return "", err
+	}
+	return message.String(), nil
+}
+
+// NotificationTemplateService notification template service
+// Admins can replace the built-in template of any event and language; messages are rendered per recipient
+type NotificationTemplateService struct{}
+
+// NotificationTemplateView effective template of an event in a language
+type NotificationTemplateView struct {
+	Event     models.NotificationEvent `json:"event"`
+	Language  models.Language          `json:"language"`
+	Body      string                   `json:"body"`
+	Custom    bool                     `json:"custom"` // Edited by an admin rather than built in
+	UpdatedAt *time.Time               `json:"updated_at,omitempty"`
+}
+
+// GetTemplates returns the effective template of every event in every language
+func (ts *NotificationTemplateService) GetTemplates(db *gorm.DB) ([]NotificationTemplateView, error) {
+	var custom []models.NotificationTemplate
+	if err := db.Find(&custom).Error; err != nil {
+		return nil, err
+	}
+
+	views := make([]NotificationTemplateView, 0, len(models.NotificationEvents)*len(models.Languages))
+	for _, event := range models.NotificationEvents {
+		for _, language := range models.Languages {
+			view := NotificationTemplateView{
+				Event:    event,
+				Language: language,
+				Body:     defaultNotificationTemplate(event, language),
+			}
+			for i := range custom {
+				if custom[i].Event == event && custom[i].Language == language {
+					view.Body = custom[i].Body
+					view.Custom = true
+					view.UpdatedAt = &custom[i].UpdatedAt
+				}
+			}
+			views = append(views, view)
+		}
+	}
+	return views, nil
+}
+
+// SetTemplate replaces the template of an event in a language
+// The body is checked by rendering it with sample values, so a broken template is never stored
+func (ts *NotificationTemplateService) SetTemplate(db *gorm.DB, event models.NotificationEvent, language models.Language, body string) (*models.NotificationTemplate, error) {
+	if err := ts.validateKey(event, language); err != nil {
+		return nil, err
+	}
+
+	if strings.TrimSpace(body) == "" {
+		return nil, newDomainError(ReasonInvalidNotificationTemplate, "Template body is required")
+	}
+	if _, err := renderNotificationTemplate(body, Locale{Language: language, Calendar: models.CalendarGregorian}, sampleNotificationData); err != nil {
+		return nil, newDomainError(ReasonInvalidNotificationTemplate, "Invalid template: "+err.Error())
+	}
+
+	var tmpl models.NotificationTemplate
+	if err := db.Unscoped().Where(models.NotificationTemplate{Event: event, Language: language}).
+		FirstOrInit(&tmpl).Error; err != nil {
+		return nil, err
+	}
+	tmpl.Body = body
+	tmpl.DeletedAt = gorm.DeletedAt{}
+	if err := db.Unscoped().Save(&tmpl).Error; err != nil {
+		return nil, err
+	}
+	return &tmpl, nil
+}
+
+// ResetTemplate removes the admin edited template of an event in a language, restoring the built-in one
+func (ts *NotificationTemplateService) ResetTemplate(db *gorm.DB, event models.NotificationEvent, language models.Language) error {
+	if err := ts.validateKey(event, language); err != nil {
+		return err
+	}
+	return db.Unscoped().Where("event = ? AND language = ?", event, language).Delete(&models.NotificationTemplate{}).Error
+}
+
+// Render renders the message of an event in a locale
+// Uses the admin edited template of the language when there is one, otherwise the built-in template
+func (ts *NotificationTemplateService) Render(db *gorm.DB, event models.NotificationEvent, locale Locale, data NotificationData) (string, error) {
+	var custom models.NotificationTemplate
+	err := db.Where("event = ? AND language = ?", event, locale.Language).First(&custom).Error
+	if err == nil {
+		message, err := renderNotificationTemplate(custom.Body, locale, data)
+		if err == nil {
+			return message, nil
+		}
+		log.Printf("Failed to render %s template of notification event %s, using the built-in one: %v", locale.Language, event, err)
+	} else if err != gorm.ErrRecordNotFound {
+		return "", err
+	}
+
+	return renderNotificationTemplate(defaultNotificationTemplate(event, locale.Language), locale, data)
+}
+
+// validateKey checks the event and language of a template
+func (ts *NotificationTemplateService) validateKey(event models.NotificationEvent, language models.Language) error {
+	if !event.IsValid() {
+		return &DomainError{Status: http.StatusNotFound, Code: ReasonUnknownNotificationEvent, Message: "Unknown notification event"}
+	}
+	if !language.IsValid() {
+		return newDomainError(ReasonUnsupportedLanguage, "Unsupported language")
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package services
+
+import (
+	"time"
+
+	"restaurant-booking-backend/config"
+	"restaurant-booking-backend/models"
+
+	"gorm.io/gorm"
+)
+
+// ResolveOccupancy fills the derived Occupancy of each table at the given instant:
+// maintenance if out of service, occupied if an active reservation is in progress,
+// reserved if one starts within the reserved horizon, otherwise available
+func (rs *ReservationService) ResolveOccupancy(tx *gorm.DB, tables []models.Table, at time.Time) error {
+	if len(tables) == 0 {
+		return nil
+	}
+
+	horizon := time.Duration(config.GetTableReservedHorizon()) * time.Minute
+
+	tableIDs := make([]uint, 0, len(tables))
+	for _, table := range tables {
+		tableIDs = append(tableIDs, table.ID)
+	}
+	reservations, err := rs.findActiveReservations(tx, tableIDs, at, at.Add(horizon))
+	if err != nil {
+		return err
+	}
+
+	occupancy := make(map[uint]models.TableStatus)
+	for i := range reservations {
+		reservation := &reservations[i]
+		for _, tableID := range reservation.HeldTableIDs() {
+			if reservation.Overlaps(at, at.Add(time.Nanosecond)) {
+				occupancy[tableID] = models.TableStatusOccupied
+			} else if reservation.Overlaps(at, at.Add(horizon)) && occupancy[tableID] == "" {
+				occupancy[tableID] = models.TableStatusReserved
+			}
+		}
+	}
+
+	for i := range tables {
+		switch {
+		case tables[i].IsOutOfService():
+			tables[i].Occupancy = models.TableStatusMaintenance
+		case occupancy[tables[i].ID] != "":
+			tables[i].Occupancy = occupancy[tables[i].ID]
+		default:
+			tables[i].Occupancy = models.TableStatusAvailable
+		}
+	}
+
+	return nil
+}
+
+// IsTableFree checks if the table is in service and has no active reservation overlapping [start, end)
+func (rs *ReservationService) IsTableFree(tx *gorm.DB, table *models.Table, start, end time.Time) (bool, error) {
+	if table.IsOutOfService() {
+		return false, nil
+	}
+	conflict, err := rs.FindConflictingReservation(tx, []uint{table.ID}, start, end, 0)
+	if err != nil {
+		return false, err
+	}
+	return conflict == nil, nil
+}
@@ -0,0 +1,106 @@
+package services
+
+import (
+	"time"
+
+	"restaurant-booking-backend/config"
+	"restaurant-booking-backend/models"
+
+	"gorm.io/gorm"
+)
+
+// OpeningHoursService resolves when the restaurant accepts reservations
+type OpeningHoursService struct{}
+
+// ServiceWindows returns the windows in which the restaurant accepts reservations on a date
+// Priority: calendar exceptions for the date, then weekly opening hours,
+// then the default opening time from environment when no weekly hours are configured at all
+func (ohs *OpeningHoursService) ServiceWindows(tx *gorm.DB, date time.Time) ([]TimeWindow, error) {
+	day := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+
+	// Check calendar exceptions first
+	var exceptions []models.CalendarException
+	if err := tx.Where("date = ?", day).Find(&exceptions).Error; err != nil {
+		return nil, err
+	}
+	if len(exceptions) > 0 {
+		windows := []TimeWindow{}
+		for _, exception := range exceptions {
+			if exception.Type == models.CalendarExceptionClosed {
+				return []TimeWindow{}, nil
+			}
+			window, err := parseTimeWindow(day, exception.OpenTime, exception.CloseTime)
+			if err != nil {
+				return nil, err
+			}
+			windows = append(windows, window)
+		}
+		return windows, nil
+	}
+
+	// Weekly opening hours
+	var configured int64
+	if err := tx.Model(&models.OpeningHours{}).Count(&configured).Error; err != nil {
+		return nil, err
+	}
+	if configured == 0 {
+		window, err := parseTimeWindow(day, config.GetDefaultOpeningTime(), config.GetDefaultClosingTime())
+		if err != nil {
+			return nil, err
+		}
+		return []TimeWindow{window}, nil
+	}
+
+	var hours []models.OpeningHours
+	if err := tx.Where("weekday = ?", int(day.Weekday())).Order("open_time ASC").Find(&hours).Error; err != nil {
+		return nil, err
+	}
+
+	windows := []TimeWindow{}
+	for _, service := range hours {
+		window, err := parseTimeWindow(day, service.OpenTime, service.CloseTime)
+		if err != nil {
+			return nil, err
+		}
+		windows = append(windows, window)
+	}
+	return windows, nil
+}
+
+// ValidateBookingWindow checks that [start, end) falls within a single opening window
+func (ohs *OpeningHoursService) ValidateBookingWindow(tx *gorm.DB, start, end time.Time) error {
+	windows, err := ohs.ServiceWindows(tx, start)
+	if err != nil {
+		return err
+	}
+	if insideAnyWindow(windows, start, end) {
+		return nil
+	}
+
+	// Previous day's service may run past midnight
+	previousWindows, err := ohs.ServiceWindows(tx, start.AddDate(0, 0, -1))
+	if err != nil {
+		return err
+	}
+	if insideAnyWindow(previousWindows, start, end) {
+		return nil
+	}
+
+	if len(windows) == 0 {
+		return newDomainError(ReasonRestaurantClosed, "Restaurant is closed on this date")
+	}
+	return newDomainError(ReasonOutsideOpeningHours, "Reservation must be within opening hours")
+}
+
+// parseTimeWindow builds a window from "15:04" opening and closing times on a date
+func parseTimeWindow(date time.Time, openTime, closeTime string) (TimeWindow, error) {
+	opening, err := time.Parse("15:04", openTime)
+	if err != nil {
+		return TimeWindow{}, err
+	}
+	closing, err := time.Parse("15:04", closeTime)
+	if err != nil {
+		return TimeWindow{}, err
+	}
+	return newTimeWindow(date, opening, closing), nil
+}
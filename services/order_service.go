@@ -0,0 +1,67 @@
+package services
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"restaurant-booking-backend/models"
+
+	"gorm.io/gorm"
+)
+
+// OrderService order lifecycle service
+type OrderService struct {
+	history StatusHistoryService
+}
+
+// TransitionStatus moves an order to the next status if the order status state machine allows it,
+// records the time of the transition and the admin who made it, and adds it to the status history;
+// the caller saves the order within the same transaction
+// Illegal transitions are rejected with 409 Conflict and a reason code
+func (ors *OrderService) TransitionStatus(tx *gorm.DB, order *models.Order, next models.OrderStatus, actorID uint, reason string) error {
+	if !next.IsValid() {
+		return newDomainError(ReasonInvalidStatus, "Invalid order status")
+	}
+
+	if !order.Status.CanTransitionTo(next) {
+		return &DomainError{
+			Status:  http.StatusConflict,
+			Code:    ReasonInvalidStatusTransition,
+			Message: fmt.Sprintf("Cannot change order status from %s to %s", order.Status, next),
+		}
+	}
+
+	previous := order.Status
+	order.Status = next
+	order.RecordTransition(next, actorID, time.Now())
+	return ors.history.Record(tx, models.StatusHistoryEntityOrder, order.ID, string(previous), string(next), actorID, reason)
+}
+
+// RecallStatus moves a bumped order back to its previous kitchen status (ready to preparing), clears the time it
+// became ready and adds the change to the status history; the caller saves the order within the same transaction
+func (ors *OrderService) RecallStatus(tx *gorm.DB, order *models.Order, actorID uint, reason string) error {
+	previous, ok := order.Status.RecallStatus()
+	if !ok {
+		return &DomainError{
+			Status:  http.StatusConflict,
+			Code:    ReasonInvalidStatusTransition,
+			Message: fmt.Sprintf("Cannot recall order in status %s", order.Status),
+		}
+	}
+
+	current := order.Status
+	order.Status = previous
+	order.ClearTransition(current)
+	return ors.history.Record(tx, models.StatusHistoryEntityOrder, order.ID, string(current), string(previous), actorID, reason)
+}
+
+// RecordCreated records the initial status of a newly created order in the status history
+func (ors *OrderService) RecordCreated(tx *gorm.DB, order *models.Order, actorID uint, reason string) error {
+	return ors.history.Record(tx, models.StatusHistoryEntityOrder, order.ID, "", string(order.Status), actorID, reason)
+}
+
+// GetStatusHistory returns the status changes of an order, oldest first
+func (ors *OrderService) GetStatusHistory(tx *gorm.DB, orderID uint) ([]models.StatusHistory, error) {
+	return ors.history.GetHistory(tx, models.StatusHistoryEntityOrder, orderID)
+}
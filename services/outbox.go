@@ -0,0 +1,251 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"restaurant-booking-backend/config"
+	"restaurant-booking-backend/models"
+
+	"gorm.io/gorm"
+)
+
+// Notification outbox reason codes
+const (
+	ReasonOutboxMessageNotFound  = "outbox_message_not_found"
+	ReasonOutboxMessageDelivered = "outbox_message_delivered"
+)
+
+// outboxLease time a claimed message is reserved for the worker handling it; messages of a worker
+// that stopped mid-way are picked up again once it passes
+const outboxLease = 5 * time.Minute
+
+// outboxMaxRetryDelay upper bound of the exponential backoff between attempts
+const outboxMaxRetryDelay = time.Hour
+
+// outboxBatchSize maximum number of messages handled per poll
+const outboxBatchSize = 50
+
+// OutboxHandler handles the payload of an outbox message within the transaction marking it delivered
+type OutboxHandler func(tx *gorm.DB, payload []byte) error
+
+// OutboxService stores outbox messages and lets admins retry failed ones
+type OutboxService struct{}
+
+// Enqueue stores a message in the outbox; pass the transaction of the change causing it
+func (obs *OutboxService) Enqueue(tx *gorm.DB, topic string, payload interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	return tx.Create(&models.OutboxMessage{
+		Topic:         topic,
+		Payload:       string(body),
+		Status:        models.OutboxStatusPending,
+		NextAttemptAt: time.Now(),
+	}).Error
+}
+
+// Retry schedules a pending or dead message for immediate delivery with a fresh set of attempts
+func (obs *OutboxService) Retry(id uint) (*models.OutboxMessage, error) {
+	var message models.OutboxMessage
+	if err := config.DB.First(&message, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, &DomainError{Status: http.StatusNotFound, Code: ReasonOutboxMessageNotFound, Message: "Outbox message not found"}
+		}
+		return nil, err
+	}
+	if message.Status == models.OutboxStatusDelivered {
+		return nil, &DomainError{Status: http.StatusConflict, Code: ReasonOutboxMessageDelivered, Message: "Outbox message was already delivered"}
+	}
+
+	message.Status = models.OutboxStatusPending
+	message.Attempts = 0
+	message.NextAttemptAt = time.Now()
+	if err := config.DB.Save(&message).Error; err != nil {
+		return nil, err
+	}
+	return &message, nil
+}
+
+// RetryDead schedules every dead message for immediate delivery; returns the number of messages
+func (obs *OutboxService) RetryDead() (int64, error) {
+	result := config.DB.Model(&models.OutboxMessage{}).Where("status = ?", models.OutboxStatusDead).Updates(map[string]interface{}{
+		"status":          models.OutboxStatusPending,
+		"attempts":        0,
+		"next_attempt_at": time.Now(),
+	})
+	return result.RowsAffected, result.Error
+}
+
+// outboxRetryDelay returns the delay before the next attempt of a message that failed attempts times
+func outboxRetryDelay(attempts int) time.Duration {
+	delay := time.Duration(config.GetOutboxRetryDelay()) * time.Second
+	for i := 1; i < attempts && delay < outboxMaxRetryDelay; i++ {
+		delay *= 2
+	}
+	if delay > outboxMaxRetryDelay {
+		delay = outboxMaxRetryDelay
+	}
+	return delay
+}
+
+// OutboxWorker delivers outbox messages in the background, retrying failures with exponential backoff
+// Messages are claimed in the database, so several app instances can run a worker
+type OutboxWorker struct {
+	poll     time.Duration
+	handlers map[string]OutboxHandler
+	stop     chan struct{}
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+// NewOutboxWorker creates a worker that checks for due messages every poll
+func NewOutboxWorker(poll time.Duration) *OutboxWorker {
+	return &OutboxWorker{
+		poll:     poll,
+		handlers: map[string]OutboxHandler{},
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+}
+
+// Register sets the handler of a topic (call before Start)
+func (w *OutboxWorker) Register(topic string, handler OutboxHandler) {
+	w.handlers[topic] = handler
+}
+
+// Start starts delivering messages in the background
+func (w *OutboxWorker) Start() {
+	go w.loop()
+}
+
+// Stop stops picking up messages and waits up to timeout for the current batch to finish
+func (w *OutboxWorker) Stop(timeout time.Duration) {
+	w.stopOnce.Do(func() { close(w.stop) })
+
+	select {
+	case <-w.done:
+	case <-time.After(timeout):
+		log.Printf("Outbox worker did not stop within %s", timeout)
+	}
+}
+
+// loop delivers due messages every poll until the worker is stopped
+func (w *OutboxWorker) loop() {
+	defer close(w.done)
+
+	ticker := time.NewTicker(w.poll)
+	defer ticker.Stop()
+
+	for {
+		w.DeliverDue()
+		select {
+		case <-w.stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// DeliverDue handles a batch of messages whose next attempt is due; returns the number delivered
+func (w *OutboxWorker) DeliverDue() int {
+	var messages []models.OutboxMessage
+	if err := config.DB.Where("status = ? AND next_attempt_at <= ?", models.OutboxStatusPending, time.Now()).
+		Order("id ASC").Limit(outboxBatchSize).Find(&messages).Error; err != nil {
+		log.Printf("Failed to load outbox messages: %v", err)
+		return 0
+	}
+
+	delivered := 0
+	for i := range messages {
+		select {
+		case <-w.stop:
+			return delivered
+		default:
+		}
+		if w.deliver(&messages[i]) {
+			delivered++
+		}
+	}
+	return delivered
+}
+
+// deliver claims and handles a message and records the outcome; returns true once delivered
+func (w *OutboxWorker) deliver(message *models.OutboxMessage) bool {
+	now := time.Now()
+
+	// Claim the message by moving its next attempt forward; only one worker can win the update
+	result := config.DB.Model(&models.OutboxMessage{}).
+		Where("id = ? AND status = ? AND next_attempt_at <= ?", message.ID, models.OutboxStatusPending, now).
+		Update("next_attempt_at", now.Add(outboxLease))
+	if result.Error != nil {
+		log.Printf("Failed to claim outbox message %d: %v", message.ID, result.Error)
+		return false
+	}
+	if result.RowsAffected == 0 {
+		return false
+	}
+
+	err := w.handle(message)
+	if err == nil {
+		return true
+	}
+
+	// Retry later with exponential backoff, or give up after the maximum number of attempts
+	attempts := message.Attempts + 1
+	updates := map[string]interface{}{
+		"attempts":        attempts,
+		"last_error":      err.Error(),
+		"next_attempt_at": now.Add(outboxRetryDelay(attempts)),
+	}
+	if attempts >= config.GetOutboxMaxAttempts() {
+		updates["status"] = models.OutboxStatusDead
+		log.Printf("Outbox message %d (%s) failed %d times and was dead-lettered: %v", message.ID, message.Topic, attempts, err)
+	} else {
+		log.Printf("Outbox message %d (%s) failed, retrying: %v", message.ID, message.Topic, err)
+	}
+
+	if err := config.DB.Model(&models.OutboxMessage{}).Where("id = ?", message.ID).Updates(updates).Error; err != nil {
+		log.Printf("Failed to record failure of outbox message %d: %v", message.ID, err)
+	}
+	return false
+}
+
+// handle runs the handler of a message and marks it delivered in one transaction
+func (w *OutboxWorker) handle(message *models.OutboxMessage) (err error) {
+	handler, ok := w.handlers[message.Topic]
+	if !ok {
+		return fmt.Errorf("no handler for topic %s", message.Topic)
+	}
+
+	tx := config.DB.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+
+	if err := handler(tx, []byte(message.Payload)); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Model(&models.OutboxMessage{}).Where("id = ?", message.ID).Updates(map[string]interface{}{
+		"status":       models.OutboxStatusDelivered,
+		"attempts":     message.Attempts + 1,
+		"last_error":   "",
+		"delivered_at": time.Now(),
+	}).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
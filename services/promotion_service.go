@@ -0,0 +1,255 @@
+package services
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"restaurant-booking-backend/config"
+	"restaurant-booking-backend/models"
+	"restaurant-booking-backend/utils"
+
+	"gorm.io/gorm"
+)
+
+// Promotion reason codes
+const (
+	ReasonPromotionNotFound     = "promotion_not_found"
+	ReasonInvalidAudience       = "invalid_audience"
+	ReasonNoPromotionRecipients = "no_promotion_recipients"
+	ReasonPromotionNotActive    = "promotion_not_active"
+)
+
+// OutboxTopicPromotionBatch outbox topic of promotion batches; each batch queues the next one until every
+// recipient is handled
+const OutboxTopicPromotionBatch = "promotion.batch"
+
+// defaultPromotionAudienceDays look-back window of the recent_reservations audience when none is given
+const defaultPromotionAudienceDays = 90
+
+// promotionBatch payload of promotion batch messages
+type promotionBatch struct {
+	PromotionID uint `json:"promotion_id"`
+}
+
+// PromotionService broadcasts promotions to their audience in batches through the outbox
+// Users who opted out of promotion notifications (chose no channels for them) are skipped
+type PromotionService struct {
+	outboxService OutboxService
+}
+
+// CreatePromotion validates the audience of a promotion, stores it and queues its first batch
+// The recipients are the users matching the audience at this point; users created later are left out
+func (ps *PromotionService) CreatePromotion(promotion *models.Promotion) error {
+	switch promotion.Audience {
+	case models.PromotionAudienceAll:
+	case models.PromotionAudienceRecentReservations:
+		if promotion.AudienceDays < 0 {
+			return newDomainError(ReasonInvalidAudience, "Audience days cannot be negative")
+		}
+		if promotion.AudienceDays == 0 {
+			promotion.AudienceDays = defaultPromotionAudienceDays
+		}
+	case models.PromotionAudienceMenuItem:
+		if promotion.MenuItemID == nil {
+			return newDomainError(ReasonInvalidAudience, "Menu item ID is required for the menu_item audience")
+		}
+		var menuItem models.MenuItem
+		if err := config.DB.First(&menuItem, *promotion.MenuItemID).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return newDomainError(ReasonInvalidAudience, "Menu item not found")
+			}
+			return err
+		}
+	case models.PromotionAudienceUsers:
+		ids, seen := []uint{}, map[uint]bool{}
+		for _, id := range promotion.UserIDList() {
+			if !seen[id] {
+				ids, seen[id] = append(ids, id), true
+			}
+		}
+		if len(ids) == 0 {
+			return newDomainError(ReasonInvalidAudience, "User IDs are required for the users audience")
+		}
+		promotion.SetUserIDs(ids)
+	default:
+		return newDomainError(ReasonInvalidAudience, "Invalid audience. Use all, recent_reservations, menu_item or users")
+	}
+
+	// Settings of other audiences do not apply
+	if promotion.Audience != models.PromotionAudienceRecentReservations {
+		promotion.AudienceDays = 0
+	}
+	if promotion.Audience != models.PromotionAudienceMenuItem {
+		promotion.MenuItemID = nil
+	}
+	if promotion.Audience != models.PromotionAudienceUsers {
+		promotion.UserIDs = ""
+	}
+
+	return config.DB.Transaction(func(tx *gorm.DB) error {
+		// Fix the audience: the reservation window is counted back from the creation time and
+		// users created later are left out
+		promotion.CreatedAt = time.Now()
+		if err := tx.Model(&models.User{}).Select("COALESCE(MAX(id), 0)").Scan(&promotion.MaxUserID).Error; err != nil {
+			return err
+		}
+
+		var total int64
+		if err := ps.recipients(tx, promotion).Count(&total).Error; err != nil {
+			return err
+		}
+		if promotion.Audience == models.PromotionAudienceUsers && int(total) != len(promotion.UserIDList()) {
+			return newDomainError(ReasonInvalidAudience, "Some user IDs do not belong to existing users")
+		}
+		if total == 0 {
+			return newDomainError(ReasonNoPromotionRecipients, "No users match the audience")
+		}
+
+		promotion.TotalRecipients = int(total)
+		promotion.Status = models.PromotionStatusQueued
+		if err := tx.Create(promotion).Error; err != nil {
+			return err
+		}
+
+		return ps.outboxService.Enqueue(tx, OutboxTopicPromotionBatch, promotionBatch{PromotionID: promotion.ID})
+	})
+}
+
+// GetPromotion returns a promotion with its progress
+func (ps *PromotionService) GetPromotion(id uint) (*models.Promotion, error) {
+	var promotion models.Promotion
+	if err := config.DB.First(&promotion, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, &DomainError{Status: http.StatusNotFound, Code: ReasonPromotionNotFound, Message: "Promotion not found"}
+		}
+		return nil, err
+	}
+	return &promotion, nil
+}
+
+// CancelPromotion stops sending a promotion; users already sent to keep their notification
+func (ps *PromotionService) CancelPromotion(id uint) (*models.Promotion, error) {
+	var promotion models.Promotion
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Set("gorm:query_option", "FOR UPDATE").First(&promotion, id).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return &DomainError{Status: http.StatusNotFound, Code: ReasonPromotionNotFound, Message: "Promotion not found"}
+			}
+			return err
+		}
+		if !promotion.IsActive() {
+			return &DomainError{Status: http.StatusConflict, Code: ReasonPromotionNotActive, Message: "Promotion is already " + string(promotion.Status)}
+		}
+
+		now := time.Now()
+		promotion.Status = models.PromotionStatusCancelled
+		promotion.CompletedAt = &now
+		return tx.Save(&promotion).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &promotion, nil
+}
+
+// OutboxHandlers returns the handlers of the promotion outbox topics
+func (ps *PromotionService) OutboxHandlers() map[string]OutboxHandler {
+	return map[string]OutboxHandler{
+		OutboxTopicPromotionBatch: ps.handleBatch,
+	}
+}
+
+// handleBatch sends a promotion to its next batch of recipients and queues the batch after it
+// Runs in the outbox transaction, so a failed batch is retried as a whole without sending twice
+func (ps *PromotionService) handleBatch(tx *gorm.DB, payload []byte) error {
+	var batch promotionBatch
+	if err := json.Unmarshal(payload, &batch); err != nil {
+		return err
+	}
+
+	var promotion models.Promotion
+	if err := tx.Set("gorm:query_option", "FOR UPDATE").First(&promotion, batch.PromotionID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil
+		}
+		return err
+	}
+	if !promotion.IsActive() {
+		return nil // Cancelled since the batch was queued
+	}
+
+	now := time.Now()
+	if promotion.Status == models.PromotionStatusQueued {
+		promotion.Status = models.PromotionStatusSending
+		promotion.StartedAt = &now
+	}
+
+	batchSize := config.GetPromotionBatchSize()
+	var users []models.User
+	if err := ps.recipients(tx, &promotion).Where("users.id > ?", promotion.LastUserID).
+		Order("users.id ASC").Limit(batchSize).Find(&users).Error; err != nil {
+		return err
+	}
+
+	notificationService := &NotificationService{tx: tx}
+	for i := range users {
+		optedOut, err := ps.optedOut(notificationService, users[i].ID)
+		if err != nil {
+			return err
+		}
+		if optedOut {
+			promotion.SkippedCount++
+		} else {
+			if err := notificationService.SendNotification(users[i].ID, promotion.Message, models.NotificationTypePromotion); err != nil {
+				return err
+			}
+			promotion.SentCount++
+		}
+		promotion.LastUserID = users[i].ID
+	}
+
+	if len(users) < batchSize {
+		promotion.Status = models.PromotionStatusCompleted
+		promotion.CompletedAt = &now
+	} else if err := ps.outboxService.Enqueue(tx, OutboxTopicPromotionBatch, batch); err != nil {
+		return err
+	}
+
+	return tx.Save(&promotion).Error
+}
+
+// recipients returns the query of the users a promotion is sent to
+func (ps *PromotionService) recipients(db *gorm.DB, promotion *models.Promotion) *gorm.DB {
+	query := db.Model(&models.User{}).Where("users.id <= ?", promotion.MaxUserID)
+
+	switch promotion.Audience {
+	case models.PromotionAudienceUsers:
+		return query.Where("users.id IN ?", promotion.UserIDList())
+	case models.PromotionAudienceRecentReservations:
+		since, _ := utils.SplitDateTime(promotion.CreatedAt.AddDate(0, 0, -promotion.AudienceDays))
+		until, _ := utils.SplitDateTime(promotion.CreatedAt)
+		query = query.Where(
+			"EXISTS (SELECT 1 FROM reservations WHERE reservations.user_id = users.id AND reservations.deleted_at IS NULL "+
+				"AND reservations.status <> ? AND reservations.date BETWEEN ? AND ?)",
+			models.ReservationStatusCancelled, since, until,
+		)
+	case models.PromotionAudienceMenuItem:
+		query = query.Where(
+			"EXISTS (SELECT 1 FROM orders JOIN order_items ON order_items.order_id = orders.id "+
+				"WHERE orders.user_id = users.id AND orders.deleted_at IS NULL AND orders.status <> ? AND order_items.menu_item_id = ?)",
+			models.OrderStatusCancelled, *promotion.MenuItemID,
+		)
+	}
+
+	return query.Where("users.role = ?", models.RoleCustomer)
+}
+
+// optedOut checks if a user opted out of promotions by choosing no channels for them in their notification preferences
+func (ps *PromotionService) optedOut(notificationService *NotificationService, userID uint) (bool, error) {
+	channels, err := notificationService.GetChannels(userID, models.NotificationTypePromotion)
+	if err != nil {
+		return false, err
+	}
+	return len(channels) == 0, nil
+}
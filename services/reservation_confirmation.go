@@ -0,0 +1,73 @@
+package services
+
+import (
+	"net/http"
+	"net/url"
+	"time"
+
+	"restaurant-booking-backend/config"
+	"restaurant-booking-backend/models"
+	"restaurant-booking-backend/utils"
+
+	"gorm.io/gorm"
+)
+
+// ConfirmationDeadline returns when a pending reservation is cancelled unless the customer confirms it:
+// the pending confirmation timeout after booking, but never later than the reservation start
+func ConfirmationDeadline(reservation *models.Reservation) time.Time {
+	deadline := reservation.StartTime()
+	if timeout := config.GetPendingConfirmationTimeout(); timeout > 0 {
+		if expiry := reservation.CreatedAt.Add(time.Duration(timeout) * time.Minute); expiry.Before(deadline) {
+			deadline = expiry
+		}
+	}
+	return deadline
+}
+
+// ConfirmationLink returns the link the customer follows to confirm or cancel a pending reservation
+// The signed token in it expires at the confirmation deadline and only holds for the current version of the reservation
+func ConfirmationLink(reservation *models.Reservation) (string, error) {
+	token, err := utils.GenerateReservationToken(reservation.ID, reservation.Version, ConfirmationDeadline(reservation))
+	if err != nil {
+		return "", err
+	}
+	return config.GetReservationLinkURL() + "?token=" + url.QueryEscape(token), nil
+}
+
+// FindReservationByToken validates the token of a confirmation link and loads its reservation
+// Links sent before the reservation was last modified are rejected, so customers never act on details they were not shown
+func FindReservationByToken(token string) (*models.Reservation, error) {
+	reservationID, version, err := utils.ValidateReservationToken(token)
+	if err == utils.ErrReservationTokenExpired {
+		return nil, &DomainError{Status: http.StatusGone, Code: ReasonConfirmationLinkExpired, Message: "Confirmation link has expired"}
+	}
+	if err != nil {
+		return nil, &DomainError{Status: http.StatusUnauthorized, Code: ReasonConfirmationLinkInvalid, Message: "Invalid confirmation link"}
+	}
+
+	var reservation models.Reservation
+	if err := config.DB.Preload("Table").Preload("Tables").First(&reservation, reservationID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, &DomainError{Status: http.StatusNotFound, Code: ReasonReservationNotFound, Message: "Reservation not found"}
+		}
+		return nil, err
+	}
+	if reservation.Version != version {
+		return nil, &DomainError{Status: http.StatusGone, Code: ReasonConfirmationLinkOutdated, Message: "Reservation has changed since this link was sent, please use the latest link"}
+	}
+
+	return &reservation, nil
+}
+
+// EnsureConfirmableByCustomer checks that the customer can confirm the reservation themselves
+// Reservations that require a deposit are confirmed by staff once it is paid
+func EnsureConfirmableByCustomer(reservation *models.Reservation) error {
+	if reservation.DepositAmount > 0 {
+		return &DomainError{
+			Status:  http.StatusConflict,
+			Code:    ReasonDepositRequired,
+			Message: "Reservation requires a deposit and is confirmed once it is paid",
+		}
+	}
+	return nil
+}
@@ -0,0 +1,237 @@
+package services
+
+import (
+	"log"
+	"time"
+
+	"restaurant-booking-backend/config"
+	"restaurant-booking-backend/models"
+	"restaurant-booking-backend/utils"
+
+	"gorm.io/gorm"
+)
+
+// ReservationLifecycleService background jobs that move reservations through their lifecycle
+type ReservationLifecycleService struct {
+	reservationService  ReservationService
+	waitlistService     WaitlistService
+	notificationService NotificationService
+	floorEventService   FloorEventService
+}
+
+// Jobs returns the reservation lifecycle jobs to register with the scheduler
+func (rls *ReservationLifecycleService) Jobs() []Job {
+	noShowService := &NoShowService{lifecycleService: *rls}
+	interval := time.Duration(config.GetJobInterval()) * time.Minute
+	return []Job{
+		{Name: "complete_past_reservations", Interval: interval, Run: countingJob("Completed", "past reservation(s)", rls.CompletePastReservations)},
+		{Name: "mark_no_shows", Interval: time.Duration(config.GetNoShowCheckInterval()) * time.Minute, Run: countingJob("Marked", "reservation(s) as no-show", noShowService.MarkNoShows)},
+		{Name: "expire_unconfirmed_reservations", Interval: interval, Run: countingJob("Cancelled", "unconfirmed reservation(s)", rls.ExpireUnconfirmedReservations)},
+		{Name: "expire_waitlist_offers", Interval: interval, Run: rls.waitlistService.ExpireOffers},
+		{Name: "send_reservation_reminders", Interval: interval, Run: countingJob("Queued", "reservation reminder(s)", rls.SendReminders)},
+	}
+}
+
+// countingJob adapts a job that returns the number of processed records and logs it
+func countingJob(verb, noun string, run func() (int, error)) func() error {
+	return func() error {
+		count, err := run()
+		if count > 0 {
+			log.Printf("%s %d %s", verb, count, noun)
+		}
+		return err
+	}
+}
+
+// CompletePastReservations completes seated reservations whose time has ended, which releases their tables
+func (rls *ReservationLifecycleService) CompletePastReservations() (int, error) {
+	now := time.Now()
+	return rls.transitionDue(models.ReservationStatusSeated, models.ReservationStatusCompleted, now,
+		func(reservation *models.Reservation) bool {
+			return !reservation.EndTime().After(now)
+		}, "Reservation time ended")
+}
+
+// ExpireUnconfirmedReservations cancels pending reservations whose confirmation deadline has passed
+// (tables held for waitlist offers expire with the offer instead)
+func (rls *ReservationLifecycleService) ExpireUnconfirmedReservations() (int, error) {
+	now := time.Now()
+
+	// Without a timeout only reservations up to today can be due; with one, any date can
+	cutoff := now
+	if config.GetPendingConfirmationTimeout() > 0 {
+		cutoff = time.Time{}
+	}
+
+	return rls.transitionDue(models.ReservationStatusPending, models.ReservationStatusCancelled, cutoff,
+		func(reservation *models.Reservation) bool {
+			return !ConfirmationDeadline(reservation).After(now)
+		}, "Reservation was not confirmed in time")
+}
+
+// SendReminders queues reminders for active reservations at each configured offset before their start
+// Sent reminders are recorded so no reminder is sent twice, also across restarts; users who opted out are skipped
+func (rls *ReservationLifecycleService) SendReminders() (int, error) {
+	now := time.Now()
+	offsets := config.GetReminderOffsets()
+	horizon := now.Add(time.Duration(offsets[0]) * time.Minute)
+
+	reservations, err := rls.findDue([]models.ReservationStatus{models.ReservationStatusPending, models.ReservationStatusConfirmed}, horizon,
+		func(db *gorm.DB) *gorm.DB {
+			return db.Where("user_id NOT IN (?)", config.DB.Model(&models.User{}).Select("id").Where("reminders_disabled = ?", true))
+		})
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for i := range reservations {
+		reservation := &reservations[i]
+		start := reservation.StartTime()
+		if !start.After(now) {
+			continue
+		}
+
+		// Offsets whose reminder time has come, skipping those that passed before the reservation was made
+		var due []int
+		for _, offset := range offsets {
+			remindAt := start.Add(-time.Duration(offset) * time.Minute)
+			if !remindAt.After(now) && !remindAt.Before(reservation.CreatedAt) {
+				due = append(due, offset)
+			}
+		}
+		if len(due) == 0 {
+			continue
+		}
+
+		// Record the reminders and queue the closest one together; the unique index rejects reminders
+		// that were already sent. When several are due at once (e.g. after downtime) only the closest one is sent
+		queued := false
+		err := config.DB.Transaction(func(tx *gorm.DB) error {
+			recorded := false
+			for _, offset := range due {
+				result := tx.Where(models.ReservationReminder{ReservationID: reservation.ID, Offset: offset}).
+					FirstOrCreate(&models.ReservationReminder{})
+				if result.Error != nil {
+					return result.Error
+				}
+				if result.RowsAffected > 0 {
+					recorded = true
+				}
+			}
+			if !recorded {
+				return nil
+			}
+
+			queued = true
+			return rls.notificationService.QueueReservationReminder(tx, reservation, due[len(due)-1])
+		})
+		if err != nil {
+			log.Printf("Failed to queue reminder of reservation %d: %v", reservation.ID, err)
+			continue
+		}
+		if queued {
+			count++
+		}
+	}
+
+	return count, nil
+}
+
+// transitionDue moves reservations in status from to status to when due returns true for them
+// cutoff is the latest instant any of them can start, used to narrow down the query by date (zero for no limit)
+func (rls *ReservationLifecycleService) transitionDue(from, to models.ReservationStatus, cutoff time.Time, due func(*models.Reservation) bool, reason string) (int, error) {
+	reservations, err := rls.findDue([]models.ReservationStatus{from}, cutoff)
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for i := range reservations {
+		if !due(&reservations[i]) {
+			continue
+		}
+
+		ok, err := rls.transition(&reservations[i], from, to, reason)
+		if err != nil {
+			log.Printf("Failed to change reservation %d from %s to %s: %v", reservations[i].ID, from, to, err)
+			continue
+		}
+		if ok {
+			count++
+		}
+	}
+
+	return count, nil
+}
+
+// findDue loads reservations in the given statuses dated up to cutoff (in restaurant time)
+// Reservations are stored as separate date and time columns, so callers compare exact instants in Go
+func (rls *ReservationLifecycleService) findDue(statuses []models.ReservationStatus, cutoff time.Time, scopes ...func(*gorm.DB) *gorm.DB) ([]models.Reservation, error) {
+	query := config.DB.Scopes(scopes...).Where("status IN ?", statuses)
+	if !cutoff.IsZero() {
+		local := cutoff.In(utils.RestaurantLocation())
+		query = query.Where("date <= ?", time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, time.UTC))
+	}
+
+	var reservations []models.Reservation
+	err := query.
+		Where("id NOT IN (?)", config.DB.Model(&models.WaitlistEntry{}).Select("reservation_id").
+			Where("status = ? AND reservation_id IS NOT NULL", models.WaitlistStatusOffered)).
+		Preload("Tables").Find(&reservations).Error
+	return reservations, err
+}
+
+// transition changes the status of a single reservation under its table locks as the system;
+// returns false if the reservation was no longer in status from
+func (rls *ReservationLifecycleService) transition(reservation *models.Reservation, from, to models.ReservationStatus, reason string) (bool, error) {
+	// Get locks of every held table before releasing them
+	unlockTables := SharedTableLocks().Lock(reservation.HeldTableIDs())
+	defer unlockTables()
+
+	tx := config.DB.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	// Reload reservation within transaction with lock
+	if err := tx.Set("gorm:query_option", "FOR UPDATE").Preload("Tables").First(reservation, reservation.ID).Error; err != nil {
+		tx.Rollback()
+		if err == gorm.ErrRecordNotFound {
+			return false, nil
+		}
+		return false, err
+	}
+	if reservation.Status != from {
+		tx.Rollback()
+		return false, nil
+	}
+
+	if err := rls.reservationService.TransitionStatus(tx, reservation, to, 0, reason); err != nil {
+		tx.Rollback()
+		return false, err
+	}
+	if err := tx.Save(reservation).Error; err != nil {
+		tx.Rollback()
+		return false, err
+	}
+	if err := rls.notificationService.QueueReservationStatusUpdated(tx, reservation); err != nil {
+		tx.Rollback()
+		return false, err
+	}
+	if err := rls.floorEventService.RecordReservationStatus(tx, reservation); err != nil {
+		tx.Rollback()
+		return false, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return false, err
+	}
+
+	// Offer the rest of the released slot to the first eligible waitlisted customer (table locks are still held)
+	rls.waitlistService.OfferFreedSlot(reservation)
+
+	return true, nil
+}
@@ -0,0 +1,254 @@
+package services
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"restaurant-booking-backend/config"
+	"restaurant-booking-backend/models"
+
+	"gorm.io/gorm"
+)
+
+// ReservationService reservation scheduling service
+type ReservationService struct {
+	openingHours OpeningHoursService
+	history      StatusHistoryService
+}
+
+// BookingTarget table or table combination a reservation is booked against
+type BookingTarget struct {
+	Table       models.Table             // Primary table (first member table for combinations)
+	Combination *models.TableCombination // Set when booking a table combination
+	Tables      []models.Table           // Every table held by the reservation
+	Capacity    int                      // Seats available to the party
+}
+
+// TableIDs returns IDs of every table held by the target
+func (t *BookingTarget) TableIDs() []uint {
+	ids := make([]uint, 0, len(t.Tables))
+	for _, table := range t.Tables {
+		ids = append(ids, table.ID)
+	}
+	return ids
+}
+
+// CombinationID returns the table combination ID (nil for single-table bookings)
+func (t *BookingTarget) CombinationID() *uint {
+	if t.Combination == nil {
+		return nil
+	}
+	return &t.Combination.ID
+}
+
+// Apply sets the target tables on a reservation
+func (t *BookingTarget) Apply(reservation *models.Reservation) {
+	reservation.TableID = t.Table.ID
+	reservation.TableCombinationID = t.CombinationID()
+	reservation.Tables = nil
+	if t.Combination != nil {
+		reservation.Tables = t.Tables
+	}
+}
+
+// TargetTableIDs returns the sorted IDs of tables to lock for a booking against a table or combination
+func (rs *ReservationService) TargetTableIDs(tx *gorm.DB, tableID, combinationID uint) ([]uint, error) {
+	if (tableID == 0) == (combinationID == 0) {
+		return nil, newDomainError(ReasonTableRequired, "Specify either table_id or table_combination_id, not both")
+	}
+	if combinationID == 0 {
+		return []uint{tableID}, nil
+	}
+
+	var combination models.TableCombination
+	if err := tx.Preload("Tables").First(&combination, combinationID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, &DomainError{Status: http.StatusNotFound, Code: ReasonTableNotFound, Message: "Table combination not found"}
+		}
+		return nil, err
+	}
+
+	ids := combination.TableIDs()
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids, nil
+}
+
+// LoadBookingTarget loads the table or table combination to book and checks it is in service
+func (rs *ReservationService) LoadBookingTarget(tx *gorm.DB, tableID, combinationID uint) (*BookingTarget, error) {
+	if (tableID == 0) == (combinationID == 0) {
+		return nil, newDomainError(ReasonTableRequired, "Specify either table_id or table_combination_id, not both")
+	}
+
+	if combinationID == 0 {
+		// Check if table exists and lock the row (SELECT FOR UPDATE)
+		var table models.Table
+		if err := tx.Set("gorm:query_option", "FOR UPDATE").First(&table, tableID).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return nil, &DomainError{Status: http.StatusNotFound, Code: ReasonTableNotFound, Message: "Table not found"}
+			}
+			return nil, err
+		}
+
+		// Check if table is in service (occupancy is derived from reservations, not stored on the table)
+		if table.IsOutOfService() {
+			return nil, newDomainError(ReasonTableOutOfService, "Table is out of service")
+		}
+
+		return &BookingTarget{Table: table, Tables: []models.Table{table}, Capacity: table.Capacity}, nil
+	}
+
+	var combination models.TableCombination
+	if err := tx.Set("gorm:query_option", "FOR UPDATE").Preload("Tables", func(db *gorm.DB) *gorm.DB {
+		return db.Order("number ASC")
+	}).First(&combination, combinationID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, &DomainError{Status: http.StatusNotFound, Code: ReasonTableNotFound, Message: "Table combination not found"}
+		}
+		return nil, err
+	}
+
+	if !combination.IsActive || len(combination.Tables) == 0 {
+		return nil, newDomainError(ReasonTableOutOfService, "Table combination is not available")
+	}
+	for _, table := range combination.Tables {
+		if table.IsOutOfService() {
+			return nil, newDomainError(ReasonTableOutOfService,
+				fmt.Sprintf("Table #%d of this combination is out of service", table.Number))
+		}
+	}
+
+	return &BookingTarget{
+		Table:       combination.Tables[0],
+		Combination: &combination,
+		Tables:      combination.Tables,
+		Capacity:    combination.Capacity,
+	}, nil
+}
+
+// ValidateBooking checks party size, opening hours and overlapping reservations for a booking
+// starting at start, and returns the resolved duration in minutes
+// excludeID skips a reservation (e.g. the one being modified); pass 0 to check all
+func (rs *ReservationService) ValidateBooking(tx *gorm.DB, target *BookingTarget, start time.Time, partySize, requestedDuration int, excludeID uint) (int, error) {
+	// Check if party fits the table(s)
+	if err := rs.ValidatePartyCapacity(target.Capacity, partySize); err != nil {
+		return 0, err
+	}
+
+	// Check if reservation falls within opening hours
+	duration := rs.ResolveTargetDuration(target, requestedDuration)
+	end := start.Add(time.Duration(duration) * time.Minute)
+	if err := rs.ValidateOpeningHours(tx, start, end); err != nil {
+		return 0, err
+	}
+
+	// Check if any held table is already reserved for an overlapping time window
+	conflict, err := rs.FindConflictingReservation(tx, target.TableIDs(), start, end, excludeID)
+	if err != nil {
+		return 0, err
+	}
+	if conflict != nil {
+		return 0, &DomainError{Status: http.StatusConflict, Code: ReasonTableAlreadyReserved, Message: "Table is already reserved at this date and time"}
+	}
+
+	return duration, nil
+}
+
+// ResolveDuration returns the reservation duration in minutes
+// Priority: requested override, then table default, then restaurant default
+func (rs *ReservationService) ResolveDuration(table *models.Table, requested int) int {
+	if requested > 0 {
+		return requested
+	}
+	if table != nil && table.ReservationDuration > 0 {
+		return table.ReservationDuration
+	}
+	return config.GetDefaultReservationDuration()
+}
+
+// ResolveTargetDuration returns the reservation duration for a booking target
+// (the longest default among member tables for combinations)
+func (rs *ReservationService) ResolveTargetDuration(target *BookingTarget, requested int) int {
+	duration := 0
+	for i := range target.Tables {
+		if tableDuration := rs.ResolveDuration(&target.Tables[i], requested); tableDuration > duration {
+			duration = tableDuration
+		}
+	}
+	return duration
+}
+
+// FindConflictingReservation finds an active reservation holding any of the tables whose time window overlaps [start, end)
+// excludeID skips a reservation (e.g. the one being re-activated); pass 0 to check all
+func (rs *ReservationService) FindConflictingReservation(tx *gorm.DB, tableIDs []uint, start, end time.Time, excludeID uint) (*models.Reservation, error) {
+	candidates, err := rs.findActiveReservations(tx, tableIDs, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range candidates {
+		if candidates[i].ID != excludeID && candidates[i].Overlaps(start, end) {
+			return &candidates[i], nil
+		}
+	}
+
+	return nil, nil
+}
+
+// findActiveReservations loads active reservations holding any of the given tables that may overlap [start, end)
+// Reservations are stored as separate date and time columns, so this narrows down by date
+// (including the previous day for bookings running past midnight); callers compare windows in Go
+func (rs *ReservationService) findActiveReservations(tx *gorm.DB, tableIDs []uint, start, end time.Time) ([]models.Reservation, error) {
+	fromDate := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, time.UTC).AddDate(0, 0, -1)
+	toDate := time.Date(end.Year(), end.Month(), end.Day(), 0, 0, 0, 0, time.UTC)
+
+	// Combination reservations hold their member tables through reservation_tables
+	combinationReservations := tx.Session(&gorm.Session{NewDB: true}).
+		Table("reservation_tables").
+		Select("reservation_id").
+		Where("table_id IN ?", tableIDs)
+
+	var reservations []models.Reservation
+	if err := tx.Preload("Tables").
+		Where("(table_id IN ? OR id IN (?)) AND date >= ? AND date <= ? AND status IN ?",
+			tableIDs,
+			combinationReservations,
+			fromDate,
+			toDate,
+			models.ActiveReservationStatuses,
+		).Find(&reservations).Error; err != nil {
+		return nil, err
+	}
+
+	return reservations, nil
+}
+
+// ValidateOpeningHours checks that the reservation window falls within opening hours
+func (rs *ReservationService) ValidateOpeningHours(tx *gorm.DB, start, end time.Time) error {
+	return rs.openingHours.ValidateBookingWindow(tx, start, end)
+}
+
+// ValidatePartySize checks that the party fits the table and does not waste too much of its capacity
+func (rs *ReservationService) ValidatePartySize(table *models.Table, partySize int) error {
+	return rs.ValidatePartyCapacity(table.Capacity, partySize)
+}
+
+// ValidatePartyCapacity checks that the party fits the given capacity and does not waste too much of it
+func (rs *ReservationService) ValidatePartyCapacity(capacity, partySize int) error {
+	if partySize <= 0 {
+		return newDomainError(ReasonPartySizeInvalid, "Party size must be at least 1")
+	}
+
+	if partySize > capacity {
+		return newDomainError(ReasonPartyExceedsCapacity,
+			fmt.Sprintf("Party size exceeds table capacity (%d seats)", capacity))
+	}
+
+	minFillRatio := config.GetMinTableFillRatio()
+	if minFillRatio > 0 && float64(partySize)/float64(capacity) < minFillRatio {
+		return newDomainError(ReasonPartyBelowMinimumFill, "Party size is too small for this table")
+	}
+
+	return nil
+}
@@ -0,0 +1,92 @@
+package services
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"restaurant-booking-backend/models"
+
+	"gorm.io/gorm"
+)
+
+// ValidateTransition checks if the reservation status state machine allows moving a reservation to next
+// Illegal transitions are rejected with 409 Conflict and a reason code
+func (rs *ReservationService) ValidateTransition(reservation *models.Reservation, next models.ReservationStatus) error {
+	if !next.IsValid() {
+		return newDomainError(ReasonInvalidStatus, "Invalid reservation status")
+	}
+
+	if !reservation.Status.CanTransitionTo(next) {
+		return &DomainError{
+			Status:  http.StatusConflict,
+			Code:    ReasonInvalidStatusTransition,
+			Message: fmt.Sprintf("Cannot change reservation status from %s to %s", reservation.Status, next),
+		}
+	}
+
+	// Guests can only miss a reservation once it has started
+	if next == models.ReservationStatusNoShow && time.Now().Before(reservation.StartTime()) {
+		return &DomainError{
+			Status:  http.StatusConflict,
+			Code:    ReasonNoShowBeforeStart,
+			Message: "Cannot mark reservation as no-show before its start time",
+		}
+	}
+
+	return nil
+}
+
+// TransitionStatus moves a reservation to the next status if the state machine allows it and records
+// the change in the status history (and no-shows on the customer); the caller saves the reservation
+// within the same transaction
+// Pass actorID 0 for changes made by the system
+func (rs *ReservationService) TransitionStatus(tx *gorm.DB, reservation *models.Reservation, next models.ReservationStatus, actorID uint, reason string) error {
+	if err := rs.ValidateTransition(reservation, next); err != nil {
+		return err
+	}
+
+	previous := reservation.Status
+	reservation.Status = next
+
+	// Track when guests hold the table for turn time estimates
+	now := time.Now()
+	switch next {
+	case models.ReservationStatusSeated:
+		reservation.SeatedAt = &now
+	case models.ReservationStatusCompleted:
+		reservation.CompletedAt = &now
+	}
+
+	// Count missed reservations against the customer
+	if next == models.ReservationStatusNoShow {
+		if err := tx.Model(&models.User{}).Where("id = ?", reservation.UserID).
+			UpdateColumn("no_show_count", gorm.Expr("no_show_count + ?", 1)).Error; err != nil {
+			return err
+		}
+	}
+
+	return rs.history.Record(tx, models.StatusHistoryEntityReservation, reservation.ID, string(previous), string(next), actorID, reason)
+}
+
+// RecordCreated records the initial status of a newly created reservation in the status history
+func (rs *ReservationService) RecordCreated(tx *gorm.DB, reservation *models.Reservation, actorID uint, reason string) error {
+	return rs.history.Record(tx, models.StatusHistoryEntityReservation, reservation.ID, "", string(reservation.Status), actorID, reason)
+}
+
+// GetStatusHistory returns the status changes of a reservation, oldest first
+func (rs *ReservationService) GetStatusHistory(tx *gorm.DB, reservationID uint) ([]models.StatusHistory, error) {
+	return rs.history.GetHistory(tx, models.StatusHistoryEntityReservation, reservationID)
+}
+
+// EnsureModifiable checks if the date, time, tables or party size of a reservation may still change
+func (rs *ReservationService) EnsureModifiable(reservation *models.Reservation) error {
+	if !reservation.IsModifiable() {
+		return &DomainError{
+			Status:  http.StatusConflict,
+			Code:    ReasonReservationNotModifiable,
+			Message: fmt.Sprintf("Cannot modify a %s reservation", reservation.Status),
+		}
+	}
+	return nil
+}
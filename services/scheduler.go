@@ -0,0 +1,143 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"restaurant-booking-backend/config"
+	"restaurant-booking-backend/models"
+
+	"gorm.io/gorm"
+)
+
+// Job periodic background task
+type Job struct {
+	Name     string        // Unique name the schedule is persisted under
+	Interval time.Duration // Time between runs
+	Run      func() error
+}
+
+// Scheduler runs periodic jobs in-process
+// Next run times are persisted, so schedules survive restarts and several app instances
+// sharing a database do not run the same job at the same time
+type Scheduler struct {
+	tick     time.Duration
+	jobs     []Job
+	stop     chan struct{}
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+// NewScheduler creates a scheduler that checks for due jobs every tick
+func NewScheduler(tick time.Duration) *Scheduler {
+	return &Scheduler{
+		tick: tick,
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+}
+
+// Register adds a job to the scheduler (call before Start)
+func (s *Scheduler) Register(job Job) {
+	s.jobs = append(s.jobs, job)
+}
+
+// Start creates missing job schedules and starts running due jobs in the background
+// Jobs that became due while the app was down run right away
+func (s *Scheduler) Start() error {
+	for _, job := range s.jobs {
+		schedule := models.ScheduledJob{Name: job.Name, NextRunAt: time.Now()}
+		if err := config.DB.Where("name = ?", job.Name).FirstOrCreate(&schedule).Error; err != nil {
+			return fmt.Errorf("failed to load schedule of job %s: %w", job.Name, err)
+		}
+	}
+
+	go s.loop()
+	return nil
+}
+
+// Stop stops scheduling new runs and waits up to timeout for a running job to finish
+func (s *Scheduler) Stop(timeout time.Duration) {
+	s.stopOnce.Do(func() { close(s.stop) })
+
+	select {
+	case <-s.done:
+	case <-time.After(timeout):
+		log.Printf("Scheduler did not stop within %s", timeout)
+	}
+}
+
+// loop runs due jobs every tick until the scheduler is stopped
+func (s *Scheduler) loop() {
+	defer close(s.done)
+
+	ticker := time.NewTicker(s.tick)
+	defer ticker.Stop()
+
+	s.runDueJobs()
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+			s.runDueJobs()
+		}
+	}
+}
+
+// runDueJobs runs every job whose next run time has passed, one after another
+func (s *Scheduler) runDueJobs() {
+	for _, job := range s.jobs {
+		select {
+		case <-s.stop:
+			return
+		default:
+		}
+		s.runIfDue(job)
+	}
+}
+
+// runIfDue claims and runs a job if it is due and records the outcome
+func (s *Scheduler) runIfDue(job Job) {
+	now := time.Now()
+
+	// Claim the run by moving the next run time forward; only one instance can win the update
+	result := config.DB.Model(&models.ScheduledJob{}).
+		Where("name = ? AND next_run_at <= ?", job.Name, now).
+		Update("next_run_at", now.Add(job.Interval))
+	if result.Error != nil {
+		log.Printf("Failed to claim job %s: %v", job.Name, result.Error)
+		return
+	}
+	if result.RowsAffected == 0 {
+		return
+	}
+
+	err := runJob(job)
+	lastError := ""
+	if err != nil {
+		lastError = err.Error()
+		log.Printf("Job %s failed: %v", job.Name, err)
+	}
+
+	if err := config.DB.Model(&models.ScheduledJob{}).Where("name = ?", job.Name).Updates(map[string]interface{}{
+		"last_run_at":   now,
+		"last_duration": time.Since(now).Milliseconds(),
+		"last_error":    lastError,
+		"run_count":     gorm.Expr("run_count + ?", 1),
+	}).Error; err != nil {
+		log.Printf("Failed to record run of job %s: %v", job.Name, err)
+	}
+}
+
+// runJob runs a job and turns a panic into an error so one failing job cannot stop the scheduler
+func runJob(job Job) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return job.Run()
+}
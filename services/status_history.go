@@ -0,0 +1,35 @@
+package services
+
+import (
+	"restaurant-booking-backend/models"
+
+	"gorm.io/gorm"
+)
+
+// StatusHistoryService status change audit trail of reservations and orders
+type StatusHistoryService struct{}
+
+// Record appends a status change to the audit trail of a reservation or order
+// Pass actorID 0 for changes made by the system; fromStatus is empty when the record is created
+func (hs *StatusHistoryService) Record(tx *gorm.DB, entityType models.StatusHistoryEntity, entityID uint, fromStatus, toStatus string, actorID uint, reason string) error {
+	entry := models.StatusHistory{
+		EntityType: entityType,
+		EntityID:   entityID,
+		FromStatus: fromStatus,
+		ToStatus:   toStatus,
+		Reason:     reason,
+	}
+	if actorID != 0 {
+		entry.ActorID = &actorID
+	}
+
+	return tx.Create(&entry).Error
+}
+
+// GetHistory returns the status changes of a reservation or order, oldest first
+func (hs *StatusHistoryService) GetHistory(tx *gorm.DB, entityType models.StatusHistoryEntity, entityID uint) ([]models.StatusHistory, error) {
+	var history []models.StatusHistory
+	err := tx.Where("entity_type = ? AND entity_id = ?", entityType, entityID).
+		Preload("Actor").Order("created_at ASC, id ASC").Find(&history).Error
+	return history, err
+}
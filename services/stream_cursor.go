@@ -0,0 +1,36 @@
+package services
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// streamCommitWindow how long streams wait for a missing ID before skipping it
+// IDs are taken when a record is inserted, but the record only shows once its transaction commits, so records
+// can show up out of ID order; a gap that stays open longer than this is taken for a rolled back insert
+const streamCommitWindow = 10 * time.Second
+
+// settledID returns the ID up to which every record of model has shown up (or was rolled back)
+// Streams hand out records in ID order up to it, so an ID is a complete cursor for clients that resume from it
+func settledID(db *gorm.DB, model interface{}) (uint, error) {
+	// Soft deleted records still hold their ID, so they must not look like gaps
+	var settled uint
+	if err := db.Unscoped().Model(model).Where("created_at < ?", time.Now().Add(-streamCommitWindow)).
+		Select("COALESCE(MAX(id), 0)").Scan(&settled).Error; err != nil {
+		return 0, err
+	}
+
+	// Newer records count up to the first gap, which may still be committing
+	var recent []uint
+	if err := db.Unscoped().Model(model).Where("id > ?", settled).Order("id ASC").Pluck("id", &recent).Error; err != nil {
+		return 0, err
+	}
+	for _, id := range recent {
+		if id != settled+1 {
+			break
+		}
+		settled = id
+	}
+	return settled, nil
+}
@@ -0,0 +1,224 @@
+package services
+
+import (
+	"errors"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"restaurant-booking-backend/config"
+	"restaurant-booking-backend/models"
+
+	"gorm.io/gorm"
+)
+
+// AssignmentQuery automatic table assignment parameters
+type AssignmentQuery struct {
+	Start     time.Time // Reservation start in restaurant time
+	PartySize int       // Number of guests
+	Duration  int       // Optional duration override in minutes
+	Location  string    // Optional table location preference
+}
+
+// assignmentCandidate table or table combination considered for automatic assignment
+type assignmentCandidate struct {
+	target        BookingTarget
+	locationMatch bool
+	duration      int
+	fragmentation int // Minutes of unsellable idle time the booking would leave around it
+}
+
+// waste returns the number of seats left empty by the party
+func (ac *assignmentCandidate) waste(partySize int) int {
+	return ac.target.Capacity - partySize
+}
+
+// AssignTable picks the best-fitting free table (or table combination) for a booking and returns it with the resolved duration
+// Candidates are ranked by location preference, empty seats, number of tables held and the
+// unsellable gaps the booking would leave next to existing reservations
+func (rs *ReservationService) AssignTable(tx *gorm.DB, q AssignmentQuery) (*BookingTarget, int, error) {
+	if q.PartySize <= 0 {
+		return nil, 0, newDomainError(ReasonPartySizeInvalid, "Party size must be at least 1")
+	}
+
+	candidates, err := rs.assignmentCandidates(tx, q)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// Load active reservations of all candidate tables around the booking once
+	var tableIDs []uint
+	for i := range candidates {
+		tableIDs = append(tableIDs, candidates[i].target.TableIDs()...)
+	}
+	reservationsByTable := make(map[uint][]models.Reservation)
+	if len(tableIDs) > 0 {
+		reservations, err := rs.findActiveReservations(tx, tableIDs, q.Start, q.Start.AddDate(0, 0, 1))
+		if err != nil {
+			return nil, 0, err
+		}
+		for _, reservation := range reservations {
+			for _, tableID := range reservation.HeldTableIDs() {
+				reservationsByTable[tableID] = append(reservationsByTable[tableID], reservation)
+			}
+		}
+	}
+
+	var fits []*assignmentCandidate
+	var openingHoursErr error
+	checkedDurations := make(map[int]error)
+	reachedConflictCheck := false
+	for i := range candidates {
+		candidate := &candidates[i]
+		if err := rs.ValidatePartyCapacity(candidate.target.Capacity, q.PartySize); err != nil {
+			continue
+		}
+
+		// Check if reservation falls within opening hours (once per distinct duration)
+		candidate.duration = rs.ResolveTargetDuration(&candidate.target, q.Duration)
+		end := q.Start.Add(time.Duration(candidate.duration) * time.Minute)
+		hoursErr, checked := checkedDurations[candidate.duration]
+		if !checked {
+			hoursErr = rs.ValidateOpeningHours(tx, q.Start, end)
+			checkedDurations[candidate.duration] = hoursErr
+		}
+		if hoursErr != nil {
+			var domainErr *DomainError
+			if !errors.As(hoursErr, &domainErr) {
+				return nil, 0, hoursErr
+			}
+			if openingHoursErr == nil {
+				openingHoursErr = hoursErr
+			}
+			continue
+		}
+		reachedConflictCheck = true
+
+		// Check if every held table is free for the booking window
+		free := true
+		for _, tableID := range candidate.target.TableIDs() {
+			for j := range reservationsByTable[tableID] {
+				if reservationsByTable[tableID][j].Overlaps(q.Start, end) {
+					free = false
+					break
+				}
+			}
+			candidate.fragmentation += unsellableGap(reservationsByTable[tableID], q.Start, end)
+		}
+		if free {
+			fits = append(fits, candidate)
+		}
+	}
+
+	if len(fits) == 0 {
+		// Report opening hours violations as such; anything else means every table is taken or too small
+		if !reachedConflictCheck && openingHoursErr != nil {
+			return nil, 0, openingHoursErr
+		}
+		return nil, 0, &DomainError{Status: http.StatusConflict, Code: ReasonNoTableAvailable, Message: "No table available for this party at the requested time"}
+	}
+
+	sort.SliceStable(fits, func(i, j int) bool {
+		a, b := fits[i], fits[j]
+		if a.locationMatch != b.locationMatch {
+			return a.locationMatch
+		}
+		if a.waste(q.PartySize) != b.waste(q.PartySize) {
+			return a.waste(q.PartySize) < b.waste(q.PartySize)
+		}
+		if len(a.target.Tables) != len(b.target.Tables) {
+			return len(a.target.Tables) < len(b.target.Tables)
+		}
+		if a.fragmentation != b.fragmentation {
+			return a.fragmentation < b.fragmentation
+		}
+		return a.target.Table.Number < b.target.Table.Number
+	})
+
+	return &fits[0].target, fits[0].duration, nil
+}
+
+// assignmentCandidates loads in-service tables and active table combinations large enough for the party
+func (rs *ReservationService) assignmentCandidates(tx *gorm.DB, q AssignmentQuery) ([]assignmentCandidate, error) {
+	var tables []models.Table
+	if err := tx.Where("capacity >= ? AND status != ?", q.PartySize, models.TableStatusMaintenance).
+		Order("number ASC").Find(&tables).Error; err != nil {
+		return nil, err
+	}
+
+	var combinations []models.TableCombination
+	if err := tx.Where("is_active = ? AND capacity >= ?", true, q.PartySize).Preload("Tables", func(db *gorm.DB) *gorm.DB {
+		return db.Order("number ASC")
+	}).Find(&combinations).Error; err != nil {
+		return nil, err
+	}
+
+	candidates := make([]assignmentCandidate, 0, len(tables)+len(combinations))
+	for _, table := range tables {
+		candidates = append(candidates, assignmentCandidate{
+			target:        BookingTarget{Table: table, Tables: []models.Table{table}, Capacity: table.Capacity},
+			locationMatch: matchesLocation(table, q.Location),
+		})
+	}
+
+	for i := range combinations {
+		combination := &combinations[i]
+		if len(combination.Tables) == 0 {
+			continue
+		}
+
+		inService, locationMatch := true, true
+		for _, table := range combination.Tables {
+			inService = inService && !table.IsOutOfService()
+			locationMatch = locationMatch && matchesLocation(table, q.Location)
+		}
+		if !inService {
+			continue
+		}
+
+		candidates = append(candidates, assignmentCandidate{
+			target: BookingTarget{
+				Table:       combination.Tables[0],
+				Combination: combination,
+				Tables:      combination.Tables,
+				Capacity:    combination.Capacity,
+			},
+			locationMatch: locationMatch,
+		})
+	}
+
+	return candidates, nil
+}
+
+// matchesLocation checks if the table location contains the preferred location (case-insensitive)
+func matchesLocation(table models.Table, location string) bool {
+	if location == "" {
+		return true
+	}
+	return strings.Contains(strings.ToLower(table.Location), strings.ToLower(strings.TrimSpace(location)))
+}
+
+// unsellableGap returns the minutes of idle time a booking of [start, end) leaves between it and the
+// neighbouring reservations of a table that are too short to sell as another booking
+func unsellableGap(reservations []models.Reservation, start, end time.Time) int {
+	minSellable := time.Duration(config.GetDefaultReservationDuration()) * time.Minute
+
+	before, after := time.Duration(-1), time.Duration(-1)
+	for i := range reservations {
+		if gap := start.Sub(reservations[i].EndTime()); gap >= 0 && (before < 0 || gap < before) {
+			before = gap
+		}
+		if gap := reservations[i].StartTime().Sub(end); gap >= 0 && (after < 0 || gap < after) {
+			after = gap
+		}
+	}
+
+	total := 0
+	for _, gap := range []time.Duration{before, after} {
+		if gap > 0 && gap < minSellable {
+			total += int(gap.Minutes())
+		}
+	}
+	return total
+}
@@ -0,0 +1,68 @@
+package services
+
+import (
+	"sort"
+	"sync"
+)
+
+// TableLocks in-process table mutexes that serialize bookings of the same tables
+type TableLocks struct {
+	// tables stores mutexes for each table to prevent concurrent reservations
+	tables sync.Map // map[uint]*sync.Mutex
+	// global for operations that need global synchronization (e.g. automatic table assignment)
+	global sync.RWMutex
+}
+
+// sharedTableLocks table locks shared by every component that books or releases tables
+var sharedTableLocks = &TableLocks{}
+
+// SharedTableLocks returns the table locks shared across controllers and background jobs
+func SharedTableLocks() *TableLocks {
+	return sharedTableLocks
+}
+
+// getTableLock gets or creates a mutex for a specific table
+func (tl *TableLocks) getTableLock(tableID uint) *sync.Mutex {
+	// Try to get existing mutex
+	if lock, ok := tl.tables.Load(tableID); ok {
+		return lock.(*sync.Mutex)
+	}
+
+	// Create new mutex if it doesn't exist
+	newLock := &sync.Mutex{}
+	lock, _ := tl.tables.LoadOrStore(tableID, newLock)
+	return lock.(*sync.Mutex)
+}
+
+// Lock locks the mutexes of all given tables in ascending ID order to avoid deadlocks
+// and returns a function that releases them
+// Holds the global lock for reading so LockAll can lock every table at once
+func (tl *TableLocks) Lock(tableIDs []uint) func() {
+	tl.global.RLock()
+
+	ids := append([]uint(nil), tableIDs...)
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	locks := make([]*sync.Mutex, 0, len(ids))
+	for i, tableID := range ids {
+		if i > 0 && tableID == ids[i-1] {
+			continue
+		}
+		lock := tl.getTableLock(tableID)
+		lock.Lock()
+		locks = append(locks, lock)
+	}
+
+	return func() {
+		for i := len(locks) - 1; i >= 0; i-- {
+			locks[i].Unlock()
+		}
+		tl.global.RUnlock()
+	}
+}
+
+// LockAll locks every table and returns a function that releases them
+func (tl *TableLocks) LockAll() func() {
+	tl.global.Lock()
+	return tl.global.Unlock
+}
@@ -0,0 +1,191 @@
+package services
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"restaurant-booking-backend/config"
+	"restaurant-booking-backend/models"
+
+	"gorm.io/gorm"
+)
+
+// turnTimeSampleSize number of recently completed reservations used for the average turn time
+const turnTimeSampleSize = 100
+
+// WaitEstimate estimated wait of a walk-in party
+type WaitEstimate struct {
+	Minutes         int `json:"minutes"`           // Estimated wait in minutes
+	AverageTurnTime int `json:"average_turn_time"` // Average table turn time in minutes
+	FittingTables   int `json:"fitting_tables"`    // Tables and combinations the party fits
+	PartiesAhead    int `json:"parties_ahead"`     // Waiting parties ahead competing for the same tables
+}
+
+// AverageTurnTime returns the average minutes guests hold a table, measured from seating to completion of
+// recently completed reservations; the default reservation duration is used until there are any
+func (rs *ReservationService) AverageTurnTime(tx *gorm.DB) (int, error) {
+	var completed []models.Reservation
+	if err := tx.Where("status = ? AND seated_at IS NOT NULL AND completed_at IS NOT NULL", models.ReservationStatusCompleted).
+		Order("completed_at DESC").Limit(turnTimeSampleSize).Find(&completed).Error; err != nil {
+		return 0, err
+	}
+
+	// Skip implausible turns, e.g. from clock changes
+	maxTurn := time.Duration(config.GetMaxReservationDuration()) * time.Minute
+	var total time.Duration
+	samples := 0
+	for i := range completed {
+		turn := completed[i].CompletedAt.Sub(*completed[i].SeatedAt)
+		if turn <= 0 || turn > maxTurn {
+			continue
+		}
+		total += turn
+		samples++
+	}
+
+	if samples == 0 {
+		return config.GetDefaultReservationDuration(), nil
+	}
+	return int(math.Round((total / time.Duration(samples)).Minutes())), nil
+}
+
+// EstimateWalkInWait estimates how long a walk-in party waits for a table from the current occupancy,
+// the average turn time and the walk-ins ahead in the queue, which are seated first on the earliest free table they fit
+func (rs *ReservationService) EstimateWalkInWait(tx *gorm.DB, partySize int, partiesAhead []int, at time.Time) (*WaitEstimate, error) {
+	candidates, err := rs.assignmentCandidates(tx, AssignmentQuery{PartySize: 1})
+	if err != nil {
+		return nil, err
+	}
+
+	targets := make([]BookingTarget, 0, len(candidates))
+	var tableIDs []uint
+	fitting := 0
+	for i := range candidates {
+		targets = append(targets, candidates[i].target)
+		tableIDs = append(tableIDs, candidates[i].target.TableIDs()...)
+		if rs.ValidatePartyCapacity(candidates[i].target.Capacity, partySize) == nil {
+			fitting++
+		}
+	}
+	if fitting == 0 {
+		return nil, newDomainError(ReasonPartyExceedsCapacity, "No table can seat this party")
+	}
+
+	turn, err := rs.AverageTurnTime(tx)
+	if err != nil {
+		return nil, err
+	}
+	turnLength := time.Duration(turn) * time.Minute
+
+	// Load active reservations of all tables from now on
+	reservations, err := rs.findActiveReservations(tx, tableIDs, at, at.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, err
+	}
+	busyByTable := make(map[uint][]TimeWindow)
+	for i := range reservations {
+		busy := TimeWindow{Start: reservations[i].StartTime(), End: reservations[i].EndTime()}
+		if !busy.Start.After(at) {
+			// Guests already at the table are expected to leave after the average turn time
+			busy.End = busy.Start.Add(turnLength)
+			if busy.End.Before(at) {
+				busy.End = at
+			}
+		}
+		if !busy.End.After(at) {
+			continue
+		}
+		for _, tableID := range reservations[i].HeldTableIDs() {
+			busyByTable[tableID] = append(busyByTable[tableID], busy)
+		}
+	}
+
+	// Earliest time each table (or combination) is free for a full turn
+	freeAt := make([]time.Time, len(targets))
+	for i := range targets {
+		freeAt[i] = earliestFreeStart(targets[i].TableIDs(), busyByTable, at, turnLength)
+	}
+
+	// earliestFit picks the earliest free target a party fits (smallest capacity on ties)
+	earliestFit := func(size int) int {
+		best := -1
+		for i := range targets {
+			if rs.ValidatePartyCapacity(targets[i].Capacity, size) != nil {
+				continue
+			}
+			if best < 0 || freeAt[i].Before(freeAt[best]) ||
+				(freeAt[i].Equal(freeAt[best]) && targets[i].Capacity < targets[best].Capacity) {
+				best = i
+			}
+		}
+		return best
+	}
+
+	// Seat the parties ahead first; each holds its tables for a full turn
+	ahead := 0
+	for _, size := range partiesAhead {
+		seated := earliestFit(size)
+		if seated < 0 {
+			continue
+		}
+		if rs.ValidatePartyCapacity(targets[seated].Capacity, partySize) == nil {
+			ahead++
+		}
+
+		leaveAt := freeAt[seated].Add(turnLength)
+		for i := range targets {
+			if sharesTable(targets[i].TableIDs(), targets[seated].TableIDs()) && freeAt[i].Before(leaveAt) {
+				freeAt[i] = leaveAt
+			}
+		}
+	}
+
+	return &WaitEstimate{
+		Minutes:         int(math.Ceil(freeAt[earliestFit(partySize)].Sub(at).Minutes())),
+		AverageTurnTime: turn,
+		FittingTables:   fitting,
+		PartiesAhead:    ahead,
+	}, nil
+}
+
+// sharesTable checks if two table ID sets have a table in common
+func sharesTable(a, b []uint) bool {
+	for _, x := range a {
+		for _, y := range b {
+			if x == y {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// earliestFreeStart returns the earliest start from at on which all tables are free for length
+func earliestFreeStart(tableIDs []uint, busyByTable map[uint][]TimeWindow, at time.Time, length time.Duration) time.Time {
+	starts := []time.Time{at}
+	for _, tableID := range tableIDs {
+		for _, busy := range busyByTable[tableID] {
+			starts = append(starts, busy.End)
+		}
+	}
+	sort.Slice(starts, func(i, j int) bool { return starts[i].Before(starts[j]) })
+
+	for _, start := range starts {
+		end := start.Add(length)
+		free := true
+		for _, tableID := range tableIDs {
+			for _, busy := range busyByTable[tableID] {
+				if busy.Start.Before(end) && start.Before(busy.End) {
+					free = false
+					break
+				}
+			}
+		}
+		if free {
+			return start
+		}
+	}
+
+	return starts[len(starts)-1]
+}
@@ -0,0 +1,283 @@
+package services
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	"restaurant-booking-backend/config"
+	"restaurant-booking-backend/models"
+	"restaurant-booking-backend/utils"
+
+	"gorm.io/gorm"
+)
+
+// WaitlistService offers freed tables to waitlisted customers
+type WaitlistService struct {
+	reservationService  ReservationService
+	notificationService NotificationService
+	policyService       BookingPolicyService
+}
+
+// OfferFreedSlot offers the tables released by a cancelled reservation to the first eligible waitlisted
+// customer by holding a pending reservation for them until the claim window ends
+// Callers must hold the table locks of the released reservation
+func (ws *WaitlistService) OfferFreedSlot(released *models.Reservation) (*models.WaitlistEntry, error) {
+	tx := config.DB.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	// A held reservation cancelled by the customer or staff closes its offer
+	if err := tx.Model(&models.WaitlistEntry{}).
+		Where("reservation_id = ? AND status = ?", released.ID, models.WaitlistStatusOffered).
+		Update("status", models.WaitlistStatusCancelled).Error; err != nil {
+		tx.Rollback()
+		log.Printf("Failed to close waitlist offer: %v", err)
+		return nil, err
+	}
+
+	entry, reservation, err := ws.offerToNextEntry(tx, released)
+	if err != nil {
+		tx.Rollback()
+		log.Printf("Failed to offer released table to waitlist: %v", err)
+		return nil, err
+	}
+	if entry != nil {
+		if err := ws.notificationService.QueueWaitlistOffer(tx, entry, reservation); err != nil {
+			tx.Rollback()
+			log.Printf("Failed to queue waitlist offer notification: %v", err)
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		log.Printf("Failed to commit waitlist offer: %v", err)
+		return nil, err
+	}
+
+	return entry, nil
+}
+
+// offerToNextEntry holds the released tables for the first waitlisted customer whose requested
+// time they can now serve and who may still book under the booking policy; returns nil when nobody is eligible
+func (ws *WaitlistService) offerToNextEntry(tx *gorm.DB, released *models.Reservation) (*models.WaitlistEntry, *models.Reservation, error) {
+	var tableID, combinationID uint
+	if released.TableCombinationID != nil {
+		combinationID = *released.TableCombinationID
+	} else {
+		tableID = released.TableID
+	}
+
+	target, err := ws.reservationService.LoadBookingTarget(tx, tableID, combinationID)
+	if err != nil {
+		var domainErr *DomainError
+		if errors.As(err, &domainErr) {
+			// Table was removed or taken out of service - nothing to offer
+			return nil, nil, nil
+		}
+		return nil, nil, err
+	}
+
+	policy, err := ws.policyService.GetPolicy(tx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var entries []models.WaitlistEntry
+	if err := tx.Where("date = ? AND status = ? AND party_size <= ?", released.Date, models.WaitlistStatusWaiting, target.Capacity).
+		Order("created_at ASC, id ASC").Find(&entries).Error; err != nil {
+		return nil, nil, err
+	}
+
+	now := time.Now()
+	for i := range entries {
+		entry := &entries[i]
+		start := entry.StartTime()
+		if start.Before(now) {
+			continue
+		}
+
+		// Only customers whose requested window was blocked by the released reservation
+		length := time.Duration(ws.reservationService.ResolveTargetDuration(target, 0)) * time.Minute
+		if !released.Overlaps(start, start.Add(length)) {
+			continue
+		}
+
+		duration, err := ws.reservationService.ValidateBooking(tx, target, start, entry.PartySize, 0, 0)
+		if err != nil {
+			var domainErr *DomainError
+			if errors.As(err, &domainErr) {
+				continue
+			}
+			return nil, nil, err
+		}
+
+		// The customer may have reached the policy limits since joining the waitlist
+		if err := ws.policyService.CheckTiming(policy, start); err != nil {
+			continue
+		}
+		deposit, err := ws.policyService.CheckCustomer(tx, policy, entry.UserID)
+		if err != nil {
+			var domainErr *DomainError
+			if errors.As(err, &domainErr) {
+				continue
+			}
+			return nil, nil, err
+		}
+
+		// Hold the tables for the customer with a pending reservation
+		reservation := models.Reservation{
+			UserID:        entry.UserID,
+			Date:          entry.Date,
+			Time:          entry.Time,
+			Duration:      duration,
+			PartySize:     entry.PartySize,
+			Status:        models.ReservationStatusPending,
+			DepositAmount: deposit,
+		}
+		target.Apply(&reservation)
+		if err := tx.Create(&reservation).Error; err != nil {
+			return nil, nil, err
+		}
+		if err := ws.reservationService.RecordCreated(tx, &reservation, 0, "Table held for waitlist offer"); err != nil {
+			return nil, nil, err
+		}
+
+		expiresAt := now.Add(time.Duration(config.GetWaitlistClaimWindow()) * time.Minute)
+		entry.Status = models.WaitlistStatusOffered
+		entry.ReservationID = &reservation.ID
+		entry.OfferExpiresAt = &expiresAt
+		if err := tx.Save(entry).Error; err != nil {
+			return nil, nil, err
+		}
+
+		return entry, &reservation, nil
+	}
+
+	return nil, nil, nil
+}
+
+// CloseOffer closes an open waitlist entry with the given status; a table still held for the
+// customer is released and offered to the next customer in line
+// actorID is the user closing the entry (0 when the offer expires)
+func (ws *WaitlistService) CloseOffer(entryID uint, status models.WaitlistStatus, actorID uint) error {
+	var entry models.WaitlistEntry
+	if err := config.DB.Preload("Reservation.Tables").First(&entry, entryID).Error; err != nil {
+		return err
+	}
+
+	// Get locks of the held tables before releasing them
+	if entry.Reservation != nil {
+		unlockTables := SharedTableLocks().Lock(entry.Reservation.HeldTableIDs())
+		defer unlockTables()
+	}
+
+	tx := config.DB.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	// Reload entry within transaction with lock
+	if err := tx.Set("gorm:query_option", "FOR UPDATE").First(&entry, entryID).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+	if !entry.IsOpen() {
+		tx.Rollback()
+		return nil
+	}
+
+	var released *models.Reservation
+	entry.Status = status
+	if entry.ReservationID != nil {
+		var reservation models.Reservation
+		if err := tx.Set("gorm:query_option", "FOR UPDATE").Preload("Tables").First(&reservation, *entry.ReservationID).Error; err != nil {
+			tx.Rollback()
+			return err
+		}
+
+		switch reservation.Status {
+		case models.ReservationStatusPending:
+			reason := "Customer left the waitlist"
+			if status == models.WaitlistStatusExpired {
+				reason = "Waitlist offer expired"
+			}
+			if err := ws.reservationService.TransitionStatus(tx, &reservation, models.ReservationStatusCancelled, actorID, reason); err != nil {
+				tx.Rollback()
+				return err
+			}
+			if err := tx.Save(&reservation).Error; err != nil {
+				tx.Rollback()
+				return err
+			}
+			released = &reservation
+		case models.ReservationStatusConfirmed:
+			// Staff confirmed the held reservation in the meantime
+			entry.Status = models.WaitlistStatusClaimed
+		}
+	}
+
+	if err := tx.Save(&entry).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+	if released != nil && status == models.WaitlistStatusExpired {
+		if err := ws.notificationService.QueueWaitlistOfferExpired(tx, &entry); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return err
+	}
+
+	if released != nil {
+		ws.OfferFreedSlot(released)
+	}
+
+	return nil
+}
+
+// ExpireOffers closes offers that were not claimed in time and entries whose requested time has passed
+func (ws *WaitlistService) ExpireOffers() error {
+	now := time.Now()
+
+	var offered []models.WaitlistEntry
+	if err := config.DB.Where("status = ? AND offer_expires_at < ?", models.WaitlistStatusOffered, now).
+		Find(&offered).Error; err != nil {
+		return err
+	}
+	for _, entry := range offered {
+		if err := ws.CloseOffer(entry.ID, models.WaitlistStatusExpired, 0); err != nil {
+			log.Printf("Failed to expire waitlist offer %d: %v", entry.ID, err)
+		}
+	}
+
+	// Waiting entries are stored as separate date and time columns, so narrow down by date and compare in Go
+	local := now.In(utils.RestaurantLocation())
+	today := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, time.UTC)
+	var waiting []models.WaitlistEntry
+	if err := config.DB.Where("status = ? AND date <= ?", models.WaitlistStatusWaiting, today).
+		Find(&waiting).Error; err != nil {
+		return err
+	}
+	var passed []uint
+	for i := range waiting {
+		if waiting[i].StartTime().Before(now) {
+			passed = append(passed, waiting[i].ID)
+		}
+	}
+	if len(passed) > 0 {
+		return config.DB.Model(&models.WaitlistEntry{}).
+			Where("id IN ? AND status = ?", passed, models.WaitlistStatusWaiting).
+			Update("status", models.WaitlistStatusExpired).Error
+	}
+
+	return nil
+}
@@ -0,0 +1,81 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"restaurant-booking-backend/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBookingPolicy(t *testing.T) {
+	SetupTestEnvironment(t)
+	defer CleanupTestEnvironment(t)
+
+	CreateTestUser("09111111111", "password123", "Admin User", models.RoleAdmin)
+	CreateTestUser("09123456789", "password123", "Test User", models.RoleCustomer)
+	table, _ := CreateTestTable(1, 4, "Window", models.TableStatusAvailable)
+	adminToken := getAuthToken(t, "09111111111", "password123")
+	userToken := getAuthToken(t, "09123456789", "password123")
+
+	t.Run("Update booking policy with invalid no-show action", func(t *testing.T) {
+		payload := map[string]interface{}{
+			"no_show_action": "fine",
+		}
+		jsonValue, _ := json.Marshal(payload)
+
+		req, _ := http.NewRequest("PUT", "/api/v1/admin/booking-policy", bytes.NewBuffer(jsonValue))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+adminToken)
+		w := httptest.NewRecorder()
+		testRouter.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("Update booking policy as admin", func(t *testing.T) {
+		payload := map[string]interface{}{
+			"max_active_reservations": 1,
+			"max_advance_days":        7,
+		}
+		jsonValue, _ := json.Marshal(payload)
+
+		req, _ := http.NewRequest("PUT", "/api/v1/admin/booking-policy", bytes.NewBuffer(jsonValue))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+adminToken)
+		w := httptest.NewRecorder()
+		testRouter.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var response map[string]interface{}
+		json.Unmarshal(w.Body.Bytes(), &response)
+		data := response["data"].(map[string]interface{})
+		assert.Equal(t, float64(7), data["max_advance_days"])
+	})
+
+	t.Run("Create reservation beyond the advance window", func(t *testing.T) {
+		payload := map[string]interface{}{
+			"table_id":   table.ID,
+			"date":       time.Now().AddDate(0, 0, 30).Format("2006-01-02"),
+			"time":       "19:00",
+			"party_size": 2,
+		}
+		jsonValue, _ := json.Marshal(payload)
+
+		req, _ := http.NewRequest("POST", "/api/v1/reservations", bytes.NewBuffer(jsonValue))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+userToken)
+		w := httptest.NewRecorder()
+		testRouter.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		var response map[string]interface{}
+		json.Unmarshal(w.Body.Bytes(), &response)
+		assert.Equal(t, "beyond_advance_window", response["code"])
+	})
+}
@@ -0,0 +1,116 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"restaurant-booking-backend/models"
+	"restaurant-booking-backend/services"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFloorEvents(t *testing.T) {
+	SetupTestEnvironment(t)
+	defer CleanupTestEnvironment(t)
+
+	user, _ := CreateTestUser("09123456789", "password123", "Test User", models.RoleCustomer)
+	CreateTestUser("09111111111", "password123", "Admin User", models.RoleAdmin)
+	table, _ := CreateTestTable(1, 4, "Window", models.TableStatusAvailable)
+	adminToken := getAuthToken(t, "09111111111", "password123")
+	userToken := getAuthToken(t, "09123456789", "password123")
+	floorEventService := &services.FloorEventService{}
+
+	stream := services.NewFloorEventStream(time.Hour)
+	assert.NoError(t, stream.Start())
+	defer stream.Stop(time.Second)
+	subscription := stream.Subscribe()
+
+	t.Run("Record table status changes", func(t *testing.T) {
+		payload := map[string]interface{}{
+			"status": "maintenance",
+		}
+		jsonValue, _ := json.Marshal(payload)
+
+		req, _ := http.NewRequest("PUT", fmt.Sprintf("/api/v1/admin/tables/%d", table.ID), bytes.NewBuffer(jsonValue))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+adminToken)
+		w := httptest.NewRecorder()
+		testRouter.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		assert.Equal(t, 1, stream.Push())
+		event := <-subscription.Events
+		assert.Equal(t, models.FloorEventTableStatusChanged, event.Type)
+		assert.Equal(t, []uint{table.ID}, event.TableIDList())
+	})
+
+	t.Run("Record seat events", func(t *testing.T) {
+		reservation := createLifecycleReservation(user.ID, table.ID, time.Now().Add(-10*time.Minute), models.ReservationStatusConfirmed)
+		payload := map[string]interface{}{
+			"status": "seated",
+		}
+		jsonValue, _ := json.Marshal(payload)
+
+		req, _ := http.NewRequest("PUT", fmt.Sprintf("/api/v1/admin/reservations/%d/status", reservation.ID), bytes.NewBuffer(jsonValue))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+adminToken)
+		w := httptest.NewRecorder()
+		testRouter.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		assert.Equal(t, 1, stream.Push())
+		event := <-subscription.Events
+		assert.Equal(t, models.FloorEventReservationSeated, event.Type)
+		assert.Equal(t, reservation.ID, *event.ReservationID)
+	})
+
+	t.Run("Replay missed events", func(t *testing.T) {
+		events, err := floorEventService.GetEventsAfter(0, 10)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, len(events))
+
+		latest, _ := floorEventService.GetLatestEventID()
+		events, _ = floorEventService.GetEventsAfter(latest, 10)
+		assert.Equal(t, 0, len(events))
+	})
+
+	t.Run("Wait for events committed out of ID order", func(t *testing.T) {
+		latest, _ := floorEventService.GetLatestEventID()
+
+		// The event before it is still being committed
+		testDB.Create(&models.FloorEvent{BaseModel: models.BaseModel{ID: latest + 2}, Type: models.FloorEventTableUpdated, Data: "{}"})
+		assert.Equal(t, 0, stream.Push())
+		events, _ := floorEventService.GetEventsAfter(latest, 10)
+		assert.Equal(t, 0, len(events))
+
+		testDB.Create(&models.FloorEvent{BaseModel: models.BaseModel{ID: latest + 1}, Type: models.FloorEventTableUpdated, Data: "{}"})
+		assert.Equal(t, 2, stream.Push())
+		assert.Equal(t, latest+1, (<-subscription.Events).ID)
+		assert.Equal(t, latest+2, (<-subscription.Events).ID)
+	})
+
+	t.Run("Prune old events", func(t *testing.T) {
+		testDB.Model(&models.FloorEvent{}).Where("1 = 1").Update("created_at", time.Now().Add(-48*time.Hour))
+
+		count, err := floorEventService.PruneEvents()
+
+		assert.NoError(t, err)
+		assert.Equal(t, 4, count)
+	})
+
+	t.Run("Require admin", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/api/v1/admin/floor/events?access_token="+userToken, nil)
+		w := httptest.NewRecorder()
+		testRouter.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+}
@@ -0,0 +1,133 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"restaurant-booking-backend/models"
+	"restaurant-booking-backend/services"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKitchenDisplay(t *testing.T) {
+	SetupTestEnvironment(t)
+	defer CleanupTestEnvironment(t)
+
+	os.Setenv("KITCHEN_STATIONS", "grill:main;bar:drink")
+	defer os.Unsetenv("KITCHEN_STATIONS")
+
+	user, _ := CreateTestUser("09123456789", "password123", "Test User", models.RoleCustomer)
+	CreateTestUser("09111111111", "password123", "Admin User", models.RoleAdmin)
+	adminToken := getAuthToken(t, "09111111111", "password123")
+	steak, _ := CreateTestMenuItem("Steak", "Grilled steak", 30, models.CategoryMain)
+	cola, _ := CreateTestMenuItem("Cola", "Cold drink", 3, models.CategoryDrink)
+	cake, _ := CreateTestMenuItem("Cake", "Chocolate cake", 6, models.CategoryDessert)
+
+	confirmedAt := time.Now().Add(-5 * time.Minute)
+	order := models.Order{
+		UserID:      user.ID,
+		Status:      models.OrderStatusConfirmed,
+		TotalPrice:  39,
+		ConfirmedAt: &confirmedAt,
+		OrderItems: []models.OrderItem{
+			{MenuItemID: steak.ID, Quantity: 1, Price: 30},
+			{MenuItemID: cola.ID, Quantity: 1, Price: 3},
+			{MenuItemID: cake.ID, Quantity: 1, Price: 6},
+		},
+	}
+	testDB.Create(&order)
+
+	stream := services.NewKitchenStream(time.Hour)
+	stream.Start()
+	defer stream.Stop(time.Second)
+	subscription := stream.Subscribe()
+
+	getTickets := func(t *testing.T, query string) []interface{} {
+		req, _ := http.NewRequest("GET", "/api/v1/admin/kitchen/tickets"+query, nil)
+		req.Header.Set("Authorization", "Bearer "+adminToken)
+		w := httptest.NewRecorder()
+		testRouter.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var response map[string]interface{}
+		json.Unmarshal(w.Body.Bytes(), &response)
+		return response["data"].([]interface{})
+	}
+
+	postAction := func(action string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("POST", fmt.Sprintf("/api/v1/admin/kitchen/tickets/%d/%s", order.ID, action), nil)
+		req.Header.Set("Authorization", "Bearer "+adminToken)
+		w := httptest.NewRecorder()
+		testRouter.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("Route items to stations by category", func(t *testing.T) {
+		tickets := getTickets(t, "?station=grill")
+		assert.Equal(t, 1, len(tickets))
+		ticket := tickets[0].(map[string]interface{})
+		items := ticket["items"].([]interface{})
+		assert.Equal(t, 1, len(items))
+		assert.Equal(t, "Steak", items[0].(map[string]interface{})["name"])
+		assert.GreaterOrEqual(t, ticket["elapsed_seconds"].(float64), float64(300))
+
+		// Categories without a station go to the default station
+		tickets = getTickets(t, "?station=kitchen")
+		items = tickets[0].(map[string]interface{})["items"].([]interface{})
+		assert.Equal(t, "Cake", items[0].(map[string]interface{})["name"])
+
+		assert.Equal(t, 3, len(getTickets(t, "")[0].(map[string]interface{})["items"].([]interface{})))
+	})
+
+	t.Run("Unknown station", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/api/v1/admin/kitchen/tickets?station=pastry", nil)
+		req.Header.Set("Authorization", "Bearer "+adminToken)
+		w := httptest.NewRecorder()
+		testRouter.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("Bump to preparing and ready", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, postAction("bump").Code)
+		testDB.First(&order, order.ID)
+		assert.Equal(t, models.OrderStatusPreparing, order.Status)
+
+		assert.Equal(t, http.StatusOK, postAction("bump").Code)
+		testDB.First(&order, order.ID)
+		assert.Equal(t, models.OrderStatusReady, order.Status)
+		assert.NotNil(t, order.ReadyAt)
+
+		// Ready tickets leave the display but can still be recalled
+		assert.Equal(t, 0, len(getTickets(t, "")))
+		assert.Equal(t, 1, len(getTickets(t, "?ready=true")))
+
+		assert.Equal(t, http.StatusConflict, postAction("bump").Code)
+	})
+
+	t.Run("Recall ready ticket", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, postAction("recall").Code)
+		var recalled models.Order
+		testDB.First(&recalled, order.ID)
+		assert.Equal(t, models.OrderStatusPreparing, recalled.Status)
+		assert.Nil(t, recalled.ReadyAt)
+
+		assert.Equal(t, http.StatusConflict, postAction("recall").Code)
+	})
+
+	t.Run("Push changed orders", func(t *testing.T) {
+		assert.GreaterOrEqual(t, stream.Push(), 1)
+		changed := <-subscription.Orders
+		assert.Equal(t, order.ID, changed.ID)
+		assert.Equal(t, models.OrderStatusPreparing, changed.Status)
+
+		// Unchanged orders are not pushed again
+		assert.Equal(t, 0, stream.Push())
+	})
+}
@@ -52,7 +52,7 @@ func TestGetMenuItemByID(t *testing.T) {
 	SetupTestEnvironment(t)
 	defer CleanupTestEnvironment(t)
 
-	item, _ := CreateTestMenuItem("Pasta", "Delicious pasta", 25.99, models.CategoryMain)
+	CreateTestMenuItem("Pasta", "Delicious pasta", 25.99, models.CategoryMain)
 
 	t.Run("Get menu item by ID", func(t *testing.T) {
 		req, _ := http.NewRequest("GET", "/api/v1/menu/1", nil)
@@ -79,7 +79,7 @@ func TestCreateMenuItem(t *testing.T) {
 	defer CleanupTestEnvironment(t)
 
 	// Create admin user and get token
-	admin, _ := CreateTestUser("09111111111", "password123", "Admin", models.RoleAdmin)
+	CreateTestUser("09111111111", "password123", "Admin", models.RoleAdmin)
 	adminToken := getAuthToken(t, "09111111111", "password123")
 
 	t.Run("Create menu item as admin", func(t *testing.T) {
@@ -32,7 +32,7 @@ func TestAuthMiddleware(t *testing.T) {
 	})
 
 	t.Run("Access protected route with valid token", func(t *testing.T) {
-		user, _ := CreateTestUser("09123456789", "password123", "Test User", models.RoleCustomer)
+		CreateTestUser("09123456789", "password123", "Test User", models.RoleCustomer)
 		userToken := getAuthToken(t, "09123456789", "password123")
 
 		req, _ := http.NewRequest("GET", "/api/v1/profile", nil)
@@ -49,8 +49,8 @@ func TestRoleMiddleware(t *testing.T) {
 	defer CleanupTestEnvironment(t)
 
 	// Create admin and customer users
-	admin, _ := CreateTestUser("09111111111", "password123", "Admin", models.RoleAdmin)
-	customer, _ := CreateTestUser("09222222222", "password123", "Customer", models.RoleCustomer)
+	CreateTestUser("09111111111", "password123", "Admin", models.RoleAdmin)
+	CreateTestUser("09222222222", "password123", "Customer", models.RoleCustomer)
 
 	adminToken := getAuthToken(t, "09111111111", "password123")
 	customerToken := getAuthToken(t, "09222222222", "password123")
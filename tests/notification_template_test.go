@@ -0,0 +1,126 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"restaurant-booking-backend/models"
+	"restaurant-booking-backend/services"
+	"restaurant-booking-backend/utils"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNotificationTemplates(t *testing.T) {
+	SetupTestEnvironment(t)
+	defer CleanupTestEnvironment(t)
+
+	user, _ := CreateTestUser("09123456789", "password123", "Test User", models.RoleCustomer)
+	CreateTestUser("09111111111", "password123", "Admin User", models.RoleAdmin)
+	table, _ := CreateTestTable(7, 4, "Window", models.TableStatusAvailable)
+	adminToken := getAuthToken(t, "09111111111", "password123")
+	userToken := getAuthToken(t, "09123456789", "password123")
+	notificationService := &services.NotificationService{}
+
+	reservation := models.Reservation{
+		UserID:    user.ID,
+		TableID:   table.ID,
+		Date:      time.Date(2024, 3, 20, 0, 0, 0, 0, time.UTC),
+		Time:      time.Date(0, 1, 1, 19, 30, 0, 0, time.UTC),
+		PartySize: 2,
+		Status:    models.ReservationStatusConfirmed,
+		Duration:  60,
+	}
+	testDB.Create(&reservation)
+
+	put := func(path, token string, payload map[string]interface{}) *httptest.ResponseRecorder {
+		jsonValue, _ := json.Marshal(payload)
+		req, _ := http.NewRequest("PUT", path, bytes.NewBuffer(jsonValue))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		testRouter.ServeHTTP(w, req)
+		return w
+	}
+
+	latestMessage := func() string {
+		var notification models.Notification
+		testDB.Where("user_id = ?", user.ID).Order("id DESC").First(&notification)
+		return notification.Message
+	}
+
+	t.Run("Convert dates to the Jalali calendar", func(t *testing.T) {
+		year, month, day := utils.ToJalali(time.Date(2024, 3, 20, 0, 0, 0, 0, time.UTC))
+		assert.Equal(t, []int{1403, 1, 1}, []int{year, month, day})
+
+		year, month, day = utils.ToJalali(time.Date(2025, 3, 20, 0, 0, 0, 0, time.UTC))
+		assert.Equal(t, []int{1403, 12, 30}, []int{year, month, day})
+	})
+
+	t.Run("Write Persian with Jalali dates", func(t *testing.T) {
+		w := put("/api/v1/profile/language", userToken, map[string]interface{}{"language": "fa"})
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		assert.NoError(t, notificationService.SendReservationCancelledNotification(&reservation))
+		assert.Equal(t, "رزرو شما برای میز شماره ۷ در تاریخ ۱۴۰۳/۰۱/۰۱ ساعت ۱۹:۳۰ لغو شد.", latestMessage())
+	})
+
+	t.Run("Write English with Gregorian dates", func(t *testing.T) {
+		w := put("/api/v1/profile/language", userToken, map[string]interface{}{"language": "en", "calendar": "gregorian"})
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		assert.NoError(t, notificationService.SendReservationCancelledNotification(&reservation))
+		assert.Equal(t, "Your reservation for table #7 on 2024-03-20 at 19:30 has been cancelled.", latestMessage())
+	})
+
+	t.Run("Write email subjects in the user's language", func(t *testing.T) {
+		persian := services.LocaleFor(&models.User{Language: models.LanguagePersian})
+		english := services.LocaleFor(&models.User{Language: models.LanguageEnglish})
+
+		assert.Equal(t, "پیشنهاد ویژه", persian.EmailSubject(models.NotificationTypePromotion))
+		assert.Equal(t, "Your reservation", english.EmailSubject(models.NotificationTypeReservation))
+	})
+
+	t.Run("Reject unsupported language", func(t *testing.T) {
+		w := put("/api/v1/profile/language", userToken, map[string]interface{}{"language": "de"})
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("Use admin edited template", func(t *testing.T) {
+		w := put("/api/v1/admin/notification-templates/reservation_reminder/en", adminToken, map[string]interface{}{
+			"body": "See you on {{date .Date}} in {{duration .ReminderOffset}}",
+		})
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		assert.NoError(t, notificationService.SendReservationReminderNotification(&reservation, 1440))
+		assert.Equal(t, "See you on 2024-03-20 in 1 day", latestMessage())
+	})
+
+	t.Run("Reject broken template", func(t *testing.T) {
+		w := put("/api/v1/admin/notification-templates/reservation_reminder/en", adminToken, map[string]interface{}{
+			"body": "See you {{.Unknown}}",
+		})
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+
+		w = put("/api/v1/admin/notification-templates/unknown_event/en", adminToken, map[string]interface{}{
+			"body": "See you",
+		})
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("Reset to built-in template", func(t *testing.T) {
+		req, _ := http.NewRequest("DELETE", "/api/v1/admin/notification-templates/reservation_reminder/en", nil)
+		req.Header.Set("Authorization", "Bearer "+adminToken)
+		w := httptest.NewRecorder()
+		testRouter.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		assert.NoError(t, notificationService.SendReservationReminderNotification(&reservation, 120))
+		assert.Equal(t, "Reminder: your reservation for table #7 is in 2 hours, on 2024-03-20 at 19:30. We look forward to seeing you!", latestMessage())
+	})
+}
@@ -5,9 +5,14 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"restaurant-booking-backend/models"
+	"restaurant-booking-backend/services"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -111,3 +116,207 @@ func TestDeleteNotification(t *testing.T) {
 	})
 }
 
+func TestNotificationPreferences(t *testing.T) {
+	SetupTestEnvironment(t)
+	defer CleanupTestEnvironment(t)
+
+	CreateTestUser("09123456789", "password123", "Test User", models.RoleCustomer)
+	userToken := getAuthToken(t, "09123456789", "password123")
+
+	t.Run("Default to SMS", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/api/v1/notifications/preferences", nil)
+		req.Header.Set("Authorization", "Bearer "+userToken)
+		w := httptest.NewRecorder()
+		testRouter.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response map[string]interface{}
+		json.Unmarshal(w.Body.Bytes(), &response)
+		channels := response["data"].(map[string]interface{})["channels"].(map[string]interface{})
+		assert.Equal(t, []interface{}{"sms"}, channels["reservation"])
+	})
+
+	t.Run("Update preferences", func(t *testing.T) {
+		payload := map[string]interface{}{
+			"channels": map[string]interface{}{
+				"reservation": []string{"sms", "email"},
+				"promotion":   []string{},
+			},
+			"email": "test@example.com",
+		}
+		jsonValue, _ := json.Marshal(payload)
+
+		req, _ := http.NewRequest("PUT", "/api/v1/notifications/preferences", bytes.NewBuffer(jsonValue))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+userToken)
+		w := httptest.NewRecorder()
+		testRouter.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("Invalid channel", func(t *testing.T) {
+		payload := map[string]interface{}{
+			"channels": map[string]interface{}{
+				"reservation": []string{"fax"},
+			},
+		}
+		jsonValue, _ := json.Marshal(payload)
+
+		req, _ := http.NewRequest("PUT", "/api/v1/notifications/preferences", bytes.NewBuffer(jsonValue))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+userToken)
+		w := httptest.NewRecorder()
+		testRouter.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("Store only the email address", func(t *testing.T) {
+		payload := map[string]interface{}{
+			"email": "Test User <test@example.com>",
+		}
+		jsonValue, _ := json.Marshal(payload)
+
+		req, _ := http.NewRequest("PUT", "/api/v1/notifications/preferences", bytes.NewBuffer(jsonValue))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+userToken)
+		w := httptest.NewRecorder()
+		testRouter.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var user models.User
+		testDB.Where("phone = ?", "09123456789").First(&user)
+		assert.Equal(t, "test@example.com", user.Email)
+	})
+}
+
+func TestNotificationChannels(t *testing.T) {
+	SetupTestEnvironment(t)
+	defer CleanupTestEnvironment(t)
+
+	// Without channel settings every channel is written to the notification file
+	path := filepath.Join(t.TempDir(), "notifications.jsonl")
+	os.Setenv("NOTIFICATION_FILE_PATH", path)
+	defer os.Unsetenv("NOTIFICATION_FILE_PATH")
+
+	user, _ := CreateTestUser("09123456789", "password123", "Test User", models.RoleCustomer)
+	notificationService := &services.NotificationService{}
+	worker := newTestOutboxWorker()
+
+	t.Run("Send over default channel", func(t *testing.T) {
+		err := notificationService.SendNotification(user.ID, "Table is ready", models.NotificationTypeReservation)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, worker.DeliverDue())
+
+		content, _ := os.ReadFile(path)
+		assert.Contains(t, string(content), `"channel":"sms"`)
+		assert.Contains(t, string(content), "Table is ready")
+	})
+
+	t.Run("Respect opt-out", func(t *testing.T) {
+		err := notificationService.UpdatePreferences(user.ID, map[models.NotificationType][]models.NotificationChannel{
+			models.NotificationTypePromotion: {},
+		})
+		assert.NoError(t, err)
+
+		err = notificationService.SendNotification(user.ID, "Half price desserts", models.NotificationTypePromotion)
+		assert.NoError(t, err)
+		assert.Equal(t, 0, worker.DeliverDue())
+
+		content, _ := os.ReadFile(path)
+		assert.False(t, strings.Contains(string(content), "Half price desserts"))
+
+		// The notification is still stored in the user's inbox
+		var count int64
+		testDB.Model(&models.Notification{}).Where("user_id = ?", user.ID).Count(&count)
+		assert.Equal(t, int64(2), count)
+	})
+}
+
+func TestNotificationStream(t *testing.T) {
+	SetupTestEnvironment(t)
+	defer CleanupTestEnvironment(t)
+
+	user, _ := CreateTestUser("09123456789", "password123", "Test User", models.RoleCustomer)
+	other, _ := CreateTestUser("09123456780", "password123", "Other User", models.RoleCustomer)
+	notificationService := &services.NotificationService{}
+
+	err := notificationService.SendNotification(user.ID, "Before the stream started", models.NotificationTypeSystem)
+	assert.NoError(t, err)
+
+	stream := services.NewNotificationStream(time.Hour)
+	assert.NoError(t, stream.Start())
+	defer stream.Stop(time.Second)
+
+	t.Run("Push new notifications to their user", func(t *testing.T) {
+		subscription := stream.Subscribe(user.ID)
+		defer stream.Unsubscribe(subscription)
+
+		notificationService.SendNotification(other.ID, "For someone else", models.NotificationTypeSystem)
+		notificationService.SendNotification(user.ID, "Table is ready", models.NotificationTypeSystem)
+		assert.Equal(t, 1, stream.Push())
+
+		notification := <-subscription.Notifications
+		assert.Equal(t, "Table is ready", notification.Message)
+		assert.Equal(t, 0, len(subscription.Notifications))
+	})
+
+	t.Run("Replay missed notifications", func(t *testing.T) {
+		latest, err := notificationService.GetLatestNotificationID(user.ID)
+		assert.NoError(t, err)
+
+		missed, err := notificationService.GetNotificationsAfter(user.ID, 0, 10)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, len(missed))
+		assert.Equal(t, "Before the stream started", missed[0].Message)
+		assert.Equal(t, latest, missed[1].ID)
+
+		missed, _ = notificationService.GetNotificationsAfter(user.ID, latest, 10)
+		assert.Equal(t, 0, len(missed))
+	})
+
+	t.Run("Wait for notifications committed out of ID order", func(t *testing.T) {
+		subscription := stream.Subscribe(user.ID)
+		defer stream.Unsubscribe(subscription)
+
+		latest, _ := notificationService.GetLatestNotificationID(user.ID)
+
+		// The notification before it is still being committed
+		testDB.Create(&models.Notification{BaseModel: models.BaseModel{ID: latest + 2}, UserID: user.ID, Message: "Second", Type: models.NotificationTypeSystem})
+		assert.Equal(t, 0, stream.Push())
+		missed, _ := notificationService.GetNotificationsAfter(user.ID, latest, 10)
+		assert.Equal(t, 0, len(missed))
+
+		testDB.Create(&models.Notification{BaseModel: models.BaseModel{ID: latest + 1}, UserID: user.ID, Message: "First", Type: models.NotificationTypeSystem})
+		assert.Equal(t, 2, stream.Push())
+		assert.Equal(t, "First", (<-subscription.Notifications).Message)
+		assert.Equal(t, "Second", (<-subscription.Notifications).Message)
+
+		missed, _ = notificationService.GetNotificationsAfter(user.ID, latest, 10)
+		assert.Equal(t, 2, len(missed))
+	})
+
+	t.Run("End subscriptions when stopped", func(t *testing.T) {
+		subscription := stream.Subscribe(user.ID)
+		stream.Stop(time.Second)
+
+		_, ok := <-subscription.Notifications
+		assert.False(t, ok)
+	})
+
+	t.Run("Require token", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/api/v1/notifications/stream", nil)
+		w := httptest.NewRecorder()
+		testRouter.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+		req, _ = http.NewRequest("GET", "/api/v1/notifications/stream?access_token=invalid", nil)
+		w = httptest.NewRecorder()
+		testRouter.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+}
@@ -0,0 +1,90 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"restaurant-booking-backend/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOpeningHours(t *testing.T) {
+	SetupTestEnvironment(t)
+	defer CleanupTestEnvironment(t)
+
+	CreateTestUser("09111111111", "password123", "Admin User", models.RoleAdmin)
+	table, _ := CreateTestTable(1, 4, "Window", models.TableStatusAvailable)
+	adminToken := getAuthToken(t, "09111111111", "password123")
+	futureDate := time.Now().Add(24 * time.Hour)
+
+	t.Run("Create opening hours as admin", func(t *testing.T) {
+		payload := map[string]interface{}{
+			"weekday":    int(futureDate.Weekday()),
+			"name":       "dinner",
+			"open_time":  "18:00",
+			"close_time": "23:00",
+		}
+		jsonValue, _ := json.Marshal(payload)
+
+		req, _ := http.NewRequest("POST", "/api/v1/admin/opening-hours", bytes.NewBuffer(jsonValue))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+adminToken)
+		w := httptest.NewRecorder()
+		testRouter.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("Create opening hours with invalid weekday", func(t *testing.T) {
+		payload := map[string]interface{}{
+			"weekday":    7,
+			"open_time":  "18:00",
+			"close_time": "23:00",
+		}
+		jsonValue, _ := json.Marshal(payload)
+
+		req, _ := http.NewRequest("POST", "/api/v1/admin/opening-hours", bytes.NewBuffer(jsonValue))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+adminToken)
+		w := httptest.NewRecorder()
+		testRouter.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("Create reservation outside opening hours", func(t *testing.T) {
+		payload := map[string]interface{}{
+			"phone":      "09222222222",
+			"name":       "Guest",
+			"table_id":   table.ID,
+			"date":       futureDate.Format("2006-01-02"),
+			"time":       "03:00",
+			"party_size": 2,
+		}
+		jsonValue, _ := json.Marshal(payload)
+
+		req, _ := http.NewRequest("POST", "/api/v1/admin/reservations", bytes.NewBuffer(jsonValue))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+adminToken)
+		w := httptest.NewRecorder()
+		testRouter.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		var response map[string]interface{}
+		json.Unmarshal(w.Body.Bytes(), &response)
+		assert.Equal(t, "outside_opening_hours", response["code"])
+	})
+
+	t.Run("Get public opening hours", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/api/v1/opening-hours", nil)
+		w := httptest.NewRecorder()
+		testRouter.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+}
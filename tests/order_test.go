@@ -0,0 +1,86 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"restaurant-booking-backend/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpdateOrderStatus(t *testing.T) {
+	SetupTestEnvironment(t)
+	defer CleanupTestEnvironment(t)
+
+	user, _ := CreateTestUser("09123456789", "password123", "Test User", models.RoleCustomer)
+	CreateTestUser("09111111111", "password123", "Admin User", models.RoleAdmin)
+	adminToken := getAuthToken(t, "09111111111", "password123")
+
+	order := models.Order{
+		UserID:     user.ID,
+		Status:     models.OrderStatusPending,
+		TotalPrice: 100,
+	}
+	testDB.Create(&order)
+
+	updateStatus := func(status string) *httptest.ResponseRecorder {
+		payload := map[string]interface{}{
+			"status": status,
+		}
+		jsonValue, _ := json.Marshal(payload)
+
+		req, _ := http.NewRequest("PUT", "/api/v1/admin/orders/1/status", bytes.NewBuffer(jsonValue))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+adminToken)
+		w := httptest.NewRecorder()
+		testRouter.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("Skip straight to ready", func(t *testing.T) {
+		w := updateStatus("ready")
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+		var response map[string]interface{}
+		json.Unmarshal(w.Body.Bytes(), &response)
+		assert.Equal(t, "invalid_status_transition", response["code"])
+	})
+
+	t.Run("Confirm and start preparing", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, updateStatus("confirmed").Code)
+
+		w := updateStatus("preparing")
+		assert.Equal(t, http.StatusOK, w.Code)
+		var response map[string]interface{}
+		json.Unmarshal(w.Body.Bytes(), &response)
+		data := response["data"].(map[string]interface{})
+		assert.NotNil(t, data["confirmed_at"])
+		assert.NotNil(t, data["preparing_at"])
+	})
+
+	t.Run("Cancel order being prepared", func(t *testing.T) {
+		w := updateStatus("cancelled")
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+
+	t.Run("Get order history", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/api/v1/admin/orders/1/history", nil)
+		req.Header.Set("Authorization", "Bearer "+adminToken)
+		w := httptest.NewRecorder()
+		testRouter.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var response map[string]interface{}
+		json.Unmarshal(w.Body.Bytes(), &response)
+		history := response["data"].([]interface{})
+		assert.Len(t, history, 2)
+		last := history[1].(map[string]interface{})
+		assert.Equal(t, "confirmed", last["from_status"])
+		assert.Equal(t, "preparing", last["to_status"])
+	})
+}
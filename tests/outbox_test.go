@@ -0,0 +1,118 @@
+package tests
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"restaurant-booking-backend/models"
+	"restaurant-booking-backend/services"
+	"restaurant-booking-backend/utils"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestOutboxWorker creates an outbox worker with the notification handlers; tests call DeliverDue directly
+func newTestOutboxWorker() *services.OutboxWorker {
+	worker := services.NewOutboxWorker(time.Second)
+	for topic, handler := range (&services.NotificationService{}).OutboxHandlers() {
+		worker.Register(topic, handler)
+	}
+	return worker
+}
+
+func TestNotificationOutbox(t *testing.T) {
+	SetupTestEnvironment(t)
+	defer CleanupTestEnvironment(t)
+
+	// SMS gateway that is down until the test brings it back
+	gatewayUp := false
+	gateway := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !gatewayUp {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer gateway.Close()
+
+	os.Setenv("SMS_API_URL", gateway.URL)
+	os.Setenv("OUTBOX_MAX_ATTEMPTS", "2")
+	defer os.Unsetenv("SMS_API_URL")
+	defer os.Unsetenv("OUTBOX_MAX_ATTEMPTS")
+
+	CreateTestUser("09111111111", "password123", "Admin User", models.RoleAdmin)
+	user, _ := CreateTestUser("09123456789", "password123", "Test User", models.RoleCustomer)
+	table, _ := CreateTestTable(1, 4, "Window", models.TableStatusAvailable)
+	adminToken := getAuthToken(t, "09111111111", "password123")
+	worker := newTestOutboxWorker()
+
+	date, clock := utils.SplitDateTime(time.Now().Add(24 * time.Hour))
+	reservation := models.Reservation{
+		UserID:    user.ID,
+		TableID:   table.ID,
+		Date:      date,
+		Time:      clock,
+		Duration:  60,
+		PartySize: 2,
+		Status:    models.ReservationStatusPending,
+	}
+	testDB.Create(&reservation)
+
+	t.Run("Queue notifications with the change", func(t *testing.T) {
+		err := (&services.NotificationService{}).QueueReservationCreated(testDB, &reservation)
+		assert.NoError(t, err)
+
+		// Nothing is sent until the worker handles the message
+		var count int64
+		testDB.Model(&models.Notification{}).Count(&count)
+		assert.Equal(t, int64(0), count)
+
+		assert.Equal(t, 1, worker.DeliverDue())
+		testDB.Model(&models.Notification{}).Count(&count)
+		assert.Equal(t, int64(2), count)
+	})
+
+	t.Run("Dead-letter after repeated failures", func(t *testing.T) {
+		assert.Equal(t, 0, worker.DeliverDue())
+
+		// Make the retries due right away
+		testDB.Model(&models.OutboxMessage{}).Where("status = ?", models.OutboxStatusPending).
+			Update("next_attempt_at", time.Now())
+		assert.Equal(t, 0, worker.DeliverDue())
+
+		var dead int64
+		testDB.Model(&models.OutboxMessage{}).Where("status = ?", models.OutboxStatusDead).Count(&dead)
+		assert.Equal(t, int64(2), dead)
+	})
+
+	t.Run("List dead messages", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/api/v1/admin/outbox?status=dead", nil)
+		req.Header.Set("Authorization", "Bearer "+adminToken)
+		w := httptest.NewRecorder()
+		testRouter.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("Retry dead messages", func(t *testing.T) {
+		gatewayUp = true
+
+		req, _ := http.NewRequest("POST", "/api/v1/admin/outbox/retry", nil)
+		req.Header.Set("Authorization", "Bearer "+adminToken)
+		w := httptest.NewRecorder()
+		testRouter.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, 2, worker.DeliverDue())
+	})
+
+	t.Run("Retry delivered message", func(t *testing.T) {
+		req, _ := http.NewRequest("POST", "/api/v1/admin/outbox/1/retry", nil)
+		req.Header.Set("Authorization", "Bearer "+adminToken)
+		w := httptest.NewRecorder()
+		testRouter.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+}
@@ -0,0 +1,142 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"restaurant-booking-backend/models"
+	"restaurant-booking-backend/services"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPromotions(t *testing.T) {
+	SetupTestEnvironment(t)
+	defer CleanupTestEnvironment(t)
+
+	os.Setenv("PROMOTION_BATCH_SIZE", "2")
+	defer os.Unsetenv("PROMOTION_BATCH_SIZE")
+
+	CreateTestUser("09111111111", "password123", "Admin User", models.RoleAdmin)
+	adminToken := getAuthToken(t, "09111111111", "password123")
+	table, _ := CreateTestTable(1, 4, "Window", models.TableStatusAvailable)
+	steak, _ := CreateTestMenuItem("Steak", "Grilled steak", 30, models.CategoryMain)
+
+	var customers []*models.User
+	for i := 0; i < 4; i++ {
+		customer, _ := CreateTestUser(fmt.Sprintf("0912345678%d", i), "password123", fmt.Sprintf("Customer %d", i), models.RoleCustomer)
+		customers = append(customers, customer)
+	}
+
+	// Customer 0 had a reservation last week, customer 1 ordered steak and customer 3 opted out of promotions
+	createLifecycleReservation(customers[0].ID, table.ID, time.Now().AddDate(0, 0, -7), models.ReservationStatusCompleted)
+	testDB.Create(&models.Order{
+		UserID:     customers[1].ID,
+		Status:     models.OrderStatusDelivered,
+		TotalPrice: 30,
+		OrderItems: []models.OrderItem{{MenuItemID: steak.ID, Quantity: 1, Price: 30}},
+	})
+	optOut, _ := json.Marshal(map[string]interface{}{"channels": map[string][]string{"promotion": {}}})
+	req, _ := http.NewRequest("PUT", "/api/v1/notifications/preferences", bytes.NewBuffer(optOut))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+getAuthToken(t, "09123456783", "password123"))
+	w := httptest.NewRecorder()
+	testRouter.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	worker := services.NewOutboxWorker(time.Second)
+	for topic, handler := range (&services.PromotionService{}).OutboxHandlers() {
+		worker.Register(topic, handler)
+	}
+
+	createPromotion := func(payload map[string]interface{}) (*httptest.ResponseRecorder, models.Promotion) {
+		jsonValue, _ := json.Marshal(payload)
+		req, _ := http.NewRequest("POST", "/api/v1/admin/promotions", bytes.NewBuffer(jsonValue))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+adminToken)
+		w := httptest.NewRecorder()
+		testRouter.ServeHTTP(w, req)
+
+		var response struct {
+			Data models.Promotion `json:"data"`
+		}
+		json.Unmarshal(w.Body.Bytes(), &response)
+		return w, response.Data
+	}
+
+	received := func(userID uint, message string) bool {
+		var count int64
+		testDB.Model(&models.Notification{}).
+			Where("user_id = ? AND type = ? AND message = ?", userID, models.NotificationTypePromotion, message).Count(&count)
+		return count > 0
+	}
+
+	t.Run("Send to all customers in batches", func(t *testing.T) {
+		w, promotion := createPromotion(map[string]interface{}{"message": "20% off this weekend", "audience": "all"})
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, 4, promotion.TotalRecipients)
+
+		worker.DeliverDue()
+		testDB.First(&promotion, promotion.ID)
+		assert.Equal(t, models.PromotionStatusSending, promotion.Status)
+		assert.Equal(t, 2, promotion.SentCount)
+
+		worker.DeliverDue()
+		worker.DeliverDue()
+		testDB.First(&promotion, promotion.ID)
+		assert.Equal(t, models.PromotionStatusCompleted, promotion.Status)
+		assert.Equal(t, 3, promotion.SentCount)
+		assert.Equal(t, 1, promotion.SkippedCount)
+
+		assert.True(t, received(customers[2].ID, "20% off this weekend"))
+		assert.False(t, received(customers[3].ID, "20% off this weekend"))
+	})
+
+	t.Run("Send to recent guests", func(t *testing.T) {
+		w, promotion := createPromotion(map[string]interface{}{"message": "Welcome back", "audience": "recent_reservations"})
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, 90, promotion.AudienceDays)
+		assert.Equal(t, 1, promotion.TotalRecipients)
+
+		worker.DeliverDue()
+		assert.True(t, received(customers[0].ID, "Welcome back"))
+		assert.False(t, received(customers[1].ID, "Welcome back"))
+	})
+
+	t.Run("Send to customers who ordered a menu item", func(t *testing.T) {
+		w, promotion := createPromotion(map[string]interface{}{"message": "Steak night", "audience": "menu_item", "menu_item_id": steak.ID})
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, 1, promotion.TotalRecipients)
+
+		worker.DeliverDue()
+		assert.True(t, received(customers[1].ID, "Steak night"))
+	})
+
+	t.Run("Reject invalid audience", func(t *testing.T) {
+		w, _ := createPromotion(map[string]interface{}{"message": "Hello", "audience": "users", "user_ids": []uint{customers[0].ID, 9999}})
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+
+		w, _ = createPromotion(map[string]interface{}{"message": "Hello", "audience": "menu_item"})
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("Cancel promotion", func(t *testing.T) {
+		w, promotion := createPromotion(map[string]interface{}{"message": "Never sent", "audience": "users", "user_ids": []uint{customers[2].ID}})
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		req, _ := http.NewRequest("POST", fmt.Sprintf("/api/v1/admin/promotions/%d/cancel", promotion.ID), nil)
+		req.Header.Set("Authorization", "Bearer "+adminToken)
+		w = httptest.NewRecorder()
+		testRouter.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		worker.DeliverDue()
+		assert.False(t, received(customers[2].ID, "Never sent"))
+	})
+}
@@ -0,0 +1,115 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"restaurant-booking-backend/config"
+	"restaurant-booking-backend/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReservationDuration(t *testing.T) {
+	SetupTestEnvironment(t)
+	defer CleanupTestEnvironment(t)
+
+	CreateTestUser("09123456789", "password123", "Test User", models.RoleCustomer)
+	table, _ := CreateTestTable(1, 4, "Window", models.TableStatusAvailable)
+	shortTable, _ := CreateTestTable(2, 4, "Bar", models.TableStatusAvailable)
+	config.DB.Model(shortTable).Update("reservation_duration", 60)
+	userToken := getAuthToken(t, "09123456789", "password123")
+
+	tomorrow := time.Now().Add(24 * time.Hour)
+
+	reserve := func(tableID uint, date time.Time, clock string, duration int) (int, map[string]interface{}) {
+		payload := map[string]interface{}{
+			"table_id":   tableID,
+			"date":       date.Format("2006-01-02"),
+			"time":       clock,
+			"party_size": 2,
+		}
+		if duration != 0 {
+			payload["duration"] = duration
+		}
+		jsonValue, _ := json.Marshal(payload)
+
+		req, _ := http.NewRequest("POST", "/api/v1/reservations", bytes.NewBuffer(jsonValue))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+userToken)
+		w := httptest.NewRecorder()
+		testRouter.ServeHTTP(w, req)
+
+		var response map[string]interface{}
+		json.Unmarshal(w.Body.Bytes(), &response)
+		return w.Code, response
+	}
+
+	t.Run("Use restaurant default duration", func(t *testing.T) {
+		code, response := reserve(table.ID, tomorrow, "12:00", 0)
+
+		assert.Equal(t, http.StatusOK, code)
+		data := response["data"].(map[string]interface{})
+		assert.Equal(t, float64(config.GetDefaultReservationDuration()), data["duration"])
+	})
+
+	t.Run("Use table default duration", func(t *testing.T) {
+		code, response := reserve(shortTable.ID, tomorrow, "12:00", 0)
+
+		assert.Equal(t, http.StatusOK, code)
+		data := response["data"].(map[string]interface{})
+		assert.Equal(t, float64(60), data["duration"])
+	})
+
+	t.Run("Requested duration overrides defaults", func(t *testing.T) {
+		code, response := reserve(shortTable.ID, tomorrow, "13:00", 45)
+
+		assert.Equal(t, http.StatusOK, code)
+		data := response["data"].(map[string]interface{})
+		assert.Equal(t, float64(45), data["duration"])
+	})
+
+	t.Run("Reject duration above the maximum", func(t *testing.T) {
+		code, _ := reserve(table.ID, tomorrow, "17:00", config.GetMaxReservationDuration()+1)
+
+		assert.Equal(t, http.StatusBadRequest, code)
+	})
+
+	t.Run("Reject booking that overlaps an earlier reservation", func(t *testing.T) {
+		// The 12:00 booking runs until 14:00 with the default duration
+		code, _ := reserve(table.ID, tomorrow, "13:30", 60)
+
+		assert.Equal(t, http.StatusConflict, code)
+	})
+
+	t.Run("Accept booking that starts when the previous one ends", func(t *testing.T) {
+		code, _ := reserve(table.ID, tomorrow, "14:00", 0)
+
+		assert.Equal(t, http.StatusOK, code)
+	})
+
+	t.Run("Reject booking that runs into a later reservation", func(t *testing.T) {
+		code, _ := reserve(table.ID, tomorrow, "20:00", 0)
+		assert.Equal(t, http.StatusOK, code)
+
+		// 19:00 for 90 minutes ends at 20:30, inside the 20:00 booking
+		code, _ = reserve(table.ID, tomorrow, "19:00", 90)
+		assert.Equal(t, http.StatusConflict, code)
+	})
+
+	t.Run("Accept booking that ends when the next one starts", func(t *testing.T) {
+		code, _ := reserve(table.ID, tomorrow, "18:00", 120)
+
+		assert.Equal(t, http.StatusOK, code)
+	})
+
+	t.Run("Overlap is checked per table", func(t *testing.T) {
+		code, _ := reserve(shortTable.ID, tomorrow, "19:00", 90)
+
+		assert.Equal(t, http.StatusOK, code)
+	})
+}
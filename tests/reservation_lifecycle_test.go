@@ -0,0 +1,131 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"restaurant-booking-backend/models"
+	"restaurant-booking-backend/services"
+	"restaurant-booking-backend/utils"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func createLifecycleReservation(userID, tableID uint, start time.Time, status models.ReservationStatus) models.Reservation {
+	date, clock := utils.SplitDateTime(start)
+	reservation := models.Reservation{
+		UserID:    userID,
+		TableID:   tableID,
+		Date:      date,
+		Time:      clock,
+		Duration:  60,
+		PartySize: 2,
+		Status:    status,
+	}
+	testDB.Create(&reservation)
+	return reservation
+}
+
+func TestReservationLifecycleJobs(t *testing.T) {
+	SetupTestEnvironment(t)
+	defer CleanupTestEnvironment(t)
+
+	user, _ := CreateTestUser("09123456789", "password123", "Test User", models.RoleCustomer)
+	table, _ := CreateTestTable(1, 4, "Window", models.TableStatusAvailable)
+	now := time.Now()
+	lifecycleService := &services.ReservationLifecycleService{}
+
+	t.Run("Complete past seated reservations", func(t *testing.T) {
+		past := createLifecycleReservation(user.ID, table.ID, now.Add(-2*time.Hour), models.ReservationStatusSeated)
+		current := createLifecycleReservation(user.ID, table.ID, now.Add(-30*time.Minute), models.ReservationStatusSeated)
+
+		count, err := lifecycleService.CompletePastReservations()
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, count)
+		testDB.First(&past, past.ID)
+		testDB.First(&current, current.ID)
+		assert.Equal(t, models.ReservationStatusCompleted, past.Status)
+		assert.Equal(t, models.ReservationStatusSeated, current.Status)
+		assert.NotNil(t, past.CompletedAt)
+	})
+
+	t.Run("Expire unconfirmed reservations", func(t *testing.T) {
+		started := createLifecycleReservation(user.ID, table.ID, now.Add(-10*time.Minute), models.ReservationStatusPending)
+		upcoming := createLifecycleReservation(user.ID, table.ID, now.Add(3*time.Hour), models.ReservationStatusPending)
+
+		count, err := lifecycleService.ExpireUnconfirmedReservations()
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, count)
+		testDB.First(&started, started.ID)
+		testDB.First(&upcoming, upcoming.ID)
+		assert.Equal(t, models.ReservationStatusCancelled, started.Status)
+		assert.Equal(t, models.ReservationStatusPending, upcoming.Status)
+	})
+
+	t.Run("Send reminder once", func(t *testing.T) {
+		reservation := createLifecycleReservation(user.ID, table.ID, now.Add(time.Hour), models.ReservationStatusConfirmed)
+		testDB.Model(&reservation).Update("created_at", now.AddDate(0, 0, -2))
+
+		count, err := lifecycleService.SendReminders()
+		assert.NoError(t, err)
+		assert.Equal(t, 1, count)
+
+		count, err = lifecycleService.SendReminders()
+		assert.NoError(t, err)
+		assert.Equal(t, 0, count)
+
+		// The 24 hour reminder was due as well, but only the closest one is sent
+		var reminders int64
+		testDB.Model(&models.ReservationReminder{}).Where("reservation_id = ?", reservation.ID).Count(&reminders)
+		assert.Equal(t, int64(2), reminders)
+	})
+
+	t.Run("Skip reminders that were due before booking", func(t *testing.T) {
+		createLifecycleReservation(user.ID, table.ID, now.Add(time.Hour), models.ReservationStatusConfirmed)
+
+		count, err := lifecycleService.SendReminders()
+		assert.NoError(t, err)
+		assert.Equal(t, 0, count)
+	})
+
+	t.Run("Skip users who opted out", func(t *testing.T) {
+		testDB.Model(user).Update("reminders_disabled", true)
+		reservation := createLifecycleReservation(user.ID, table.ID, now.Add(90*time.Minute), models.ReservationStatusConfirmed)
+		testDB.Model(&reservation).Update("created_at", now.AddDate(0, 0, -2))
+
+		count, err := lifecycleService.SendReminders()
+		assert.NoError(t, err)
+		assert.Equal(t, 0, count)
+	})
+}
+
+func TestScheduler(t *testing.T) {
+	SetupTestEnvironment(t)
+	defer CleanupTestEnvironment(t)
+
+	runs := 0
+	scheduler := services.NewScheduler(10 * time.Millisecond)
+	scheduler.Register(services.Job{
+		Name:     "test_job",
+		Interval: time.Hour,
+		Run: func() error {
+			runs++
+			return nil
+		},
+	})
+
+	assert.NoError(t, scheduler.Start())
+	time.Sleep(100 * time.Millisecond)
+	scheduler.Stop(time.Second)
+
+	// Due once on start, then not again until the interval passes
+	assert.Equal(t, 1, runs)
+
+	var job models.ScheduledJob
+	testDB.Where("name = ?", "test_job").First(&job)
+	assert.Equal(t, 1, job.RunCount)
+	assert.NotNil(t, job.LastRunAt)
+	assert.True(t, job.NextRunAt.After(time.Now()))
+}
@@ -5,10 +5,13 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"os"
 	"testing"
 	"time"
 
 	"restaurant-booking-backend/models"
+	"restaurant-booking-backend/services"
+	"restaurant-booking-backend/utils"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -18,16 +21,17 @@ func TestCreateReservation(t *testing.T) {
 	defer CleanupTestEnvironment(t)
 
 	// Create test user and table
-	user, _ := CreateTestUser("09123456789", "password123", "Test User", models.RoleCustomer)
+	CreateTestUser("09123456789", "password123", "Test User", models.RoleCustomer)
 	table, _ := CreateTestTable(1, 4, "Window", models.TableStatusAvailable)
 	userToken := getAuthToken(t, "09123456789", "password123")
 
 	t.Run("Create reservation", func(t *testing.T) {
 		futureDate := time.Now().Add(24 * time.Hour)
 		payload := map[string]interface{}{
-			"table_id": table.ID,
-			"date":     futureDate.Format("2006-01-02"),
-			"time":     "19:00",
+			"table_id":   table.ID,
+			"date":       futureDate.Format("2006-01-02"),
+			"time":       "19:00",
+			"party_size": 2,
 		}
 		jsonValue, _ := json.Marshal(payload)
 
@@ -43,12 +47,52 @@ func TestCreateReservation(t *testing.T) {
 		assert.True(t, response["success"].(bool))
 	})
 
+	t.Run("Create overlapping reservation", func(t *testing.T) {
+		futureDate := time.Now().Add(24 * time.Hour)
+		payload := map[string]interface{}{
+			"table_id":   table.ID,
+			"date":       futureDate.Format("2006-01-02"),
+			"time":       "19:30",
+			"party_size": 2,
+		}
+		jsonValue, _ := json.Marshal(payload)
+
+		req, _ := http.NewRequest("POST", "/api/v1/reservations", bytes.NewBuffer(jsonValue))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+userToken)
+		w := httptest.NewRecorder()
+		testRouter.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+
+	t.Run("Create reservation on same table after previous one ends", func(t *testing.T) {
+		futureDate := time.Now().Add(24 * time.Hour)
+		payload := map[string]interface{}{
+			"table_id":   table.ID,
+			"date":       futureDate.Format("2006-01-02"),
+			"time":       "21:00",
+			"party_size": 2,
+			"duration":   90,
+		}
+		jsonValue, _ := json.Marshal(payload)
+
+		req, _ := http.NewRequest("POST", "/api/v1/reservations", bytes.NewBuffer(jsonValue))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+userToken)
+		w := httptest.NewRecorder()
+		testRouter.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
 	t.Run("Create reservation with past date", func(t *testing.T) {
 		pastDate := time.Now().Add(-24 * time.Hour)
 		payload := map[string]interface{}{
-			"table_id": table.ID,
-			"date":     pastDate.Format("2006-01-02"),
-			"time":     "19:00",
+			"table_id":   table.ID,
+			"date":       pastDate.Format("2006-01-02"),
+			"time":       "19:00",
+			"party_size": 2,
 		}
 		jsonValue, _ := json.Marshal(payload)
 
@@ -61,12 +105,36 @@ func TestCreateReservation(t *testing.T) {
 		assert.Equal(t, http.StatusBadRequest, w.Code)
 	})
 
+	t.Run("Create reservation exceeding table capacity", func(t *testing.T) {
+		smallTable, _ := CreateTestTable(2, 2, "Bar", models.TableStatusAvailable)
+		futureDate := time.Now().Add(48 * time.Hour)
+		payload := map[string]interface{}{
+			"table_id":   smallTable.ID,
+			"date":       futureDate.Format("2006-01-02"),
+			"time":       "19:00",
+			"party_size": 8,
+		}
+		jsonValue, _ := json.Marshal(payload)
+
+		req, _ := http.NewRequest("POST", "/api/v1/reservations", bytes.NewBuffer(jsonValue))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+userToken)
+		w := httptest.NewRecorder()
+		testRouter.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		var response map[string]interface{}
+		json.Unmarshal(w.Body.Bytes(), &response)
+		assert.Equal(t, "party_exceeds_capacity", response["code"])
+	})
+
 	t.Run("Create reservation without auth", func(t *testing.T) {
 		futureDate := time.Now().Add(24 * time.Hour)
 		payload := map[string]interface{}{
-			"table_id": table.ID,
-			"date":     futureDate.Format("2006-01-02"),
-			"time":     "19:00",
+			"table_id":   table.ID,
+			"date":       futureDate.Format("2006-01-02"),
+			"time":       "19:00",
+			"party_size": 2,
 		}
 		jsonValue, _ := json.Marshal(payload)
 
@@ -79,11 +147,112 @@ func TestCreateReservation(t *testing.T) {
 	})
 }
 
+func TestCreateReservationWithAutomaticAssignment(t *testing.T) {
+	SetupTestEnvironment(t)
+	defer CleanupTestEnvironment(t)
+
+	CreateTestUser("09123456789", "password123", "Test User", models.RoleCustomer)
+	CreateTestTable(1, 6, "Window", models.TableStatusAvailable)
+	smallTable, _ := CreateTestTable(2, 2, "Window", models.TableStatusAvailable)
+	userToken := getAuthToken(t, "09123456789", "password123")
+	futureDate := time.Now().Add(24 * time.Hour)
+
+	t.Run("Assign smallest sufficient table", func(t *testing.T) {
+		payload := map[string]interface{}{
+			"date":       futureDate.Format("2006-01-02"),
+			"time":       "19:00",
+			"party_size": 2,
+			"location":   "window",
+		}
+		jsonValue, _ := json.Marshal(payload)
+
+		req, _ := http.NewRequest("POST", "/api/v1/reservations", bytes.NewBuffer(jsonValue))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+userToken)
+		w := httptest.NewRecorder()
+		testRouter.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var response map[string]interface{}
+		json.Unmarshal(w.Body.Bytes(), &response)
+		data := response["data"].(map[string]interface{})
+		assert.Equal(t, float64(smallTable.ID), data["table_id"])
+	})
+
+	t.Run("No table fits the party", func(t *testing.T) {
+		payload := map[string]interface{}{
+			"date":       futureDate.Format("2006-01-02"),
+			"time":       "19:00",
+			"party_size": 8,
+		}
+		jsonValue, _ := json.Marshal(payload)
+
+		req, _ := http.NewRequest("POST", "/api/v1/reservations", bytes.NewBuffer(jsonValue))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+userToken)
+		w := httptest.NewRecorder()
+		testRouter.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+		var response map[string]interface{}
+		json.Unmarshal(w.Body.Bytes(), &response)
+		assert.Equal(t, "no_table_available", response["code"])
+	})
+}
+
+func TestCreateReservationByAdmin(t *testing.T) {
+	SetupTestEnvironment(t)
+	defer CleanupTestEnvironment(t)
+
+	// Pending reservations are cancelled one minute after booking unless confirmed
+	os.Setenv("PENDING_CONFIRMATION_TIMEOUT", "1")
+	defer os.Unsetenv("PENDING_CONFIRMATION_TIMEOUT")
+
+	CreateTestUser("09111111111", "password123", "Admin User", models.RoleAdmin)
+	table, _ := CreateTestTable(1, 4, "Window", models.TableStatusAvailable)
+	adminToken := getAuthToken(t, "09111111111", "password123")
+
+	t.Run("Reservation booked by staff needs no confirmation", func(t *testing.T) {
+		futureDate := time.Now().Add(24 * time.Hour)
+		payload := map[string]interface{}{
+			"phone":      "09222222222",
+			"name":       "Guest",
+			"table_id":   table.ID,
+			"date":       futureDate.Format("2006-01-02"),
+			"time":       "19:00",
+			"party_size": 2,
+		}
+		jsonValue, _ := json.Marshal(payload)
+
+		req, _ := http.NewRequest("POST", "/api/v1/admin/reservations", bytes.NewBuffer(jsonValue))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+adminToken)
+		w := httptest.NewRecorder()
+		testRouter.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var response map[string]interface{}
+		json.Unmarshal(w.Body.Bytes(), &response)
+		data := response["data"].(map[string]interface{})
+		assert.Equal(t, "confirmed", data["status"])
+
+		// Past the confirmation timeout the reservation is left alone
+		var reservation models.Reservation
+		testDB.First(&reservation, uint(data["id"].(float64)))
+		testDB.Model(&reservation).Update("created_at", time.Now().Add(-time.Hour))
+		count, err := (&services.ReservationLifecycleService{}).ExpireUnconfirmedReservations()
+		assert.NoError(t, err)
+		assert.Equal(t, 0, count)
+		testDB.First(&reservation, reservation.ID)
+		assert.Equal(t, models.ReservationStatusConfirmed, reservation.Status)
+	})
+}
+
 func TestGetUserReservations(t *testing.T) {
 	SetupTestEnvironment(t)
 	defer CleanupTestEnvironment(t)
 
-	user, _ := CreateTestUser("09123456789", "password123", "Test User", models.RoleCustomer)
+	CreateTestUser("09123456789", "password123", "Test User", models.RoleCustomer)
 	userToken := getAuthToken(t, "09123456789", "password123")
 
 	t.Run("Get user reservations", func(t *testing.T) {
@@ -131,3 +300,290 @@ func TestCancelReservation(t *testing.T) {
 	})
 }
 
+func TestModifyReservation(t *testing.T) {
+	SetupTestEnvironment(t)
+	defer CleanupTestEnvironment(t)
+
+	user, _ := CreateTestUser("09123456789", "password123", "Test User", models.RoleCustomer)
+	other, _ := CreateTestUser("09129876543", "password123", "Other User", models.RoleCustomer)
+	table1, _ := CreateTestTable(1, 4, "Window", models.TableStatusAvailable)
+	table2, _ := CreateTestTable(2, 4, "Window", models.TableStatusAvailable)
+	userToken := getAuthToken(t, "09123456789", "password123")
+
+	futureDate, _ := utils.SplitDateTime(time.Now().Add(24 * time.Hour))
+	reservation := models.Reservation{
+		UserID:    user.ID,
+		TableID:   table1.ID,
+		Date:      futureDate,
+		Time:      time.Date(0, 1, 1, 19, 0, 0, 0, time.UTC),
+		Duration:  120,
+		PartySize: 2,
+		Status:    models.ReservationStatusPending,
+	}
+	testDB.Create(&reservation)
+	testDB.Create(&models.Reservation{
+		UserID:    other.ID,
+		TableID:   table2.ID,
+		Date:      futureDate,
+		Time:      time.Date(0, 1, 1, 20, 0, 0, 0, time.UTC),
+		Duration:  120,
+		PartySize: 2,
+		Status:    models.ReservationStatusConfirmed,
+	})
+
+	t.Run("Move reservation to a reserved table", func(t *testing.T) {
+		payload := map[string]interface{}{
+			"table_id": table2.ID,
+		}
+		jsonValue, _ := json.Marshal(payload)
+
+		req, _ := http.NewRequest("PUT", "/api/v1/reservations/1", bytes.NewBuffer(jsonValue))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+userToken)
+		w := httptest.NewRecorder()
+		testRouter.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+		var response map[string]interface{}
+		json.Unmarshal(w.Body.Bytes(), &response)
+		assert.Equal(t, "table_already_reserved", response["code"])
+	})
+
+	t.Run("Change time and party size", func(t *testing.T) {
+		payload := map[string]interface{}{
+			"time":       "20:30",
+			"party_size": 4,
+		}
+		jsonValue, _ := json.Marshal(payload)
+
+		req, _ := http.NewRequest("PUT", "/api/v1/reservations/1", bytes.NewBuffer(jsonValue))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+userToken)
+		w := httptest.NewRecorder()
+		testRouter.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var response map[string]interface{}
+		json.Unmarshal(w.Body.Bytes(), &response)
+		data := response["data"].(map[string]interface{})
+		assert.Equal(t, float64(4), data["party_size"])
+		assert.Equal(t, float64(table1.ID), data["table_id"])
+	})
+
+	t.Run("Modify reservation of another user", func(t *testing.T) {
+		payload := map[string]interface{}{
+			"time": "18:00",
+		}
+		jsonValue, _ := json.Marshal(payload)
+
+		req, _ := http.NewRequest("PUT", "/api/v1/reservations/2", bytes.NewBuffer(jsonValue))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+userToken)
+		w := httptest.NewRecorder()
+		testRouter.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func TestUpdateReservationStatus(t *testing.T) {
+	SetupTestEnvironment(t)
+	defer CleanupTestEnvironment(t)
+
+	user, _ := CreateTestUser("09123456789", "password123", "Test User", models.RoleCustomer)
+	CreateTestUser("09111111111", "password123", "Admin User", models.RoleAdmin)
+	table, _ := CreateTestTable(1, 4, "Window", models.TableStatusAvailable)
+	adminToken := getAuthToken(t, "09111111111", "password123")
+
+	futureDate := time.Now().Add(24 * time.Hour)
+	reservation := models.Reservation{
+		UserID:    user.ID,
+		TableID:   table.ID,
+		Date:      futureDate,
+		Time:      time.Date(0, 0, 0, 19, 0, 0, 0, time.UTC),
+		Duration:  120,
+		PartySize: 2,
+		Status:    models.ReservationStatusPending,
+	}
+	testDB.Create(&reservation)
+
+	t.Run("Complete a pending reservation", func(t *testing.T) {
+		payload := map[string]interface{}{
+			"status": "completed",
+		}
+		jsonValue, _ := json.Marshal(payload)
+
+		req, _ := http.NewRequest("PUT", "/api/v1/admin/reservations/1/status", bytes.NewBuffer(jsonValue))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+adminToken)
+		w := httptest.NewRecorder()
+		testRouter.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+		var response map[string]interface{}
+		json.Unmarshal(w.Body.Bytes(), &response)
+		assert.Equal(t, "invalid_status_transition", response["code"])
+	})
+
+	t.Run("Confirm a pending reservation", func(t *testing.T) {
+		payload := map[string]interface{}{
+			"status": "confirmed",
+		}
+		jsonValue, _ := json.Marshal(payload)
+
+		req, _ := http.NewRequest("PUT", "/api/v1/admin/reservations/1/status", bytes.NewBuffer(jsonValue))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+adminToken)
+		w := httptest.NewRecorder()
+		testRouter.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("Mark reservation as no-show before it starts", func(t *testing.T) {
+		payload := map[string]interface{}{
+			"status": "no_show",
+		}
+		jsonValue, _ := json.Marshal(payload)
+
+		req, _ := http.NewRequest("PUT", "/api/v1/admin/reservations/1/status", bytes.NewBuffer(jsonValue))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+adminToken)
+		w := httptest.NewRecorder()
+		testRouter.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+		var response map[string]interface{}
+		json.Unmarshal(w.Body.Bytes(), &response)
+		assert.Equal(t, "no_show_before_start", response["code"])
+	})
+
+	t.Run("Cancel and re-open a reservation", func(t *testing.T) {
+		req, _ := http.NewRequest("DELETE", "/api/v1/admin/reservations/1", nil)
+		req.Header.Set("Authorization", "Bearer "+adminToken)
+		w := httptest.NewRecorder()
+		testRouter.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		payload := map[string]interface{}{
+			"status": "confirmed",
+		}
+		jsonValue, _ := json.Marshal(payload)
+
+		req, _ = http.NewRequest("PUT", "/api/v1/admin/reservations/1/status", bytes.NewBuffer(jsonValue))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+adminToken)
+		w = httptest.NewRecorder()
+		testRouter.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+
+	t.Run("Get reservation history", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/api/v1/admin/reservations/1/history", nil)
+		req.Header.Set("Authorization", "Bearer "+adminToken)
+		w := httptest.NewRecorder()
+		testRouter.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var response map[string]interface{}
+		json.Unmarshal(w.Body.Bytes(), &response)
+		history := response["data"].([]interface{})
+		assert.Len(t, history, 2)
+		last := history[1].(map[string]interface{})
+		assert.Equal(t, "confirmed", last["from_status"])
+		assert.Equal(t, "cancelled", last["to_status"])
+	})
+}
+
+func TestReservationConfirmationLink(t *testing.T) {
+	SetupTestEnvironment(t)
+	defer CleanupTestEnvironment(t)
+
+	user, _ := CreateTestUser("09123456789", "password123", "Test User", models.RoleCustomer)
+	table, _ := CreateTestTable(1, 4, "Window", models.TableStatusAvailable)
+
+	date, clock := utils.SplitDateTime(time.Now().Add(5 * time.Hour))
+	newReservation := func() models.Reservation {
+		reservation := models.Reservation{
+			UserID:    user.ID,
+			TableID:   table.ID,
+			Date:      date,
+			Time:      clock,
+			Duration:  60,
+			PartySize: 2,
+			Status:    models.ReservationStatusPending,
+		}
+		testDB.Create(&reservation)
+		return reservation
+	}
+	post := func(path string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("POST", path, nil)
+		w := httptest.NewRecorder()
+		testRouter.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("Confirm without logging in", func(t *testing.T) {
+		reservation := newReservation()
+		token, _ := utils.GenerateReservationToken(reservation.ID, reservation.Version, time.Now().Add(time.Hour))
+
+		w := post("/api/v1/reservation-links/" + token + "/confirm")
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		testDB.First(&reservation, reservation.ID)
+		assert.Equal(t, models.ReservationStatusConfirmed, reservation.Status)
+
+		// Confirming twice is not a valid transition
+		w = post("/api/v1/reservation-links/" + token + "/confirm")
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+
+	t.Run("Cancel without logging in", func(t *testing.T) {
+		reservation := newReservation()
+		token, _ := utils.GenerateReservationToken(reservation.ID, reservation.Version, time.Now().Add(time.Hour))
+
+		w := post("/api/v1/reservation-links/" + token + "/cancel")
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		testDB.First(&reservation, reservation.ID)
+		assert.Equal(t, models.ReservationStatusCancelled, reservation.Status)
+	})
+
+	t.Run("Expired link", func(t *testing.T) {
+		reservation := newReservation()
+		token, _ := utils.GenerateReservationToken(reservation.ID, reservation.Version, time.Now().Add(-time.Minute))
+
+		w := post("/api/v1/reservation-links/" + token + "/confirm")
+		assert.Equal(t, http.StatusGone, w.Code)
+	})
+
+	t.Run("Link sent before the reservation was modified", func(t *testing.T) {
+		reservation := newReservation()
+		token, _ := utils.GenerateReservationToken(reservation.ID, reservation.Version, time.Now().Add(time.Hour))
+		testDB.Model(&reservation).Update("version", reservation.Version+1)
+
+		w := post("/api/v1/reservation-links/" + token + "/confirm")
+		assert.Equal(t, http.StatusGone, w.Code)
+
+		testDB.First(&reservation, reservation.ID)
+		assert.Equal(t, models.ReservationStatusPending, reservation.Status)
+	})
+
+	t.Run("Login token is not a valid link", func(t *testing.T) {
+		token, _ := utils.GenerateToken(user.ID, user.Phone, string(user.Role))
+
+		w := post("/api/v1/reservation-links/" + token + "/confirm")
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("Deposit required", func(t *testing.T) {
+		reservation := newReservation()
+		testDB.Model(&reservation).Update("deposit_amount", 100)
+		token, _ := utils.GenerateReservationToken(reservation.ID, reservation.Version, time.Now().Add(time.Hour))
+
+		w := post("/api/v1/reservation-links/" + token + "/confirm")
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+}
@@ -3,6 +3,7 @@ package tests
 import (
 	"bytes"
 	"encoding/json"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"os"
@@ -12,13 +13,13 @@ import (
 	"restaurant-booking-backend/models"
 	"restaurant-booking-backend/routes"
 
-	"github.com/gin-gonic/gin"
+	"github.com/gofiber/fiber/v2"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 )
 
 var testDB *gorm.DB
-var testRouter *gin.Engine
+var testRouter *TestRouter
 
 // SetupTestDB sets up a test database
 func SetupTestDB(t *testing.T) *gorm.DB {
@@ -28,14 +29,50 @@ func SetupTestDB(t *testing.T) *gorm.DB {
 		t.Fatalf("Failed to connect to test database: %v", err)
 	}
 
-	// Auto migrate all models
-	err = db.AutoMigrate(
+	// A single connection keeps every query on the same in-memory database
+	sqlDB, _ := db.DB()
+	sqlDB.SetMaxOpenConns(1)
+
+	testModels := []interface{}{
 		&models.User{},
 		&models.Table{},
 		&models.MenuItem{},
 		&models.Reservation{},
 		&models.Notification{},
-	)
+		&models.Category{},
+		&models.Order{},
+		&models.OrderItem{},
+		&models.OpeningHours{},
+		&models.CalendarException{},
+		&models.TableCombination{},
+		&models.WaitlistEntry{},
+		&models.WalkIn{},
+		&models.StatusHistory{},
+		&models.BookingPolicy{},
+		&models.ScheduledJob{},
+		&models.ReservationReminder{},
+		&models.NotificationPreference{},
+		&models.OutboxMessage{},
+		&models.FloorEvent{},
+		&models.NotificationTemplate{},
+		&models.Promotion{},
+	}
+
+	// SQLite only reads date and datetime columns back as time.Time, so store times of day as datetime
+	for _, model := range testModels {
+		statement := &gorm.Statement{DB: db}
+		if err := statement.Parse(model); err != nil {
+			t.Fatalf("Failed to parse model: %v", err)
+		}
+		for _, field := range statement.Schema.Fields {
+			if field.DataType == "time" {
+				field.DataType = "datetime"
+			}
+		}
+	}
+
+	// Auto migrate all models
+	err = db.AutoMigrate(testModels...)
 	if err != nil {
 		t.Fatalf("Failed to migrate test database: %v", err)
 	}
@@ -43,19 +80,40 @@ func SetupTestDB(t *testing.T) *gorm.DB {
 	return db
 }
 
+// TestRouter serves test requests through the Fiber app like an http.Handler
+type TestRouter struct {
+	app *fiber.App
+}
+
+// ServeHTTP runs the request through the app and copies the response into w
+func (tr *TestRouter) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	resp, err := tr.app.Test(req, -1)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer resp.Body.Close()
+
+	for key, values := range resp.Header {
+		for _, value := range values {
+			w.Header().Add(key, value)
+		}
+	}
+	w.WriteHeader(resp.StatusCode)
+	io.Copy(w, resp.Body)
+}
+
 // SetupTestRouter sets up a test router
-func SetupTestRouter() *gin.Engine {
-	gin.SetMode(gin.TestMode)
-	router := gin.New()
-	routes.SetupRoutes(router)
-	return router
+func SetupTestRouter() *TestRouter {
+	app := fiber.New()
+	routes.SetupRoutes(app)
+	return &TestRouter{app: app}
 }
 
 // SetupTestEnvironment sets up the test environment
 func SetupTestEnvironment(t *testing.T) {
 	// Set test environment variables
 	os.Setenv("JWT_SECRET", "test-secret-key")
-	os.Setenv("GIN_MODE", "test")
 
 	// Setup test database
 	testDB = SetupTestDB(t)
@@ -0,0 +1,104 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"restaurant-booking-backend/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTableCombinations(t *testing.T) {
+	SetupTestEnvironment(t)
+	defer CleanupTestEnvironment(t)
+
+	CreateTestUser("09111111111", "password123", "Admin User", models.RoleAdmin)
+	table1, _ := CreateTestTable(1, 4, "Window", models.TableStatusAvailable)
+	table2, _ := CreateTestTable(2, 4, "Window", models.TableStatusAvailable)
+	adminToken := getAuthToken(t, "09111111111", "password123")
+	futureDate := time.Now().Add(24 * time.Hour)
+
+	t.Run("Create table combination with a single table", func(t *testing.T) {
+		payload := map[string]interface{}{
+			"name":      "Window 1",
+			"table_ids": []uint{table1.ID},
+		}
+		jsonValue, _ := json.Marshal(payload)
+
+		req, _ := http.NewRequest("POST", "/api/v1/admin/table-combinations", bytes.NewBuffer(jsonValue))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+adminToken)
+		w := httptest.NewRecorder()
+		testRouter.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("Create table combination as admin", func(t *testing.T) {
+		payload := map[string]interface{}{
+			"name":      "Window 1+2",
+			"table_ids": []uint{table1.ID, table2.ID},
+		}
+		jsonValue, _ := json.Marshal(payload)
+
+		req, _ := http.NewRequest("POST", "/api/v1/admin/table-combinations", bytes.NewBuffer(jsonValue))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+adminToken)
+		w := httptest.NewRecorder()
+		testRouter.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var response map[string]interface{}
+		json.Unmarshal(w.Body.Bytes(), &response)
+		data := response["data"].(map[string]interface{})
+		assert.Equal(t, float64(8), data["capacity"])
+	})
+
+	t.Run("Reserve table combination for a large party", func(t *testing.T) {
+		payload := map[string]interface{}{
+			"phone":                "09222222222",
+			"name":                 "Guest",
+			"table_combination_id": 1,
+			"date":                 futureDate.Format("2006-01-02"),
+			"time":                 "19:00",
+			"party_size":           7,
+		}
+		jsonValue, _ := json.Marshal(payload)
+
+		req, _ := http.NewRequest("POST", "/api/v1/admin/reservations", bytes.NewBuffer(jsonValue))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+adminToken)
+		w := httptest.NewRecorder()
+		testRouter.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("Reserve member table of a booked combination", func(t *testing.T) {
+		payload := map[string]interface{}{
+			"phone":      "09333333333",
+			"name":       "Guest",
+			"table_id":   table2.ID,
+			"date":       futureDate.Format("2006-01-02"),
+			"time":       "19:30",
+			"party_size": 2,
+		}
+		jsonValue, _ := json.Marshal(payload)
+
+		req, _ := http.NewRequest("POST", "/api/v1/admin/reservations", bytes.NewBuffer(jsonValue))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+adminToken)
+		w := httptest.NewRecorder()
+		testRouter.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+		var response map[string]interface{}
+		json.Unmarshal(w.Body.Bytes(), &response)
+		assert.Equal(t, "table_already_reserved", response["code"])
+	})
+}
@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"restaurant-booking-backend/models"
 
@@ -56,3 +57,32 @@ func TestGetTableStatuses(t *testing.T) {
 	})
 }
 
+
+func TestGetTableAvailability(t *testing.T) {
+	SetupTestEnvironment(t)
+	defer CleanupTestEnvironment(t)
+
+	CreateTestTable(1, 4, "Window", models.TableStatusAvailable)
+	CreateTestTable(2, 2, "Bar", models.TableStatusAvailable)
+	futureDate := time.Now().Add(24 * time.Hour).Format("2006-01-02")
+
+	t.Run("Get table availability for time range", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/api/v1/tables/availability?date="+futureDate+"&party_size=2&from=18:00&to=21:00", nil)
+		w := httptest.NewRecorder()
+		testRouter.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var response map[string]interface{}
+		json.Unmarshal(w.Body.Bytes(), &response)
+		assert.True(t, response["success"].(bool))
+		assert.Len(t, response["data"], 2)
+	})
+
+	t.Run("Get table availability without party size", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/api/v1/tables/availability?date="+futureDate+"&time=19:00", nil)
+		w := httptest.NewRecorder()
+		testRouter.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
@@ -6,8 +6,10 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"restaurant-booking-backend/models"
+	"restaurant-booking-backend/utils"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -47,8 +49,8 @@ func TestUpdateUserRole(t *testing.T) {
 	SetupTestEnvironment(t)
 	defer CleanupTestEnvironment(t)
 
-	admin, _ := CreateTestUser("09111111111", "password123", "Admin", models.RoleAdmin)
-	customer, _ := CreateTestUser("09222222222", "password123", "Customer", models.RoleCustomer)
+	CreateTestUser("09111111111", "password123", "Admin", models.RoleAdmin)
+	CreateTestUser("09222222222", "password123", "Customer", models.RoleCustomer)
 	adminToken := getAuthToken(t, "09111111111", "password123")
 
 	t.Run("Update user role as admin", func(t *testing.T) {
@@ -92,3 +94,90 @@ func TestGetProfile(t *testing.T) {
 	})
 }
 
+func TestUserNoShowCount(t *testing.T) {
+	SetupTestEnvironment(t)
+	defer CleanupTestEnvironment(t)
+
+	CreateTestUser("09111111111", "password123", "Admin User", models.RoleAdmin)
+	user, _ := CreateTestUser("09123456789", "password123", "Test User", models.RoleCustomer)
+	table, _ := CreateTestTable(1, 4, "Window", models.TableStatusAvailable)
+	adminToken := getAuthToken(t, "09111111111", "password123")
+
+	// Reservation that started an hour ago
+	date, clock := utils.SplitDateTime(time.Now().Add(-time.Hour))
+	reservation := models.Reservation{
+		UserID:    user.ID,
+		TableID:   table.ID,
+		Date:      date,
+		Time:      clock,
+		Duration:  120,
+		PartySize: 2,
+		Status:    models.ReservationStatusConfirmed,
+	}
+	testDB.Create(&reservation)
+
+	t.Run("Mark reservation as no-show", func(t *testing.T) {
+		payload := map[string]interface{}{
+			"status": "no_show",
+		}
+		jsonValue, _ := json.Marshal(payload)
+
+		req, _ := http.NewRequest("PUT", "/api/v1/admin/reservations/1/status", bytes.NewBuffer(jsonValue))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+adminToken)
+		w := httptest.NewRecorder()
+		testRouter.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("Get user with no-show count", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/api/v1/admin/users/2", nil)
+		req.Header.Set("Authorization", "Bearer "+adminToken)
+		w := httptest.NewRecorder()
+		testRouter.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var response map[string]interface{}
+		json.Unmarshal(w.Body.Bytes(), &response)
+		data := response["data"].(map[string]interface{})
+		assert.Equal(t, float64(1), data["no_show_count"])
+	})
+}
+
+func TestUpdateReminderPreference(t *testing.T) {
+	SetupTestEnvironment(t)
+	defer CleanupTestEnvironment(t)
+
+	user, _ := CreateTestUser("09123456789", "password123", "Test User", models.RoleCustomer)
+	token := getAuthToken(t, "09123456789", "password123")
+
+	t.Run("Opt out of reminders", func(t *testing.T) {
+		payload := map[string]interface{}{
+			"enabled": false,
+		}
+		jsonValue, _ := json.Marshal(payload)
+
+		req, _ := http.NewRequest("PUT", "/api/v1/profile/reminders", bytes.NewBuffer(jsonValue))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		testRouter.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var updated models.User
+		testDB.First(&updated, user.ID)
+		assert.True(t, updated.RemindersDisabled)
+	})
+
+	t.Run("Missing enabled", func(t *testing.T) {
+		req, _ := http.NewRequest("PUT", "/api/v1/profile/reminders", bytes.NewBufferString("{}"))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		testRouter.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
@@ -0,0 +1,226 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"restaurant-booking-backend/models"
+	"restaurant-booking-backend/services"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWaitlist(t *testing.T) {
+	SetupTestEnvironment(t)
+	defer CleanupTestEnvironment(t)
+
+	CreateTestUser("09123456789", "password123", "Test User", models.RoleCustomer)
+	CreateTestTable(1, 4, "Window", models.TableStatusAvailable)
+	userToken := getAuthToken(t, "09123456789", "password123")
+	futureDate := time.Now().Add(24 * time.Hour)
+
+	t.Run("Join waitlist", func(t *testing.T) {
+		payload := map[string]interface{}{
+			"date":       futureDate.Format("2006-01-02"),
+			"time":       "19:00",
+			"party_size": 2,
+		}
+		jsonValue, _ := json.Marshal(payload)
+
+		req, _ := http.NewRequest("POST", "/api/v1/waitlist", bytes.NewBuffer(jsonValue))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+userToken)
+		w := httptest.NewRecorder()
+		testRouter.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var response map[string]interface{}
+		json.Unmarshal(w.Body.Bytes(), &response)
+		data := response["data"].(map[string]interface{})
+		assert.Equal(t, "waiting", data["status"])
+	})
+
+	t.Run("Join waitlist for a past time", func(t *testing.T) {
+		payload := map[string]interface{}{
+			"date":       time.Now().Add(-24 * time.Hour).Format("2006-01-02"),
+			"time":       "19:00",
+			"party_size": 2,
+		}
+		jsonValue, _ := json.Marshal(payload)
+
+		req, _ := http.NewRequest("POST", "/api/v1/waitlist", bytes.NewBuffer(jsonValue))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+userToken)
+		w := httptest.NewRecorder()
+		testRouter.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("Claim waitlist entry without an offer", func(t *testing.T) {
+		req, _ := http.NewRequest("POST", "/api/v1/waitlist/1/claim", nil)
+		req.Header.Set("Authorization", "Bearer "+userToken)
+		w := httptest.NewRecorder()
+		testRouter.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+}
+
+func TestWaitlistOfferClaim(t *testing.T) {
+	SetupTestEnvironment(t)
+	defer CleanupTestEnvironment(t)
+
+	// Pending reservations are cancelled one minute after booking unless confirmed
+	os.Setenv("PENDING_CONFIRMATION_TIMEOUT", "1")
+	defer os.Unsetenv("PENDING_CONFIRMATION_TIMEOUT")
+
+	owner, _ := CreateTestUser("09123456781", "password123", "Owner", models.RoleCustomer)
+	CreateTestUser("09123456782", "password123", "Waiting Guest", models.RoleCustomer)
+	table, _ := CreateTestTable(1, 4, "Window", models.TableStatusAvailable)
+	ownerToken := getAuthToken(t, "09123456781", "password123")
+	guestToken := getAuthToken(t, "09123456782", "password123")
+	futureDate, _ := time.Parse("2006-01-02", time.Now().Add(24*time.Hour).Format("2006-01-02"))
+
+	// offerFreedTable books the only table for the owner, puts the guest on the waitlist for the same
+	// time and cancels the booking, which offers the table to the guest
+	offerFreedTable := func(t *testing.T, hour int) models.WaitlistEntry {
+		booked := models.Reservation{
+			UserID:    owner.ID,
+			TableID:   table.ID,
+			Date:      futureDate,
+			Time:      time.Date(0, 1, 1, hour, 0, 0, 0, time.UTC),
+			Duration:  120,
+			PartySize: 2,
+			Status:    models.ReservationStatusConfirmed,
+		}
+		testDB.Create(&booked)
+
+		payload := map[string]interface{}{
+			"date":       futureDate.Format("2006-01-02"),
+			"time":       fmt.Sprintf("%02d:00", hour),
+			"party_size": 2,
+		}
+		jsonValue, _ := json.Marshal(payload)
+		req, _ := http.NewRequest("POST", "/api/v1/waitlist", bytes.NewBuffer(jsonValue))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+guestToken)
+		w := httptest.NewRecorder()
+		testRouter.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		req, _ = http.NewRequest("DELETE", fmt.Sprintf("/api/v1/reservations/%d", booked.ID), nil)
+		req.Header.Set("Authorization", "Bearer "+ownerToken)
+		w = httptest.NewRecorder()
+		testRouter.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var entry models.WaitlistEntry
+		testDB.Order("id DESC").First(&entry)
+		assert.Equal(t, models.WaitlistStatusOffered, entry.Status)
+		assert.NotNil(t, entry.ReservationID)
+		return entry
+	}
+
+	claim := func(entryID uint) int {
+		req, _ := http.NewRequest("POST", fmt.Sprintf("/api/v1/waitlist/%d/claim", entryID), nil)
+		req.Header.Set("Authorization", "Bearer "+guestToken)
+		w := httptest.NewRecorder()
+		testRouter.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	t.Run("Claimed offer confirms the held reservation", func(t *testing.T) {
+		entry := offerFreedTable(t, 19)
+
+		assert.Equal(t, http.StatusOK, claim(entry.ID))
+
+		var held models.Reservation
+		testDB.First(&held, *entry.ReservationID)
+		assert.Equal(t, models.ReservationStatusConfirmed, held.Status)
+
+		// Past the confirmation timeout the claimed reservation is left alone
+		testDB.Model(&held).Update("created_at", time.Now().Add(-time.Hour))
+		count, err := (&services.ReservationLifecycleService{}).ExpireUnconfirmedReservations()
+		assert.NoError(t, err)
+		assert.Equal(t, 0, count)
+		testDB.First(&held, held.ID)
+		assert.Equal(t, models.ReservationStatusConfirmed, held.Status)
+	})
+
+	t.Run("Claim after the offer expired", func(t *testing.T) {
+		entry := offerFreedTable(t, 21)
+		testDB.Model(&entry).Update("offer_expires_at", time.Now().Add(-time.Minute))
+
+		assert.Equal(t, http.StatusConflict, claim(entry.ID))
+
+		var held models.Reservation
+		testDB.First(&held, *entry.ReservationID)
+		assert.Equal(t, models.ReservationStatusPending, held.Status)
+	})
+
+	t.Run("Skip offer to a customer the booking policy blocks", func(t *testing.T) {
+		payload := map[string]interface{}{
+			"date":       futureDate.Format("2006-01-02"),
+			"time":       "17:00",
+			"party_size": 2,
+		}
+		jsonValue, _ := json.Marshal(payload)
+		req, _ := http.NewRequest("POST", "/api/v1/waitlist", bytes.NewBuffer(jsonValue))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+guestToken)
+		w := httptest.NewRecorder()
+		testRouter.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		// The guest misses a reservation while waiting
+		testDB.Create(&models.BookingPolicy{NoShowThreshold: 1, NoShowAction: models.NoShowPolicyBlock})
+		testDB.Model(&models.User{}).Where("phone = ?", "09123456782").Update("no_show_count", 1)
+
+		booked := models.Reservation{
+			UserID:    owner.ID,
+			TableID:   table.ID,
+			Date:      futureDate,
+			Time:      time.Date(0, 1, 1, 17, 0, 0, 0, time.UTC),
+			Duration:  120,
+			PartySize: 2,
+			Status:    models.ReservationStatusConfirmed,
+		}
+		testDB.Create(&booked)
+		req, _ = http.NewRequest("DELETE", fmt.Sprintf("/api/v1/reservations/%d", booked.ID), nil)
+		req.Header.Set("Authorization", "Bearer "+ownerToken)
+		w = httptest.NewRecorder()
+		testRouter.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var entry models.WaitlistEntry
+		testDB.Order("id DESC").First(&entry)
+		assert.Equal(t, models.WaitlistStatusWaiting, entry.Status)
+		assert.Nil(t, entry.ReservationID)
+	})
+
+	t.Run("Join waitlist while blocked by the booking policy", func(t *testing.T) {
+		payload := map[string]interface{}{
+			"date":       futureDate.Format("2006-01-02"),
+			"time":       "13:00",
+			"party_size": 2,
+		}
+		jsonValue, _ := json.Marshal(payload)
+		req, _ := http.NewRequest("POST", "/api/v1/waitlist", bytes.NewBuffer(jsonValue))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+guestToken)
+		w := httptest.NewRecorder()
+		testRouter.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		var response map[string]interface{}
+		json.Unmarshal(w.Body.Bytes(), &response)
+		assert.Equal(t, "customer_blocked", response["code"])
+	})
+}
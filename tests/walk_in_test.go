@@ -0,0 +1,100 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"restaurant-booking-backend/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWalkInQueue(t *testing.T) {
+	SetupTestEnvironment(t)
+	defer CleanupTestEnvironment(t)
+
+	CreateTestUser("09111111111", "password123", "Admin User", models.RoleAdmin)
+	CreateTestTable(1, 4, "Window", models.TableStatusAvailable)
+	adminToken := getAuthToken(t, "09111111111", "password123")
+
+	t.Run("Add walk-in as admin", func(t *testing.T) {
+		payload := map[string]interface{}{
+			"name":       "Guest",
+			"phone":      "09222222222",
+			"party_size": 2,
+		}
+		jsonValue, _ := json.Marshal(payload)
+
+		req, _ := http.NewRequest("POST", "/api/v1/admin/walk-ins", bytes.NewBuffer(jsonValue))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+adminToken)
+		w := httptest.NewRecorder()
+		testRouter.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var response map[string]interface{}
+		json.Unmarshal(w.Body.Bytes(), &response)
+		data := response["data"].(map[string]interface{})
+		assert.Equal(t, "waiting", data["status"])
+		assert.Equal(t, float64(0), data["quoted_wait"])
+	})
+
+	t.Run("Estimate wait for a party too large for any table", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/api/v1/admin/walk-ins/estimate?party_size=10", nil)
+		req.Header.Set("Authorization", "Bearer "+adminToken)
+		w := httptest.NewRecorder()
+		testRouter.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("Seat walk-in at a missing table", func(t *testing.T) {
+		payload := map[string]interface{}{
+			"table_id": 999,
+		}
+		jsonValue, _ := json.Marshal(payload)
+
+		req, _ := http.NewRequest("POST", "/api/v1/admin/walk-ins/1/seat", bytes.NewBuffer(jsonValue))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+adminToken)
+		w := httptest.NewRecorder()
+		testRouter.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("Remove walk-in", func(t *testing.T) {
+		req, _ := http.NewRequest("DELETE", "/api/v1/admin/walk-ins/1", nil)
+		req.Header.Set("Authorization", "Bearer "+adminToken)
+		w := httptest.NewRecorder()
+		testRouter.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("Estimate average turn time from seating to completion", func(t *testing.T) {
+		// Two parties held their table for 40 and 50 minutes; the one without timestamps is left out
+		for _, minutes := range []int{40, 50} {
+			completedAt := time.Now().Add(-time.Hour)
+			seatedAt := completedAt.Add(-time.Duration(minutes) * time.Minute)
+			reservation := createLifecycleReservation(1, 1, seatedAt, models.ReservationStatusCompleted)
+			testDB.Model(&reservation).Updates(models.Reservation{SeatedAt: &seatedAt, CompletedAt: &completedAt})
+		}
+		createLifecycleReservation(1, 1, time.Now().AddDate(0, 0, -2), models.ReservationStatusCompleted)
+
+		req, _ := http.NewRequest("GET", "/api/v1/admin/walk-ins/estimate?party_size=2", nil)
+		req.Header.Set("Authorization", "Bearer "+adminToken)
+		w := httptest.NewRecorder()
+		testRouter.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var response map[string]interface{}
+		json.Unmarshal(w.Body.Bytes(), &response)
+		data := response["data"].(map[string]interface{})
+		assert.Equal(t, float64(45), data["average_turn_time"])
+	})
+}
@@ -0,0 +1,51 @@
+package utils
+
+import (
+	"os"
+	"sync"
+	"time"
+)
+
+var (
+	restaurantLocation     *time.Location
+	restaurantLocationOnce sync.Once
+)
+
+// RestaurantLocation returns the restaurant time zone (RESTAURANT_TIMEZONE, defaults to Asia/Tehran)
+func RestaurantLocation() *time.Location {
+	restaurantLocationOnce.Do(func() {
+		name := os.Getenv("RESTAURANT_TIMEZONE")
+		if name == "" {
+			name = "Asia/Tehran"
+		}
+
+		loc, err := time.LoadLocation(name)
+		if err != nil {
+			// Fall back to fixed Tehran offset if tzdata is not available
+			loc = time.FixedZone("IRST", 3*60*60+30*60)
+		}
+		restaurantLocation = loc
+	})
+	return restaurantLocation
+}
+
+// CombineDateTime combines a reservation date and clock time into a single instant in restaurant time
+func CombineDateTime(date, clock time.Time) time.Time {
+	return time.Date(
+		date.Year(),
+		date.Month(),
+		date.Day(),
+		clock.Hour(),
+		clock.Minute(),
+		0, 0, RestaurantLocation(),
+	)
+}
+
+// SplitDateTime splits an instant into the reservation date and clock time in restaurant time
+// (the inverse of CombineDateTime, truncated to the minute)
+func SplitDateTime(t time.Time) (date, clock time.Time) {
+	local := t.In(RestaurantLocation())
+	date = time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, time.UTC)
+	clock = time.Date(0, 1, 1, local.Hour(), local.Minute(), 0, 0, time.UTC)
+	return date, clock
+}
@@ -0,0 +1,48 @@
+package utils
+
+import (
+	"strings"
+	"time"
+)
+
+// gregorianMonthDays days of a common Gregorian year before each month
+var gregorianMonthDays = [12]int{0, 31, 59, 90, 120, 151, 181, 212, 243, 273, 304, 334}
+
+// persianDigits replaces ASCII digits with Persian digits
+var persianDigits = strings.NewReplacer(
+	"0", "۰", "1", "۱", "2", "۲", "3", "۳", "4", "۴",
+	"5", "۵", "6", "۶", "7", "۷", "8", "۸", "9", "۹",
+)
+
+// ToJalali converts the date of t to the Jalali (Solar Hijri) calendar
+func ToJalali(t time.Time) (year, month, day int) {
+	gy, gm, gd := t.Year(), int(t.Month()), t.Day()
+
+	// Days since the Jalali epoch
+	gy2 := gy
+	if gm > 2 {
+		gy2 = gy + 1
+	}
+	days := 355666 + 365*gy + (gy2+3)/4 - (gy2+99)/100 + (gy2+399)/400 + gd + gregorianMonthDays[gm-1]
+
+	// 33 year cycles, then 4 year cycles, then years
+	year = -1595 + 33*(days/12053)
+	days %= 12053
+	year += 4 * (days / 1461)
+	days %= 1461
+	if days > 365 {
+		year += (days - 1) / 365
+		days = (days - 1) % 365
+	}
+
+	// The first six months have 31 days, the next five 30 and the last 29 (30 in leap years)
+	if days < 186 {
+		return year, 1 + days/31, 1 + days%31
+	}
+	return year, 7 + (days-186)/30, 1 + (days-186)%30
+}
+
+// PersianDigits replaces the ASCII digits of s with Persian digits
+func PersianDigits(s string) string {
+	return persianDigits.Replace(s)
+}
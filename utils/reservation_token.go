@@ -0,0 +1,62 @@
+package utils
+
+import (
+	"errors"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Reservation tokens are signed with their own key, so they can never be used as login tokens
+var reservationTokenSecret = []byte(getJWTSecret() + ":reservation-confirmation")
+
+// ErrReservationTokenExpired reservation token deadline has passed
+var ErrReservationTokenExpired = errors.New("reservation token has expired")
+
+// ReservationClaims reservation confirmation token claims structure
+type ReservationClaims struct {
+	ReservationID uint `json:"reservation_id"`
+	Version       int  `json:"version"` // Reservation version the token was issued for
+	jwt.RegisteredClaims
+}
+
+// GenerateReservationToken generates a signed token that lets the customer confirm or cancel a version of a
+// reservation until expiresAt
+func GenerateReservationToken(reservationID uint, version int, expiresAt time.Time) (string, error) {
+	claims := &ReservationClaims{
+		ReservationID: reservationID,
+		Version:       version,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString(reservationTokenSecret)
+}
+
+// ValidateReservationToken validates a reservation token and returns the reservation ID and version
+func ValidateReservationToken(tokenString string) (uint, int, error) {
+	claims := &ReservationClaims{}
+
+	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, errors.New("invalid signing method")
+		}
+		return reservationTokenSecret, nil
+	})
+
+	if errors.Is(err, jwt.ErrTokenExpired) {
+		return 0, 0, ErrReservationTokenExpired
+	}
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if !token.Valid || claims.ReservationID == 0 {
+		return 0, 0, errors.New("invalid token")
+	}
+
+	return claims.ReservationID, claims.Version, nil
+}
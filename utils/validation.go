@@ -6,7 +6,7 @@ import (
 )
 
 // ValidatePhoneNumber validates phone number format
-// Supports formats like: +989123456789, 09123456789, 9123456789, +989338467840
+// Supports formats like: +989123456789, 00989123456789, 09123456789, 9123456789, +989338467840
 func ValidatePhoneNumber(phone string) bool {
 	// Remove spaces, dashes, and other non-digit characters except +
 	cleanedPhone := strings.ReplaceAll(phone, " ", "")
@@ -19,9 +19,9 @@ func ValidatePhoneNumber(phone string) bool {
 		return false
 	}
 
-	// Pattern: optional +, then 1-9, then 9-14 digits
-	// This supports: +989123456789, 09123456789, 9123456789
-	phoneRegex := regexp.MustCompile(`^(\+?[1-9]\d{9,14}|0\d{9,10})$`)
+	// Pattern: optional + or 00, then 1-9, then 9-14 digits
+	// This supports: +989123456789, 00989123456789, 09123456789, 9123456789
+	phoneRegex := regexp.MustCompile(`^((\+|00)?[1-9]\d{9,14}|0\d{9,10})$`)
 
 	return phoneRegex.MatchString(cleanedPhone)
 }